@@ -5,6 +5,7 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"unicode/utf8"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
@@ -27,11 +28,17 @@ const (
 var driverLabels = map[ConnectionType]string{
 	ConnectionPostgres: "PostgreSQL",
 	ConnectionSQLite:   "SQLite",
+	ConnectionMySQL:    "MySQL/MariaDB",
+}
+
+var driverDefaults = map[ConnectionType]ConnectionInfo{
+	ConnectionPostgres: {Port: 5432, User: "postgres", SSLMode: "disable"},
+	ConnectionMySQL:    {Port: 3306, User: "root", SSLMode: "disable"},
 }
 
 type AddConnectionForm struct {
 	connectionInfo  *ConnectionInfo
-	cursor          int
+	input           *TextBuffer
 	field           int
 	isConfirmed     bool
 	cancelled       bool
@@ -53,7 +60,7 @@ func NewAddConnectionForm() *AddConnectionForm {
 			SSLMode:  "disable",
 			Path:     "",
 		},
-		cursor:          0,
+		input:           NewTextBuffer(false),
 		field:           0,
 		fieldLabelWidth: 22,
 		mode:            "add",
@@ -79,30 +86,22 @@ func (acf *AddConnectionForm) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case tea.KeyEscape:
 			acf.isConfirmed = true
 			acf.cancelled = true
-		case tea.KeyBackspace:
-			acf.deleteChar()
 		case tea.KeyLeft:
 			if acf.currentField() == fieldDriver {
 				acf.toggleDriver(-1)
 			} else {
-				acf.moveCursor(-1)
+				acf.editField(msg)
 			}
 		case tea.KeyRight:
 			if acf.currentField() == fieldDriver {
 				acf.toggleDriver(1)
 			} else {
-				acf.moveCursor(1)
+				acf.editField(msg)
 			}
 		case tea.KeyCtrlT:
 			acf.toggleDriver(1)
-		case tea.KeyRunes:
-			if len(msg.Runes) > 0 && !msg.Alt {
-				acf.addRunes(msg.Runes)
-			}
-		case tea.KeySpace:
-			if !msg.Alt {
-				acf.addRunes([]rune{' '})
-			}
+		default:
+			acf.editField(msg)
 		}
 	}
 	return acf, nil
@@ -143,18 +142,33 @@ func (acf *AddConnectionForm) moveField(delta int) {
 	} else if acf.field >= len(fields) {
 		acf.field = len(fields) - 1
 	}
-	acf.syncCursorToField()
+	acf.loadFieldInput()
 }
 
-func (acf *AddConnectionForm) syncCursorToField() {
+// loadFieldInput puts the focused field's value in the edit buffer, with the
+// cursor at its end.
+func (acf *AddConnectionForm) loadFieldInput() {
 	if acf.currentField() == fieldDriver {
-		acf.cursor = 0
+		acf.input.Load("")
 		return
 	}
-	val := acf.currentFieldValue()
-	if acf.cursor > len(val) {
-		acf.cursor = len(val)
+	acf.input.Load(acf.currentFieldValue())
+}
+
+// editField passes a key to the edit buffer and stores the result back in
+// the focused field. The port only accepts digits.
+func (acf *AddConnectionForm) editField(msg tea.KeyMsg) {
+	field := acf.currentField()
+	if field == fieldDriver {
+		return
 	}
+	if field == fieldPort && msg.Type == tea.KeyRunes && strings.Trim(string(msg.Runes), "0123456789") != "" {
+		return
+	}
+	if !acf.input.HandleKey(msg) {
+		return
+	}
+	acf.setCurrentFieldValue(acf.input.Text())
 }
 
 func (acf *AddConnectionForm) currentFieldValue() string {
@@ -184,22 +198,8 @@ func (acf *AddConnectionForm) currentFieldValue() string {
 	}
 }
 
-func (acf *AddConnectionForm) moveCursor(delta int) {
-	if acf.currentField() == fieldDriver {
-		return
-	}
-	acf.cursor += delta
-	if acf.cursor < 0 {
-		acf.cursor = 0
-	}
-	val := acf.currentFieldValue()
-	if acf.cursor > len(val) {
-		acf.cursor = len(val)
-	}
-}
-
 func (acf *AddConnectionForm) toggleDriver(delta int) {
-	drivers := []ConnectionType{ConnectionPostgres, ConnectionSQLite}
+	drivers := []ConnectionType{ConnectionPostgres, ConnectionMySQL, ConnectionSQLite}
 	current := acf.connectionInfo.Type
 	idx := 0
 	for i, d := range drivers {
@@ -210,71 +210,52 @@ func (acf *AddConnectionForm) toggleDriver(delta int) {
 	}
 	idx = (idx + delta + len(drivers)) % len(drivers)
 	acf.connectionInfo.Type = drivers[idx]
+	acf.applyDriverDefaults(current)
 	acf.field = 0 // keep cursor on driver when toggling
-	acf.cursor = 0
+	acf.input.Load("")
 	acf.validationError = ""
 }
 
-func (acf *AddConnectionForm) addChar(char string) {
-	if acf.currentField() == fieldDriver {
+// Port and user only follow the driver while they still hold the previous
+// driver's defaults, so values typed by the user are never overwritten.
+func (acf *AddConnectionForm) applyDriverDefaults(previous ConnectionType) {
+	info := acf.connectionInfo
+	oldDefaults, hadDefaults := driverDefaults[previous]
+	newDefaults, ok := driverDefaults[info.Type]
+	if !ok {
 		return
 	}
-
-	switch acf.currentField() {
-	case fieldName:
-		acf.connectionInfo.Name = acf.insertAtCursor(acf.connectionInfo.Name, char)
-	case fieldHost:
-		acf.connectionInfo.Host = acf.insertAtCursor(acf.connectionInfo.Host, char)
-	case fieldPort:
-		portStr := acf.insertAtCursor(acf.currentFieldValue(), char)
-		acf.setPortFromString(portStr)
-	case fieldUser:
-		acf.connectionInfo.User = acf.insertAtCursor(acf.connectionInfo.User, char)
-	case fieldPassword:
-		acf.connectionInfo.Password = acf.insertAtCursor(acf.connectionInfo.Password, char)
-	case fieldDatabase:
-		acf.connectionInfo.Database = acf.insertAtCursor(acf.connectionInfo.Database, char)
-	case fieldSSLMode:
-		acf.connectionInfo.SSLMode = acf.insertAtCursor(acf.connectionInfo.SSLMode, char)
-	case fieldPath:
-		acf.connectionInfo.Path = acf.insertAtCursor(acf.connectionInfo.Path, char)
+	if !hadDefaults || info.Port == 0 || info.Port == oldDefaults.Port {
+		info.Port = newDefaults.Port
 	}
-	acf.cursor++
-}
-
-func (acf *AddConnectionForm) addRunes(runes []rune) {
-	for _, r := range runes {
-		acf.addChar(string(r))
+	if !hadDefaults || info.User == "" || info.User == oldDefaults.User {
+		info.User = newDefaults.User
 	}
-}
-
-func (acf *AddConnectionForm) deleteChar() {
-	if acf.currentField() == fieldDriver || acf.cursor == 0 {
-		return
+	if info.SSLMode == "" {
+		info.SSLMode = newDefaults.SSLMode
 	}
+}
 
+func (acf *AddConnectionForm) setCurrentFieldValue(value string) {
+	info := acf.connectionInfo
 	switch acf.currentField() {
 	case fieldName:
-		acf.connectionInfo.Name = acf.deleteFromCursor(acf.connectionInfo.Name)
+		info.Name = value
 	case fieldHost:
-		acf.connectionInfo.Host = acf.deleteFromCursor(acf.connectionInfo.Host)
+		info.Host = value
 	case fieldPort:
-		portStr := acf.deleteFromCursor(acf.currentFieldValue())
-		acf.cursor--
-		acf.setPortFromString(portStr)
-		return
+		acf.setPortFromString(value)
 	case fieldUser:
-		acf.connectionInfo.User = acf.deleteFromCursor(acf.connectionInfo.User)
+		info.User = value
 	case fieldPassword:
-		acf.connectionInfo.Password = acf.deleteFromCursor(acf.connectionInfo.Password)
+		info.Password = value
 	case fieldDatabase:
-		acf.connectionInfo.Database = acf.deleteFromCursor(acf.connectionInfo.Database)
+		info.Database = value
 	case fieldSSLMode:
-		acf.connectionInfo.SSLMode = acf.deleteFromCursor(acf.connectionInfo.SSLMode)
+		info.SSLMode = value
 	case fieldPath:
-		acf.connectionInfo.Path = acf.deleteFromCursor(acf.connectionInfo.Path)
+		info.Path = value
 	}
-	acf.cursor--
 }
 
 func (acf *AddConnectionForm) setPortFromString(value string) {
@@ -290,23 +271,6 @@ func (acf *AddConnectionForm) setPortFromString(value string) {
 	acf.connectionInfo.Port = port
 }
 
-func (acf *AddConnectionForm) insertAtCursor(current, char string) string {
-	if acf.cursor > len(current) {
-		return current + char
-	}
-	return current[:acf.cursor] + char + current[acf.cursor:]
-}
-
-func (acf *AddConnectionForm) deleteFromCursor(current string) string {
-	if acf.cursor == 0 || len(current) == 0 {
-		return current
-	}
-	if acf.cursor >= len(current) {
-		return current[:len(current)-1]
-	}
-	return current[:acf.cursor-1] + current[acf.cursor:]
-}
-
 func (acf *AddConnectionForm) validate() bool {
 	info := acf.connectionInfo
 	if strings.TrimSpace(info.Name) == "" {
@@ -323,7 +287,7 @@ func (acf *AddConnectionForm) validate() bool {
 	default:
 		switch {
 		case strings.TrimSpace(info.Host) == "":
-			acf.validationError = "Host é obrigatório para PostgreSQL"
+			acf.validationError = fmt.Sprintf("Host é obrigatório para %s", driverLabels[info.Type])
 			return false
 		case info.Port <= 0:
 			acf.validationError = "Porta inválida"
@@ -366,7 +330,7 @@ func (acf *AddConnectionForm) View() string {
 		lines = append(lines, "", errLine)
 	}
 
-	helpText := "↑/↓ Navega | ←/→ Move cursor (Driver alterna) | Tab Avança | Ctrl+T Troca Driver | Enter Salva | Esc Cancela"
+	helpText := "↑/↓ Navega | ←/→ Move cursor (Driver alterna) | Tab Avança | Ctrl+Z/Y Desfaz/Refaz | Ctrl+T Troca Driver | Enter Salva | Esc Cancela"
 	helpLine := lipgloss.NewStyle().
 		Foreground(lipgloss.Color("#888888")).
 		Italic(true).
@@ -385,6 +349,9 @@ func (acf *AddConnectionForm) View() string {
 func (acf *AddConnectionForm) renderField(field formField, focused bool) string {
 	label := acf.fieldLabel(field)
 	value := acf.displayValue(field)
+	if focused && field != fieldDriver {
+		value = acf.inputView(field)
+	}
 
 	lineStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#FFFFFF"))
 	if focused {
@@ -442,7 +409,7 @@ func (acf *AddConnectionForm) displayValue(field formField) string {
 		if info.Password == "" {
 			return "(opcional)"
 		}
-		return strings.Repeat("•", len(info.Password))
+		return strings.Repeat("•", utf8.RuneCountInString(info.Password))
 	case fieldDatabase:
 		if info.Database == "" {
 			return "(database)"
@@ -463,6 +430,20 @@ func (acf *AddConnectionForm) displayValue(field formField) string {
 	}
 }
 
+// inputView shows the focused field being edited, with its cursor and
+// selection; an empty field keeps its hint after the cursor.
+func (acf *AddConnectionForm) inputView(field formField) string {
+	var mask rune
+	if field == fieldPassword {
+		mask = '•'
+	}
+	view := acf.input.View(mask, 37)
+	if acf.input.Len() == 0 {
+		view += acf.displayValue(field)
+	}
+	return view
+}
+
 func (acf *AddConnectionForm) fieldLabel(field formField) string {
 	switch field {
 	case fieldDriver:
@@ -502,7 +483,7 @@ func (acf *AddConnectionForm) SetConnectionInfo(info *ConnectionInfo) {
 	}
 	acf.mode = "edit"
 	acf.field = 0
-	acf.cursor = 0
+	acf.input.Load("")
 	acf.validationError = ""
 }
 
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const taskTickInterval = 100 * time.Millisecond
+
+var spinnerFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+// backgroundTask is the database call currently running outside the update
+// loop. Cancelling its context aborts the statement on the server: lib/pq
+// sends a cancel request (pg_cancel_backend semantics), go-sqlite3 calls
+// sqlite3_interrupt and go-sql-driver/mysql closes the connection.
+type backgroundTask struct {
+	id      int
+	label   string
+	started time.Time
+	ctx     context.Context
+	cancel  context.CancelFunc
+}
+
+// RunTaskMsg asks the app to run work in the background. Components without
+// access to the app (navigators, wizards) return it instead of calling the
+// loader inline.
+type RunTaskMsg struct {
+	label string
+	run   func(ctx context.Context) tea.Msg
+}
+
+type taskDoneMsg struct {
+	id     int
+	result tea.Msg
+}
+
+type taskTickMsg struct {
+	id int
+}
+
+func runTask(label string, run func(ctx context.Context) tea.Msg) tea.Cmd {
+	return func() tea.Msg {
+		return RunTaskMsg{label: label, run: run}
+	}
+}
+
+// startTask runs work with a cancellable context and reports its result as a
+// taskDoneMsg. Only one task runs at a time; while one is running a new one
+// is refused, so an apply, import or export is never cancelled behind the
+// user's back.
+func (app *XTreeGoldApp) startTask(label string, run func(ctx context.Context) tea.Msg) tea.Cmd {
+	if app.task != nil {
+		app.setStatus(fmt.Sprintf("⚠ Aguarde: %s em andamento", app.task.label))
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	app.nextTaskID++
+	task := &backgroundTask{
+		id:      app.nextTaskID,
+		label:   label,
+		started: time.Now(),
+		ctx:     ctx,
+		cancel:  cancel,
+	}
+	app.task = task
+
+	return tea.Batch(
+		func() tea.Msg {
+			return taskDoneMsg{id: task.id, result: run(ctx)}
+		},
+		taskTick(task.id),
+	)
+}
+
+func taskTick(id int) tea.Cmd {
+	return tea.Tick(taskTickInterval, func(time.Time) tea.Msg {
+		return taskTickMsg{id: id}
+	})
+}
+
+func (app *XTreeGoldApp) cancelTask() {
+	if app.task == nil {
+		return
+	}
+	app.task.cancel()
+	app.setStatus(fmt.Sprintf("Cancelando: %s...", app.task.label))
+}
+
+// finishTask clears the running task and returns the result that should be
+// processed, or nil when the task was cancelled by the user.
+func (app *XTreeGoldApp) finishTask(msg taskDoneMsg) tea.Msg {
+	task := app.task
+	if task == nil || task.id != msg.id {
+		return nil
+	}
+	app.task = nil
+	task.cancel()
+
+	if task.ctx.Err() == nil {
+		return msg.result
+	}
+
+	app.setStatus(fmt.Sprintf("✖ Cancelado: %s", task.label))
+	if app.importWizard != nil && app.importWizard.Running() {
+		// The cancelled import was rolled back; show that instead of
+		// leaving the wizard waiting.
+		app.importWizard.SetResult(ImportResult{}, task.ctx.Err())
+	}
+	if !app.initialized && app.connectionStep == StepConnected {
+		app.focusMode = FocusConnectionDialog
+		app.connectionStep = StepSelectConnection
+	}
+	return nil
+}
+
+func (app *XTreeGoldApp) taskStatus() string {
+	if app.task == nil {
+		return ""
+	}
+	elapsed := time.Since(app.task.started)
+	frame := spinnerFrames[int(elapsed/taskTickInterval)%len(spinnerFrames)]
+	return fmt.Sprintf("%s %s... %.1fs (Esc/Ctrl+C: cancelar)", frame, app.task.label, elapsed.Seconds())
+}
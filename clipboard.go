@@ -0,0 +1,140 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/aymanbagabas/go-osc52"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// ClipboardCopiedMsg reports a copy to the terminal clipboard.
+type ClipboardCopiedMsg struct {
+	what  string
+	chars int
+	err   error
+}
+
+type RowCopyFormat int
+
+const (
+	RowCopyTSV RowCopyFormat = iota
+	RowCopyJSON
+	RowCopyInsert
+)
+
+func (f RowCopyFormat) String() string {
+	switch f {
+	case RowCopyJSON:
+		return "JSON"
+	case RowCopyInsert:
+		return "INSERT"
+	default:
+		return "TSV"
+	}
+}
+
+// copyToClipboard sends text to the terminal with OSC 52, which also works
+// over SSH; inside tmux or screen the sequence is wrapped for passthrough.
+func copyToClipboard(text, what string) tea.Cmd {
+	return func() tea.Msg {
+		seq := osc52.Sequence(text, os.Getenv("TERM"), osc52.SystemClipboard)
+		if _, err := io.WriteString(os.Stdout, seq); err != nil {
+			return ClipboardCopiedMsg{what: what, err: fmt.Errorf("failed to write to terminal: %w", err)}
+		}
+		return ClipboardCopiedMsg{what: what, chars: len([]rune(text))}
+	}
+}
+
+// rowClipboardText formats one row: TSV for spreadsheets, or the same JSON
+// object and INSERT statement the exporter writes. source provides the
+// target table and dialect for INSERT.
+func rowClipboardText(format RowCopyFormat, source ExportSource, values []interface{}) (string, error) {
+	if format == RowCopyTSV {
+		var buf bytes.Buffer
+		w := csv.NewWriter(&buf)
+		w.Comma = '\t'
+		record := make([]string, len(values))
+		for idx, value := range values {
+			record[idx] = exportText(value)
+		}
+		if err := w.Write(record); err != nil {
+			return "", err
+		}
+		w.Flush()
+		return strings.TrimRight(buf.String(), "\n"), w.Error()
+	}
+
+	exportFormat := ExportNDJSON
+	if format == RowCopyInsert {
+		exportFormat = ExportSQL
+	}
+	row := &ResultSet{Rows: [][]interface{}{values}}
+	for _, name := range source.Columns {
+		row.Columns = append(row.Columns, ResultColumn{Name: name})
+	}
+	source.NextPage = staticExportSource(row).NextPage
+
+	var buf bytes.Buffer
+	if _, err := WriteExport(&buf, exportFormat, source); err != nil {
+		return "", err
+	}
+	return strings.TrimRight(buf.String(), "\n"), nil
+}
+
+// copyCell copies the selected cell of a grid as text; NULL copies as an
+// empty string.
+func (app *XTreeGoldApp) copyCell(grid *PaneModel) tea.Cmd {
+	row, column, value := grid.GetSelectedDataCell()
+	if row < 0 {
+		return nil
+	}
+	return copyToClipboard(exportText(value), fmt.Sprintf("célula %s", column))
+}
+
+// copyRow copies the selected row of a grid. Table rows are written as
+// INSERTs into their table; query results use the export placeholder name.
+func (app *XTreeGoldApp) copyRow(grid *PaneModel, format RowCopyFormat) tea.Cmd {
+	rowIdx := grid.GetSelectedDataRowIndex()
+	if rowIdx < 0 || rowIdx >= grid.GetDataRowCount() || app.dbLoader == nil {
+		return nil
+	}
+	columns := grid.GetDataColumns()
+	values := make([]interface{}, len(columns))
+	for idx, column := range columns {
+		values[idx] = grid.GetDataValue(rowIdx, column)
+	}
+
+	var source ExportSource
+	if grid.HasDataContext() {
+		db, schema, table := grid.GetDataContext()
+		source = app.dbLoader.TableExportSource(context.Background(), db, schema, table, columns, grid.GetDataView())
+	} else {
+		source = app.dbLoader.QueryExportSource(grid.GetData())
+		source.Columns = columns
+	}
+	text, err := rowClipboardText(format, source, values)
+	if err != nil {
+		app.setStatus(fmt.Sprintf("❌ Falha ao copiar linha: %v", err))
+		return nil
+	}
+	return copyToClipboard(text, fmt.Sprintf("linha (%s)", format))
+}
+
+// copyQuery copies the editor selection, or the whole query when nothing
+// is selected.
+func (app *XTreeGoldApp) copyQuery() tea.Cmd {
+	if text := app.queryEditor.SelectedText(); text != "" {
+		return copyToClipboard(text, "seleção")
+	}
+	query := app.queryEditor.GetValue()
+	if query == "" {
+		return nil
+	}
+	return copyToClipboard(query, "consulta")
+}
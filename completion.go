@@ -0,0 +1,465 @@
+package main
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"windsurf-tui/sqllex"
+)
+
+const catalogFetchTimeout = 10 * time.Second
+
+type CompletionKind int
+
+const (
+	CompletionKeyword CompletionKind = iota
+	CompletionFunction
+	CompletionSchema
+	CompletionTable
+	CompletionColumn
+)
+
+func (k CompletionKind) String() string {
+	switch k {
+	case CompletionFunction:
+		return "função"
+	case CompletionSchema:
+		return "schema"
+	case CompletionTable:
+		return "tabela"
+	case CompletionColumn:
+		return "coluna"
+	}
+	return "palavra-chave"
+}
+
+type CompletionItem struct {
+	Label  string
+	Kind   CompletionKind
+	Detail string
+}
+
+// CatalogLoadedMsg carries the children fetched for a tree node on behalf of
+// the completion popup.
+type CatalogLoadedMsg struct {
+	node     *TreeNode
+	children []*TreeNode
+	err      error
+}
+
+// SchemaCatalog answers completion lookups from the loaded tree. Nodes whose
+// children were never loaded are fetched in the background, outside the task
+// system, so typing is never blocked; lookups meanwhile return what is known.
+type SchemaCatalog struct {
+	root      *TreeNode
+	database  string
+	loader    DatabaseLoader
+	requested map[*TreeNode]bool
+	loading   map[*TreeNode]bool
+	pending   []*TreeNode
+}
+
+func NewSchemaCatalog(root *TreeNode, database string, loader DatabaseLoader) *SchemaCatalog {
+	return &SchemaCatalog{
+		root:      root,
+		database:  database,
+		loader:    loader,
+		requested: make(map[*TreeNode]bool),
+		loading:   make(map[*TreeNode]bool),
+	}
+}
+
+// databaseNode is the database queries run against: the connection's own, or
+// the first one listed.
+func (sc *SchemaCatalog) databaseNode() *TreeNode {
+	if sc == nil || sc.root == nil || len(sc.root.Children) == 0 {
+		return nil
+	}
+	databases := sc.root.Children[0].Children
+	for _, db := range databases {
+		if db.Name == sc.database {
+			return db
+		}
+	}
+	if len(databases) > 0 {
+		return databases[0]
+	}
+	return nil
+}
+
+// children returns node's children, queueing a fetch when they are missing.
+func (sc *SchemaCatalog) children(node *TreeNode) []*TreeNode {
+	if node == nil {
+		return nil
+	}
+	if len(node.Children) == 0 && !sc.requested[node] {
+		sc.requested[node] = true
+		sc.pending = append(sc.pending, node)
+	}
+	return node.Children
+}
+
+func (sc *SchemaCatalog) schemas() []*TreeNode {
+	return sc.children(sc.databaseNode())
+}
+
+// defaultSchema is the one unqualified names resolve to: public on
+// PostgreSQL, otherwise the only schema listed.
+func (sc *SchemaCatalog) defaultSchema() *TreeNode {
+	schemas := sc.schemas()
+	for _, schema := range schemas {
+		if schema.Name == "public" {
+			return schema
+		}
+	}
+	if len(schemas) > 0 {
+		return schemas[0]
+	}
+	return nil
+}
+
+func (sc *SchemaCatalog) schema(name string) *TreeNode {
+	for _, schema := range sc.schemas() {
+		if strings.EqualFold(schema.Name, name) {
+			return schema
+		}
+	}
+	return nil
+}
+
+func (sc *SchemaCatalog) tables(schema *TreeNode) []*TreeNode {
+	return sc.children(schema)
+}
+
+func (sc *SchemaCatalog) table(schemaName, tableName string) *TreeNode {
+	schema := sc.defaultSchema()
+	if schemaName != "" {
+		schema = sc.schema(schemaName)
+	}
+	for _, table := range sc.tables(schema) {
+		if strings.EqualFold(table.Name, tableName) {
+			return table
+		}
+	}
+	return nil
+}
+
+func (sc *SchemaCatalog) columns(table *TreeNode) []*TreeNode {
+	return sc.children(table)
+}
+
+// Loading reports whether metadata requested by earlier lookups is still
+// being fetched.
+func (sc *SchemaCatalog) Loading() bool {
+	return sc != nil && (len(sc.loading) > 0 || len(sc.pending) > 0)
+}
+
+// Fetch returns a command loading every node queued by lookups. Each node is
+// loaded into a detached copy and attached by Attach on the update loop, so
+// the tree is never written from another goroutine.
+func (sc *SchemaCatalog) Fetch() tea.Cmd {
+	if sc == nil || sc.loader == nil || len(sc.pending) == 0 {
+		return nil
+	}
+	var cmds []tea.Cmd
+	for _, node := range sc.pending {
+		node, loader := node, sc.loader
+		sc.loading[node] = true
+		probe := node.detached()
+		cmds = append(cmds, func() tea.Msg {
+			ctx, cancel := context.WithTimeout(context.Background(), catalogFetchTimeout)
+			defer cancel()
+			err := loader.LoadChildren(ctx, probe)
+			return CatalogLoadedMsg{node: node, children: probe.Children, err: err}
+		})
+	}
+	sc.pending = nil
+	return tea.Batch(cmds...)
+}
+
+// Attach stores fetched children unless the navigator loaded them meanwhile.
+// A node whose fetch failed stays requested and is not retried.
+func (sc *SchemaCatalog) Attach(msg CatalogLoadedMsg) {
+	delete(sc.loading, msg.node)
+	if msg.err == nil {
+		msg.node.attachChildren(msg.children)
+	}
+}
+
+// tableRef is a table named in the statement, with the alias it was given.
+type tableRef struct {
+	schema string
+	table  string
+	alias  string
+}
+
+// completionContext describes the cursor position: the partial word being
+// typed, the name before a dot (schema, table or alias) and whether the
+// grammar expects a table name there.
+type completionContext struct {
+	prefix      string
+	prefixStart int
+	qualifier   string
+	expectTable bool
+	refs        []tableRef
+}
+
+var tableClauseKeywords = []string{"FROM", "JOIN", "UPDATE", "INTO", "TABLE"}
+
+var clauseKeywords = []string{
+	"SELECT", "FROM", "JOIN", "WHERE", "ON", "SET", "INTO", "UPDATE", "GROUP", "ORDER",
+	"HAVING", "VALUES", "LIMIT", "TABLE", "RETURNING", "USING",
+}
+
+func analyzeCompletion(text string, cursor int, dialect ConnectionType) completionContext {
+	var ctx completionContext
+
+	ctx.prefixStart = cursor
+	for ctx.prefixStart > 0 && isIdentByte(text[ctx.prefixStart-1]) {
+		ctx.prefixStart--
+	}
+	ctx.prefix = text[ctx.prefixStart:cursor]
+
+	stmtStart, stmtEnd := statementBounds(text, ctx.prefixStart, cursor, dialect)
+
+	if ctx.prefixStart > 0 && text[ctx.prefixStart-1] == '.' {
+		end := ctx.prefixStart - 1
+		start := end
+		for start > stmtStart && isIdentByte(text[start-1]) {
+			start--
+		}
+		ctx.qualifier = strings.Trim(text[start:end], "\"`")
+	}
+
+	tokens := tokenizeSQL(text[stmtStart:stmtEnd], dialect)
+	var before []sqllex.Token
+	for _, tok := range tokens {
+		if tok.IsTrivia() {
+			continue
+		}
+		if stmtStart+tok.Start >= ctx.prefixStart {
+			break
+		}
+		before = append(before, tok)
+	}
+	ctx.expectTable = expectsTable(before, ctx.qualifier != "")
+	ctx.refs = tableRefs(tokens)
+	return ctx
+}
+
+// statementBounds returns the extent of the statement being typed at pos:
+// the last one that starts before it, unless a semicolon already ended it.
+func statementBounds(text string, pos, cursor int, dialect ConnectionType) (int, int) {
+	start := pos
+	if before := splitStatements(text[:pos], dialect); len(before) > 0 {
+		last := before[len(before)-1]
+		terminated := false
+		for _, tok := range tokenizeSQL(text[last.End:pos], dialect) {
+			if tok.Kind == sqllex.Punct && tok.Text == ";" {
+				terminated = true
+			}
+		}
+		if !terminated {
+			start = last.Start
+		}
+	}
+
+	end := cursor
+	for _, stmt := range splitStatements(text, dialect) {
+		if stmt.Start <= start && stmt.End >= end {
+			end = stmt.End
+		}
+	}
+	return start, end
+}
+
+func isIdentByte(c byte) bool {
+	return c == '_' || c == '$' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= 0x80
+}
+
+// expectsTable looks back from the cursor for the clause it is in: right
+// after FROM/JOIN/UPDATE/INTO, or after a comma in a FROM list.
+func expectsTable(before []sqllex.Token, qualified bool) bool {
+	idx := len(before) - 1
+	if qualified {
+		// Skip "schema ." to reach the token before the qualified name.
+		idx -= 2
+	}
+	if idx < 0 {
+		return false
+	}
+	last := before[idx]
+	if last.IsKeyword(tableClauseKeywords...) {
+		return true
+	}
+	if last.Kind != sqllex.Punct || last.Text != "," {
+		return false
+	}
+	for i := idx - 1; i >= 0; i-- {
+		if before[i].IsKeyword(clauseKeywords...) {
+			return before[i].IsKeyword("FROM")
+		}
+	}
+	return false
+}
+
+// tableRefs finds "FROM/JOIN/UPDATE/INTO [schema.]table [[AS] alias]" in the
+// statement, including comma-separated FROM lists.
+func tableRefs(tokens []sqllex.Token) []tableRef {
+	var significant []sqllex.Token
+	for _, tok := range tokens {
+		if !tok.IsTrivia() {
+			significant = append(significant, tok)
+		}
+	}
+
+	var refs []tableRef
+	for idx := 0; idx < len(significant); idx++ {
+		tok := significant[idx]
+		if !tok.IsKeyword(tableClauseKeywords...) {
+			continue
+		}
+		inFrom := tok.IsKeyword("FROM")
+		for {
+			ref, next, ok := parseTableRef(significant, idx+1)
+			if !ok {
+				break
+			}
+			refs = append(refs, ref)
+			idx = next - 1
+			if !inFrom || next >= len(significant) || significant[next].Text != "," {
+				break
+			}
+			idx = next
+		}
+	}
+	return refs
+}
+
+func parseTableRef(tokens []sqllex.Token, idx int) (tableRef, int, bool) {
+	name := func(i int) (string, bool) {
+		if i >= len(tokens) {
+			return "", false
+		}
+		switch tokens[i].Kind {
+		case sqllex.Word:
+			return tokens[i].Text, true
+		case sqllex.QuotedIdent:
+			return tokens[i].Text[1 : len(tokens[i].Text)-1], len(tokens[i].Text) >= 2
+		}
+		return "", false
+	}
+
+	var ref tableRef
+	first, ok := name(idx)
+	if !ok || tokens[idx].IsKeyword(clauseKeywords...) {
+		return ref, idx, false
+	}
+	ref.table = first
+	idx++
+	if idx+1 < len(tokens) && tokens[idx].Text == "." {
+		if second, ok := name(idx + 1); ok {
+			ref.schema, ref.table = first, second
+			idx += 2
+		}
+	}
+
+	if idx < len(tokens) && tokens[idx].IsKeyword("AS") {
+		idx++
+	}
+	if alias, ok := name(idx); ok && !isSQLKeyword(ConnectionPostgres, alias) {
+		ref.alias = alias
+		idx++
+	}
+	return ref, idx, true
+}
+
+// completeSQL lists the candidates for the word at cursor, best matches
+// first. Catalog lookups for metadata that is not loaded yet are queued on
+// the catalog and simply contribute nothing this time.
+func completeSQL(text string, cursor int, dialect ConnectionType, catalog *SchemaCatalog) (completionContext, []CompletionItem) {
+	ctx := analyzeCompletion(text, cursor, dialect)
+
+	var items []CompletionItem
+	seen := make(map[string]bool)
+	add := func(label string, kind CompletionKind, detail string) {
+		key := strings.ToLower(label)
+		if seen[key] || !strings.HasPrefix(key, strings.ToLower(ctx.prefix)) {
+			return
+		}
+		seen[key] = true
+		items = append(items, CompletionItem{Label: label, Kind: kind, Detail: detail})
+	}
+	addColumns := func(table *TreeNode) {
+		for _, column := range catalog.columns(table) {
+			add(column.Name, CompletionColumn, table.Name+" "+column.Metadata.DataType)
+		}
+	}
+	addTables := func(schema *TreeNode) {
+		for _, table := range catalog.tables(schema) {
+			add(table.Name, CompletionTable, schema.Name)
+		}
+	}
+
+	if catalog != nil && ctx.qualifier != "" {
+		for _, ref := range ctx.refs {
+			if strings.EqualFold(ref.alias, ctx.qualifier) || ref.alias == "" && strings.EqualFold(ref.table, ctx.qualifier) {
+				addColumns(catalog.table(ref.schema, ref.table))
+			}
+		}
+		if schema := catalog.schema(ctx.qualifier); schema != nil {
+			addTables(schema)
+		}
+		return ctx, items
+	}
+
+	if catalog != nil && ctx.expectTable {
+		addTables(catalog.defaultSchema())
+		for _, schema := range catalog.schemas() {
+			add(schema.Name, CompletionSchema, "")
+		}
+		return ctx, items
+	}
+
+	if catalog != nil {
+		for _, ref := range ctx.refs {
+			addColumns(catalog.table(ref.schema, ref.table))
+		}
+	}
+	sort.SliceStable(items, func(i, j int) bool { return items[i].Label < items[j].Label })
+	for _, function := range sqlFunctionList(dialect) {
+		add(function, CompletionFunction, "")
+	}
+	for _, keyword := range sqlKeywordList(dialect) {
+		add(keyword, CompletionKeyword, "")
+	}
+	return ctx, items
+}
+
+// completionText is what replaces the typed prefix: keywords and functions
+// keep their spelling, names that would not survive unquoted are quoted.
+func completionText(item CompletionItem, dialect ConnectionType) string {
+	if item.Kind == CompletionKeyword || item.Kind == CompletionFunction {
+		return item.Label
+	}
+	simple := item.Label != ""
+	for i, r := range item.Label {
+		lower := r >= 'a' && r <= 'z' || r == '_' || i > 0 && r >= '0' && r <= '9'
+		upper := r >= 'A' && r <= 'Z'
+		if !lower && !(upper && dialect != ConnectionPostgres) {
+			simple = false
+			break
+		}
+	}
+	if simple && !isSQLKeyword(dialect, item.Label) {
+		return item.Label
+	}
+	if dialect == ConnectionMySQL {
+		return quoteMySQLIdentifier(item.Label)
+	}
+	return quoteIdentifier(item.Label)
+}
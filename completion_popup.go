@@ -0,0 +1,142 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const completionPopupRows = 8
+
+// completionPopup lists the candidates for the word that starts at start
+// (a rune position in the editor buffer).
+type completionPopup struct {
+	items    []CompletionItem
+	selected int
+	start    int
+}
+
+func (qe *QueryEditor) SetCatalog(catalog *SchemaCatalog) {
+	qe.catalog = catalog
+	qe.completion = nil
+}
+
+func (qe *QueryEditor) CompletionOpen() bool {
+	return qe.completion != nil
+}
+
+// AttachCatalog stores metadata fetched for completion and refreshes an open
+// popup, which may in turn ask for more (e.g. columns once tables arrive).
+func (qe *QueryEditor) AttachCatalog(msg CatalogLoadedMsg) tea.Cmd {
+	if qe.catalog == nil {
+		return nil
+	}
+	qe.catalog.Attach(msg)
+	if qe.completion == nil {
+		return nil
+	}
+	return qe.refreshCompletion(false)
+}
+
+// openCompletion runs on Tab. A single candidate is inserted right away.
+func (qe *QueryEditor) openCompletion() tea.Cmd {
+	qe.completion = &completionPopup{}
+	cmd := qe.refreshCompletion(false)
+	if qe.completion != nil && len(qe.completion.items) == 1 && !qe.catalog.Loading() {
+		qe.acceptCompletion()
+	}
+	return cmd
+}
+
+// refreshCompletion recomputes the candidates after an edit or a catalog
+// update; the popup closes when nothing matches and nothing is loading.
+func (qe *QueryEditor) refreshCompletion(closeWhenEmpty bool) tea.Cmd {
+	ctx, items := completeSQL(qe.buffer.Text(), qe.buffer.CursorByte(), qe.dialect, qe.catalog)
+	cmd := qe.catalog.Fetch()
+
+	if len(items) == 0 && (closeWhenEmpty || !qe.catalog.Loading()) {
+		qe.completion = nil
+		return cmd
+	}
+	qe.completion.items = items
+	qe.completion.start = qe.buffer.RuneOffset(ctx.prefixStart)
+	if qe.completion.selected >= len(items) {
+		qe.completion.selected = 0
+	}
+	return cmd
+}
+
+func (qe *QueryEditor) acceptCompletion() {
+	popup := qe.completion
+	qe.completion = nil
+	if popup == nil || popup.selected >= len(popup.items) || popup.start > qe.buffer.Cursor() {
+		return
+	}
+	text := completionText(popup.items[popup.selected], qe.dialect)
+	qe.buffer.ReplaceRange(popup.start, qe.buffer.Cursor(), text)
+}
+
+// updateCompletion handles keys while the popup is open. It returns false for
+// keys the editor should process itself.
+func (qe *QueryEditor) updateCompletion(msg tea.KeyMsg) (bool, tea.Cmd) {
+	popup := qe.completion
+	switch msg.Type {
+	case tea.KeyUp:
+		if popup.selected > 0 {
+			popup.selected--
+		}
+		return true, nil
+	case tea.KeyDown:
+		if popup.selected < len(popup.items)-1 {
+			popup.selected++
+		}
+		return true, nil
+	case tea.KeyTab, tea.KeyEnter:
+		qe.acceptCompletion()
+		return true, nil
+	case tea.KeyEscape:
+		qe.completion = nil
+		return true, nil
+	case tea.KeyBackspace, tea.KeyRunes:
+		return false, nil
+	}
+	qe.completion = nil
+	return false, nil
+}
+
+func (qe *QueryEditor) renderCompletion() string {
+	popup := qe.completion
+	var lines []string
+
+	start := 0
+	if popup.selected >= completionPopupRows {
+		start = popup.selected - completionPopupRows + 1
+	}
+	for idx := start; idx < len(popup.items) && idx < start+completionPopupRows; idx++ {
+		item := popup.items[idx]
+		style := lipgloss.NewStyle().Foreground(lipgloss.Color("#FFFFFF"))
+		if idx == popup.selected {
+			style = style.Background(lipgloss.Color("#083863")).Bold(true)
+		}
+		detail := item.Kind.String()
+		if item.Detail != "" {
+			detail += " · " + item.Detail
+		}
+		lines = append(lines, style.Render(fmt.Sprintf(" %-30s %s ", item.Label, detail)))
+	}
+	if qe.catalog.Loading() {
+		lines = append(lines, lipgloss.NewStyle().Foreground(lipgloss.Color("#808080")).Italic(true).
+			Render(" carregando metadados..."))
+	}
+	if len(popup.items) > completionPopupRows {
+		lines = append(lines, lipgloss.NewStyle().Foreground(lipgloss.Color("#808080")).
+			Render(fmt.Sprintf(" %d/%d", popup.selected+1, len(popup.items))))
+	}
+
+	return lipgloss.NewStyle().
+		Border(lipgloss.NormalBorder()).
+		BorderForeground(lipgloss.Color("#808080")).
+		Render(strings.Join(lines, "\n"))
+}
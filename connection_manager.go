@@ -4,11 +4,14 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"net"
 	"os"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
 
+	"github.com/go-sql-driver/mysql"
 	_ "github.com/lib/pq"
 	_ "github.com/mattn/go-sqlite3"
 )
@@ -18,6 +21,7 @@ type ConnectionType string
 const (
 	ConnectionPostgres ConnectionType = "postgres"
 	ConnectionSQLite   ConnectionType = "sqlite"
+	ConnectionMySQL    ConnectionType = "mysql"
 )
 
 type ConnectionInfo struct {
@@ -84,6 +88,9 @@ func (cm *ConnectionManager) Connect(connInfo *ConnectionInfo) (*sql.DB, error)
 			return nil, fmt.Errorf("sqlite connection requires a file path")
 		}
 		connStr = connInfo.Path
+	case ConnectionMySQL:
+		driver = "mysql"
+		connStr = mysqlDSN(connInfo)
 	default:
 		driver = "postgres"
 		connStr = fmt.Sprintf(
@@ -111,6 +118,26 @@ func (cm *ConnectionManager) Connect(connInfo *ConnectionInfo) (*sql.DB, error)
 	return db, nil
 }
 
+func mysqlDSN(connInfo *ConnectionInfo) string {
+	cfg := mysql.NewConfig()
+	cfg.User = connInfo.User
+	cfg.Passwd = connInfo.Password
+	cfg.Net = "tcp"
+	cfg.Addr = net.JoinHostPort(connInfo.Host, strconv.Itoa(connInfo.Port))
+	cfg.DBName = connInfo.Database
+	cfg.ParseTime = true
+	// Report matched rather than changed rows, so that saving a value equal
+	// to the stored one still counts as touching the row.
+	cfg.ClientFoundRows = true
+	switch connInfo.SSLMode {
+	case "require":
+		cfg.TLSConfig = "skip-verify"
+	case "verify-full":
+		cfg.TLSConfig = "true"
+	}
+	return cfg.FormatDSN()
+}
+
 func (cm *ConnectionManager) GetConnection(name string) (*sql.DB, bool) {
 	db, ok := cm.connections[name]
 	return db, ok
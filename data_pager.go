@@ -0,0 +1,222 @@
+package main
+
+import "time"
+
+const (
+	dataPageSize = 100
+	// dataPageWindow is how many fetched pages stay in memory around the
+	// selection; pages further away are released and fetched again on demand.
+	dataPageWindow = 5
+	// dataPageTimeout bounds a page fetch, which runs outside the task slot
+	// and so cannot be cancelled with Esc.
+	dataPageTimeout = 30 * time.Second
+)
+
+// DataPageRequest asks for one page of the table shown in the Data pane.
+// Generation ties the answer to the table load that issued it.
+type DataPageRequest struct {
+	generation int
+	page       int
+	options    TableQueryOptions
+}
+
+type dataPage struct {
+	start  int // first row in the ResultSet
+	count  int
+	offset int // first row in the table
+	after  []interface{}
+	last   []interface{}
+	loaded bool
+}
+
+// dataPager tracks which ResultSet rows belong to which table page. Rows
+// staged for insertion are appended outside of any page and never released.
+// One page is fetched at a time; fetching is set while its answer is pending.
+type dataPager struct {
+	pages      []dataPage
+	keyColumns []string
+	view       TableView
+	generation int
+	total      int64
+	complete   bool
+	fetching   bool
+}
+
+func (dp *dataPager) reset(data *ResultSet, keyColumns []string, view TableView, complete bool, total int64) {
+	dp.generation++
+	dp.pages = nil
+	dp.keyColumns = keyColumns
+	dp.view = view
+	dp.total = total
+	dp.complete = complete
+	dp.fetching = false
+
+	var after []interface{}
+	for start := 0; start < data.Len(); start += dataPageSize {
+		count := data.Len() - start
+		if count > dataPageSize {
+			count = dataPageSize
+		}
+		page := dataPage{start: start, count: count, offset: start, after: after, loaded: true}
+		page.last = dp.keyValues(data, start+count-1)
+		dp.pages = append(dp.pages, page)
+		after = page.last
+	}
+}
+
+// keyValues captures the key of a row as it was fetched, before any staged
+// edit can change it.
+func (dp *dataPager) keyValues(data *ResultSet, row int) []interface{} {
+	if len(dp.keyColumns) == 0 {
+		return nil
+	}
+	values := make([]interface{}, 0, len(dp.keyColumns))
+	for _, col := range dp.keyColumns {
+		val, ok := data.Lookup(row, col)
+		if !ok {
+			return nil
+		}
+		values = append(values, val)
+	}
+	return values
+}
+
+func (dp *dataPager) pageOf(row int) int {
+	for idx, page := range dp.pages {
+		if row >= page.start && row < page.start+page.count {
+			return idx
+		}
+	}
+	return -1
+}
+
+func (dp *dataPager) loadedRows() int {
+	rows := 0
+	for _, page := range dp.pages {
+		if page.loaded {
+			rows += page.count
+		}
+	}
+	return rows
+}
+
+func (dp *dataPager) released() bool {
+	for _, page := range dp.pages {
+		if !page.loaded {
+			return true
+		}
+	}
+	return false
+}
+
+// request returns the page needed to show rows first..last, or the next page
+// when last is close to the end of what has been fetched. Nothing is returned
+// while another page is being fetched; the returned request is marked as in
+// flight until apply or fail hears back about it.
+func (dp *dataPager) request(first, last, rowCount, prefetch int) (DataPageRequest, bool) {
+	if dp.fetching {
+		return DataPageRequest{}, false
+	}
+	req, ok := dp.nextRequest(first, last, rowCount, prefetch)
+	dp.fetching = ok
+	return req, ok
+}
+
+func (dp *dataPager) nextRequest(first, last, rowCount, prefetch int) (DataPageRequest, bool) {
+	for idx, page := range dp.pages {
+		if page.loaded || page.start > last || page.start+page.count <= first {
+			continue
+		}
+		return DataPageRequest{
+			generation: dp.generation,
+			page:       idx,
+			options:    TableQueryOptions{TableView: dp.view, Limit: page.count, Offset: page.offset, After: page.after},
+		}, true
+	}
+
+	if dp.complete || last < rowCount-prefetch {
+		return DataPageRequest{}, false
+	}
+	next := TableQueryOptions{TableView: dp.view, Limit: dataPageSize}
+	if n := len(dp.pages); n > 0 {
+		prev := dp.pages[n-1]
+		next.Offset = prev.offset + prev.count
+		next.After = prev.last
+	}
+	return DataPageRequest{generation: dp.generation, page: len(dp.pages), options: next}, true
+}
+
+// fail forgets a request whose fetch went wrong so the page can be asked for
+// again.
+func (dp *dataPager) fail(req DataPageRequest) {
+	if req.generation == dp.generation {
+		dp.fetching = false
+	}
+}
+
+// apply stores a fetched page, appending it when it is new or refilling its
+// rows when it had been released.
+func (dp *dataPager) apply(data *ResultSet, req DataPageRequest, rows *ResultSet) bool {
+	if req.generation != dp.generation || req.page > len(dp.pages) {
+		return false
+	}
+	dp.fetching = false
+
+	if req.page == len(dp.pages) {
+		page := dataPage{
+			start:  data.Len(),
+			count:  rows.Len(),
+			offset: req.options.Offset,
+			after:  req.options.After,
+			loaded: true,
+		}
+		data.Rows = append(data.Rows, rows.Rows...)
+		page.last = dp.keyValues(data, page.start+page.count-1)
+		dp.complete = rows.Len() < req.options.Limit
+		if page.count > 0 {
+			dp.pages = append(dp.pages, page)
+		}
+		return true
+	}
+
+	page := &dp.pages[req.page]
+	for idx := 0; idx < page.count; idx++ {
+		if idx < rows.Len() {
+			data.Rows[page.start+idx] = rows.Rows[idx]
+		} else {
+			data.Rows[page.start+idx] = nil
+		}
+	}
+	page.loaded = true
+	return true
+}
+
+// release drops the rows of pages outside the window around the selected
+// row. Pages holding staged changes are kept so edits stay visible.
+func (dp *dataPager) release(data *ResultSet, selected int, pinned func(row int) bool) {
+	center := dp.pageOf(selected)
+	if center < 0 {
+		return
+	}
+	for idx := range dp.pages {
+		page := &dp.pages[idx]
+		distance := idx - center
+		if distance < 0 {
+			distance = -distance
+		}
+		if !page.loaded || distance <= dataPageWindow/2 {
+			continue
+		}
+		keep := false
+		for row := page.start; row < page.start+page.count && !keep; row++ {
+			keep = pinned(row)
+		}
+		if keep {
+			continue
+		}
+		for row := page.start; row < page.start+page.count; row++ {
+			data.Rows[row] = nil
+		}
+		page.loaded = false
+	}
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+// pagedRows returns n rows of a single "id" column numbered from 1.
+func pagedRows(n int) *ResultSet {
+	rs := &ResultSet{Columns: []ResultColumn{{Name: "id"}}}
+	for idx := 0; idx < n; idx++ {
+		rs.Rows = append(rs.Rows, []interface{}{int64(idx + 1)})
+	}
+	return rs
+}
+
+func TestDataPagerRequest(t *testing.T) {
+	tests := []struct {
+		name     string
+		rows     int
+		complete bool
+		released int // page to release before asking, or -1
+		first    int
+		last     int
+		prefetch int
+		want     DataPageRequest
+		wantOK   bool
+	}{
+		{
+			name:     "next page past the prefetch threshold",
+			rows:     200,
+			released: -1,
+			first:    150,
+			last:     195,
+			prefetch: 10,
+			want: DataPageRequest{page: 2, options: TableQueryOptions{
+				Limit: dataPageSize, Offset: 200, After: []interface{}{int64(200)},
+			}},
+			wantOK: true,
+		},
+		{
+			name:     "nothing before the prefetch threshold",
+			rows:     200,
+			released: -1,
+			first:    100,
+			last:     180,
+			prefetch: 10,
+		},
+		{
+			name:     "nothing once the table is complete",
+			rows:     200,
+			complete: true,
+			released: -1,
+			first:    150,
+			last:     199,
+			prefetch: 10,
+		},
+		{
+			name:     "released page in view is fetched again",
+			rows:     300,
+			released: 1,
+			first:    120,
+			last:     140,
+			prefetch: 10,
+			want: DataPageRequest{page: 1, options: TableQueryOptions{
+				Limit: dataPageSize, Offset: 100, After: []interface{}{int64(100)},
+			}},
+			wantOK: true,
+		},
+		{
+			name:     "released page out of view is left alone",
+			rows:     300,
+			released: 0,
+			first:    120,
+			last:     140,
+			prefetch: 10,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := pagedRows(tt.rows)
+			var dp dataPager
+			dp.reset(data, []string{"id"}, TableView{}, tt.complete, int64(tt.rows))
+			if tt.released >= 0 {
+				dp.pages[tt.released].loaded = false
+			}
+			tt.want.generation = dp.generation
+
+			got, ok := dp.request(tt.first, tt.last, data.Len(), tt.prefetch)
+			if ok != tt.wantOK {
+				t.Fatalf("request() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if ok && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("request() = %+v, want %+v", got, tt.want)
+			}
+			if dp.fetching != ok {
+				t.Errorf("fetching = %v, want %v", dp.fetching, ok)
+			}
+		})
+	}
+}
+
+func TestDataPagerOneFetchAtATime(t *testing.T) {
+	data := pagedRows(100)
+	var dp dataPager
+	dp.reset(data, []string{"id"}, TableView{}, false, 0)
+
+	req, ok := dp.request(90, 99, data.Len(), 10)
+	if !ok {
+		t.Fatalf("request() found nothing to fetch")
+	}
+	if _, ok := dp.request(90, 99, data.Len(), 10); ok {
+		t.Fatalf("request() issued a second fetch while one was pending")
+	}
+
+	dp.fail(DataPageRequest{generation: dp.generation - 1})
+	if !dp.fetching {
+		t.Fatalf("fail() of a stale request released the pending fetch")
+	}
+	dp.fail(req)
+	retry, ok := dp.request(90, 99, data.Len(), 10)
+	if !ok || !reflect.DeepEqual(retry, req) {
+		t.Fatalf("request() after fail = %+v, %v, want %+v", retry, ok, req)
+	}
+
+	if !dp.apply(data, retry, pagedRows(dataPageSize)) {
+		t.Fatalf("apply() rejected the pending page")
+	}
+	if dp.fetching {
+		t.Errorf("apply() left the pager fetching")
+	}
+	if data.Len() != 200 || len(dp.pages) != 2 {
+		t.Errorf("after apply: %d rows in %d pages, want 200 in 2", data.Len(), len(dp.pages))
+	}
+}
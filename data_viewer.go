@@ -3,199 +3,242 @@ package main
 import (
 	"fmt"
 	"strings"
+	"time"
 
+	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
 )
 
+const statementListRows = 6
+
+// The results grid never gets shorter than this, however tall the editor.
+const minResultGridHeight = 6
+
+// DataViewer shows the outcome of the statements run from the query editor.
+// Rows go to a grid that shares the Data pane's model and renderer, so
+// selection, scrolling and column widths work the same in both.
 type DataViewer struct {
-	results     []map[string]interface{}
-	columns     []string
-	verticalPos int
-	width       int
-	height      int
+	grid       *PaneModel
+	renderer   *PaneRenderer
+	statements []StatementResult
+	current    int
+	skipped    int
 }
 
 func NewDataViewer() *DataViewer {
 	return &DataViewer{
-		results:     make([]map[string]interface{}, 0),
-		columns:     make([]string, 0),
-		verticalPos: 0,
-		width:       80,
-		height:      20,
-	}
-}
-
-func (dv *DataViewer) SetResults(results []map[string]interface{}) {
-	dv.results = results
-	if len(results) > 0 {
-		columns := make([]string, 0, len(results[0]))
-		for col := range results[0] {
-			columns = append(columns, col)
-		}
-		dv.columns = columns
+		grid:     NewPaneModel(),
+		renderer: NewPaneRenderer(),
 	}
-	dv.verticalPos = 0
-}
-
-func (dv *DataViewer) GetResults() []map[string]interface{} {
-	return dv.results
 }
 
-func (dv *DataViewer) View() string {
-	if len(dv.results) == 0 {
-		return dv.renderEmptyState()
-	}
-
-	return dv.renderTable()
+func (dv *DataViewer) SetResults(results *ResultSet) {
+	dv.grid.SetData(results)
 }
 
-func (dv *DataViewer) renderEmptyState() string {
-	emptyMsg := "No data to display"
-
-	return lipgloss.NewStyle().
-		Width(60).
-		Align(lipgloss.Center).
-		Height(10).
-		Background(lipgloss.Color("#1a1a1a")).
-		Border(lipgloss.RoundedBorder()).
-		Padding(2, 1).
-		Render(emptyMsg)
+func (dv *DataViewer) GetResults() *ResultSet {
+	return dv.grid.GetData()
 }
 
-func (dv *DataViewer) renderTable() string {
-	columnWidths := dv.calculateColumnWidths()
-	header := dv.renderHeader(columnWidths)
-	separator := dv.renderSeparator(columnWidths)
-	body := dv.renderBody(columnWidths)
-
-	return header + "\n" + separator + "\n" + body
+// Grid is the model of the results grid, for navigation and copying.
+func (dv *DataViewer) Grid() *PaneModel {
+	return dv.grid
 }
 
-func (dv *DataViewer) calculateColumnWidths() map[string]int {
-	widths := make(map[string]int)
-
-	for _, col := range dv.columns {
-		widths[col] = len(col)
-	}
+// SetStatements shows the outcome of a script. The first failed statement is
+// selected, otherwise the last one that returned rows.
+func (dv *DataViewer) SetStatements(statements []StatementResult, skipped int) {
+	dv.statements = statements
+	dv.skipped = skipped
 
-	for _, row := range dv.results {
-		for col, val := range row {
-			valStr := fmt.Sprintf("%v", val)
-			if len(valStr) > widths[col] {
-				widths[col] = len(valStr)
-			}
+	selected := len(statements) - 1
+	for idx := len(statements) - 1; idx >= 0; idx-- {
+		if statements[idx].ReturnsRows() {
+			selected = idx
+			break
 		}
 	}
-
-	for col := range widths {
-		if widths[col] > 30 {
-			widths[col] = 30
-		}
-		if widths[col] < 10 {
-			widths[col] = 10
+	for idx, statement := range statements {
+		if statement.Err != nil {
+			selected = idx
+			break
 		}
 	}
-
-	return widths
+	dv.selectStatement(selected)
 }
 
-func (dv *DataViewer) renderHeader(columnWidths map[string]int) string {
-	parts := make([]string, 0, len(dv.columns))
-
-	for _, col := range dv.columns {
-		style := lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#FFD700")).
-			Bold(true).
-			Width(columnWidths[col])
-
-		parts = append(parts, style.Render(col))
+func (dv *DataViewer) selectStatement(idx int) {
+	dv.current = idx
+	if idx >= 0 && idx < len(dv.statements) {
+		dv.SetResults(dv.statements[idx].Results)
+		return
 	}
-
-	return strings.Join(parts, " ")
+	dv.SetResults(nil)
 }
 
-func (dv *DataViewer) renderSeparator(columnWidths map[string]int) string {
-	parts := make([]string, 0, len(dv.columns))
-
-	for _, col := range dv.columns {
-		style := lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#808080"))
-
-		parts = append(parts, style.Render(strings.Repeat("-", columnWidths[col])))
+func (dv *DataViewer) NextStatement() {
+	if dv.current < len(dv.statements)-1 {
+		dv.selectStatement(dv.current + 1)
 	}
+}
 
-	return strings.Join(parts, " ")
+func (dv *DataViewer) PrevStatement() {
+	if dv.current > 0 {
+		dv.selectStatement(dv.current - 1)
+	}
 }
 
-func (dv *DataViewer) renderBody(columnWidths map[string]int) string {
-	maxRows := dv.height - 4
-	startRow := dv.verticalPos
+func (dv *DataViewer) StatementCount() int {
+	return len(dv.statements)
+}
 
-	if startRow+maxRows > len(dv.results) {
-		startRow = len(dv.results) - maxRows
+// View lays out the statement list, the results grid and the messages panel
+// in height lines; the grid takes whatever the other two leave.
+func (dv *DataViewer) View(width, height int, focused bool) string {
+	if len(dv.statements) == 0 {
+		return dv.renderEmptyState()
 	}
-	if startRow < 0 {
-		startRow = 0
+
+	list := dv.renderStatementList()
+	messages := dv.renderMessages()
+	if !dv.statements[dv.current].ReturnsRows() {
+		return list + "\n" + messages
 	}
 
-	endRow := startRow + maxRows
-	if endRow > len(dv.results) {
-		endRow = len(dv.results)
+	gridHeight := height - lipgloss.Height(list) - lipgloss.Height(messages) - 2
+	if gridHeight < minResultGridHeight {
+		gridHeight = minResultGridHeight
 	}
+	title := "Resultado"
+	if len(dv.statements) > 1 {
+		title = fmt.Sprintf("Resultado %d/%d", dv.current+1, len(dv.statements))
+	}
+	grid := dv.renderer.renderDataPane(dv.grid, title, width-2, gridHeight, focused)
+	return list + "\n" + grid + "\n" + messages
+}
 
-	rows := make([]string, 0, endRow-startRow)
+// renderMessages is the panel below the results with the outcome, timing and
+// server notices of the selected statement.
+func (dv *DataViewer) renderMessages() string {
+	statement := dv.statements[dv.current]
+	noticeStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#87CEEB"))
 
-	for i := startRow; i < endRow; i++ {
-		row := dv.results[i]
-		rowStrs := dv.renderRow(row, columnWidths)
-		rows = append(rows, rowStrs...)
+	var lines []string
+	for _, notice := range statement.Notices {
+		lines = append(lines, noticeStyle.Render(notice))
+	}
+	elapsed := statement.Duration.Round(time.Millisecond)
+	if statement.Err != nil {
+		lines = append(lines, lipgloss.NewStyle().Foreground(lipgloss.Color("#FF6B6B")).
+			Render(fmt.Sprintf("✖ %v (%s)", statement.Err, elapsed)))
+	} else {
+		lines = append(lines, lipgloss.NewStyle().Foreground(lipgloss.Color("#50FA7B")).
+			Render(fmt.Sprintf("✔ %s em %s", describeStatementOutcome(statement), elapsed)))
 	}
 
-	return strings.Join(rows, "\n")
+	return lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("#808080")).
+		Padding(0, 1).
+		Render("Mensagens\n" + strings.Join(lines, "\n"))
 }
 
-func (dv *DataViewer) renderRow(row map[string]interface{}, columnWidths map[string]int) []string {
-	parts := make([]string, 0, len(dv.columns))
-
-	for _, col := range dv.columns {
-		val := row[col]
-		valStr := fmt.Sprintf("%v", val)
+func (dv *DataViewer) renderStatementList() string {
+	start := 0
+	if dv.current >= statementListRows {
+		start = dv.current - statementListRows + 1
+	}
 
-		if len(valStr) > 30 {
-			valStr = valStr[:27] + "..."
+	lines := make([]string, 0, statementListRows+1)
+	for idx := start; idx < len(dv.statements) && idx < start+statementListRows; idx++ {
+		statement := dv.statements[idx]
+		marker := "  "
+		style := lipgloss.NewStyle().Foreground(lipgloss.Color("#FFFFFF"))
+		if statement.Err != nil {
+			style = style.Foreground(lipgloss.Color("#FF6B6B"))
+		}
+		if idx == dv.current {
+			marker = "▶ "
+			style = style.Background(lipgloss.Color("#083863")).Bold(true)
 		}
 
-		style := lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#FFFFFF")).
-			Width(columnWidths[col])
-
-		parts = append(parts, style.Render(valStr))
+		// Cut and pad by display width so multi-byte and wide characters
+		// neither split nor push the columns out of line.
+		text := strings.Join(strings.Fields(statement.Statement), " ")
+		text = runewidth.FillRight(runewidth.Truncate(text, 50, "..."), 50)
+		outcome := describeStatementOutcome(statement)
+		if len(statement.Notices) > 0 {
+			outcome += fmt.Sprintf(" (%d aviso(s))", len(statement.Notices))
+		}
+		lines = append(lines, style.Render(fmt.Sprintf("%s%2d. %s %-34s %8s",
+			marker, idx+1, text, outcome, statement.Duration.Round(time.Millisecond))))
 	}
-
-	return []string{strings.Join(parts, " ")}
-}
-
-func (dv *DataViewer) ScrollUp() {
-	if dv.verticalPos > 0 {
-		dv.verticalPos--
+	if dv.skipped > 0 {
+		lines = append(lines, lipgloss.NewStyle().Foreground(lipgloss.Color("#808080")).
+			Render(fmt.Sprintf("    %d instrução(ões) não executada(s)", dv.skipped)))
 	}
+	return strings.Join(lines, "\n")
 }
 
-func (dv *DataViewer) ScrollDown() {
-	visibleRows := dv.height - 4
-	if dv.verticalPos < len(dv.results)-visibleRows {
-		dv.verticalPos++
+func describeStatementOutcome(statement StatementResult) string {
+	switch {
+	case statement.Err != nil:
+		return "erro"
+	case statement.ReturnsRows():
+		return fmt.Sprintf("%d linha(s)", statement.Results.Len())
+	case statement.RowsAffected >= 0:
+		return fmt.Sprintf("%d linha(s) afetada(s)", statement.RowsAffected)
 	}
+	return "OK"
 }
 
-func (dv *DataViewer) ScrollToTop() {
-	dv.verticalPos = 0
+func (dv *DataViewer) renderEmptyState() string {
+	emptyMsg := "No data to display"
+
+	return lipgloss.NewStyle().
+		Width(60).
+		Align(lipgloss.Center).
+		Height(10).
+		Background(lipgloss.Color("#1a1a1a")).
+		Border(lipgloss.RoundedBorder()).
+		Padding(2, 1).
+		Render(emptyMsg)
 }
 
-func (dv *DataViewer) ScrollToBottom() {
-	visibleRows := dv.height - 4
-	dv.verticalPos = len(dv.results) - visibleRows
-	if dv.verticalPos < 0 {
-		dv.verticalPos = 0
-	}
+// handleResultsInput drives the results grid while it has the focus: the
+// Data pane's navigation and copy keys, Ctrl+N/P between statements and
+// Esc or Ctrl+↑ back to the editor.
+func (app *XTreeGoldApp) handleResultsInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	grid := app.dataViewer.Grid()
+	if grid.HandleDataKey(msg) {
+		return app, nil
+	}
+
+	switch msg.Type {
+	case tea.KeyEscape, tea.KeyCtrlUp:
+		app.resultsFocused = false
+		return app, nil
+	case tea.KeyCtrlN:
+		app.dataViewer.NextStatement()
+		return app, nil
+	case tea.KeyCtrlP:
+		app.dataViewer.PrevStatement()
+		return app, nil
+	case tea.KeyCtrlE:
+		app.beginExport(true)
+		return app, nil
+	}
+
+	switch msg.String() {
+	case "c":
+		return app, app.copyCell(grid)
+	case "C":
+		return app, app.copyRow(grid, RowCopyTSV)
+	case "J":
+		return app, app.copyRow(grid, RowCopyJSON)
+	case "I":
+		return app, app.copyRow(grid, RowCopyInsert)
+	}
+	return app, nil
 }
@@ -1,13 +1,23 @@
 package main
 
-import tea "github.com/charmbracelet/bubbletea"
+import (
+	"context"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
 
 type DatabaseLoader interface {
-	LoadTreeAsync(serverName string) tea.Cmd
-	LoadChildren(node *TreeNode) error
-	GetTableData(database, schema, table string, limit, offset int) ([]map[string]interface{}, error)
-	UpdateCell(database, schema, table, column, rowID string, value interface{}) error
-	InsertRow(database, schema, table string, values map[string]interface{}) error
-	DeleteRow(database, schema, table, rowID string) error
-	ExecuteQuery(query string) ([]map[string]interface{}, error)
+	LoadTreeAsync(ctx context.Context, serverName string) tea.Cmd
+	LoadChildren(ctx context.Context, node *TreeNode) error
+	GetTableData(ctx context.Context, database, schema, table string, opts TableQueryOptions) (*ResultSet, error)
+	GetTableRowCount(ctx context.Context, database, schema, table string, view TableView) (int64, error)
+	GetRowKeyColumns(ctx context.Context, database, schema, table string) ([]string, error)
+	BuildChangeSQL(schema, table string, change PendingChange) (string, []interface{}, error)
+	ApplyChanges(ctx context.Context, database, schema, table string, changes []PendingChange) error
+	ImportRows(ctx context.Context, database, schema, table string, rows []map[string]interface{}) (ImportResult, error)
+	BeginTransaction(ctx context.Context) (*QueryTransaction, error)
+	OpenSession(ctx context.Context, tx *QueryTransaction) (*QuerySession, error)
+	ExecuteStatement(ctx context.Context, session *QuerySession, statement string, args ...interface{}) StatementResult
+	TableExportSource(ctx context.Context, database, schema, table string, columns []string, view TableView) ExportSource
+	QueryExportSource(results *ResultSet) ExportSource
 }
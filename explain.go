@@ -0,0 +1,361 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Sequential scans expected to read at least this many rows are flagged.
+const largeSeqScanRows = 10000
+
+// A node whose own cost (or time, after ANALYZE) is at least this share of
+// the whole plan is highlighted as expensive.
+const hotPlanShare = 0.25
+
+// PlanNode is one step of a query plan. Rows and times follow PostgreSQL:
+// ActualRows and ActualTime are per loop. SQLite plans only carry Title.
+type PlanNode struct {
+	Title       string
+	Details     []string
+	StartupCost float64
+	TotalCost   float64
+	PlanRows    float64
+	ActualRows  float64
+	ActualTime  float64
+	Loops       float64
+	HasCost     bool
+	HasActual   bool
+	SeqScan     bool
+	Children    []*PlanNode
+	Expanded    bool
+	Level       int
+	Parent      *PlanNode
+	share       float64
+}
+
+// QueryPlan is the parsed output of EXPLAIN for one statement. CanAnalyze
+// tells whether the dialect has an EXPLAIN ANALYZE to re-run it with.
+type QueryPlan struct {
+	Statement     string
+	Root          *PlanNode
+	Analyzed      bool
+	CanAnalyze    bool
+	PlanningTime  float64
+	ExecutionTime float64
+}
+
+// ExplainResultMsg carries a plan back from the background task.
+type ExplainResultMsg struct {
+	plan *QueryPlan
+	err  error
+}
+
+// Hot reports whether the node accounts for a large share of the plan.
+func (pn *PlanNode) Hot() bool {
+	return pn.share >= hotPlanShare
+}
+
+// LargeSeqScan flags full scans over many rows. SQLite gives no estimates,
+// so every full table scan is flagged there.
+func (pn *PlanNode) LargeSeqScan() bool {
+	if !pn.SeqScan {
+		return false
+	}
+	if !pn.HasCost {
+		return true
+	}
+	return pn.PlanRows >= largeSeqScanRows || pn.ActualRows*pn.Loops >= largeSeqScanRows
+}
+
+// explainSQL wraps statement in the dialect's EXPLAIN. SQLite has no
+// ANALYZE variant, so analyze is ignored there.
+func explainSQL(dialect ConnectionType, statement string, analyze bool) (string, error) {
+	switch dialect {
+	case ConnectionPostgres:
+		if analyze {
+			return "EXPLAIN (FORMAT JSON, ANALYZE, BUFFERS) " + statement, nil
+		}
+		return "EXPLAIN (FORMAT JSON) " + statement, nil
+	case ConnectionSQLite:
+		return "EXPLAIN QUERY PLAN " + statement, nil
+	}
+	return "", fmt.Errorf("query plans are not supported for %s", driverLabels[dialect])
+}
+
+// runExplain fetches the plan of statement. EXPLAIN ANALYZE executes the
+// statement, so outside an explicit transaction it runs in one that is
+// rolled back afterwards.
+func runExplain(ctx context.Context, loader DatabaseLoader, tx *QueryTransaction, dialect ConnectionType, statement string, analyze bool) (*QueryPlan, error) {
+	query, err := explainSQL(dialect, statement, analyze)
+	if err != nil {
+		return nil, err
+	}
+	analyze = analyze && dialect == ConnectionPostgres
+
+	if analyze && tx == nil {
+		tx, err = loader.BeginTransaction(ctx)
+		if err != nil {
+			return nil, err
+		}
+		defer tx.Rollback()
+	} else if tx != nil {
+		tx.countStatement()
+	}
+
+	session, err := loader.OpenSession(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+	defer session.Close()
+
+	result := loader.ExecuteStatement(ctx, session, query)
+	if result.Err != nil {
+		return nil, result.Err
+	}
+
+	var plan *QueryPlan
+	if dialect == ConnectionPostgres {
+		plan, err = parsePostgresPlan(result.Results)
+	} else {
+		plan, err = parseSQLitePlan(result.Results)
+	}
+	if err != nil {
+		return nil, err
+	}
+	plan.Statement = statement
+	plan.Analyzed = analyze
+	plan.CanAnalyze = dialect == ConnectionPostgres
+	plan.measure()
+	return plan, nil
+}
+
+func parsePostgresPlan(results *ResultSet) (*QueryPlan, error) {
+	if results.Len() == 0 {
+		return nil, fmt.Errorf("EXPLAIN returned no plan")
+	}
+	var raw string
+	switch v := results.Value(0, 0).(type) {
+	case string:
+		raw = v
+	case []byte:
+		raw = string(v)
+	default:
+		return nil, fmt.Errorf("unexpected EXPLAIN output %T", v)
+	}
+
+	var doc []struct {
+		Plan          map[string]interface{} `json:"Plan"`
+		PlanningTime  float64                `json:"Planning Time"`
+		ExecutionTime float64                `json:"Execution Time"`
+	}
+	if err := json.Unmarshal([]byte(raw), &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse plan: %w", err)
+	}
+	if len(doc) == 0 || doc[0].Plan == nil {
+		return nil, fmt.Errorf("EXPLAIN returned no plan")
+	}
+	return &QueryPlan{
+		Root:          postgresPlanNode(doc[0].Plan, nil),
+		PlanningTime:  doc[0].PlanningTime,
+		ExecutionTime: doc[0].ExecutionTime,
+	}, nil
+}
+
+func postgresPlanNode(fields map[string]interface{}, parent *PlanNode) *PlanNode {
+	text := func(key string) string {
+		if value, ok := fields[key].(string); ok {
+			return value
+		}
+		return ""
+	}
+	number := func(key string) (float64, bool) {
+		value, ok := fields[key].(float64)
+		return value, ok
+	}
+
+	nodeType := text("Node Type")
+	title := nodeType
+	if index := text("Index Name"); index != "" {
+		title += " using " + index
+	}
+	if relation := text("Relation Name"); relation != "" {
+		title += " on " + relation
+		if alias := text("Alias"); alias != "" && alias != relation {
+			title += " " + alias
+		}
+	}
+
+	node := &PlanNode{
+		Title:    title,
+		SeqScan:  nodeType == "Seq Scan",
+		Expanded: true,
+		Parent:   parent,
+	}
+	if parent != nil {
+		node.Level = parent.Level + 1
+	}
+	node.StartupCost, _ = number("Startup Cost")
+	node.TotalCost, node.HasCost = number("Total Cost")
+	node.PlanRows, _ = number("Plan Rows")
+	if rows, ok := number("Actual Rows"); ok {
+		node.HasActual = true
+		node.ActualRows = rows
+		node.ActualTime, _ = number("Actual Total Time")
+		node.Loops, _ = number("Actual Loops")
+	}
+
+	for _, key := range []string{"Join Type", "Hash Cond", "Merge Cond", "Index Cond", "Recheck Cond", "Filter", "Join Filter"} {
+		if value := text(key); value != "" {
+			node.Details = append(node.Details, key+": "+value)
+		}
+	}
+	if removed, ok := number("Rows Removed by Filter"); ok && removed > 0 {
+		node.Details = append(node.Details, fmt.Sprintf("Rows Removed by Filter: %.0f", removed))
+	}
+	for _, key := range []string{"Sort Key", "Group Key"} {
+		if values, ok := fields[key].([]interface{}); ok {
+			parts := make([]string, len(values))
+			for idx, value := range values {
+				parts[idx] = fmt.Sprint(value)
+			}
+			node.Details = append(node.Details, key+": "+strings.Join(parts, ", "))
+		}
+	}
+	hit, hasHit := number("Shared Hit Blocks")
+	read, hasRead := number("Shared Read Blocks")
+	if hasHit || hasRead {
+		node.Details = append(node.Details, fmt.Sprintf("Buffers: shared hit=%.0f read=%.0f", hit, read))
+	}
+
+	if children, ok := fields["Plans"].([]interface{}); ok {
+		for _, child := range children {
+			if childFields, ok := child.(map[string]interface{}); ok {
+				node.Children = append(node.Children, postgresPlanNode(childFields, node))
+			}
+		}
+	}
+	return node
+}
+
+// parseSQLitePlan rebuilds the tree from EXPLAIN QUERY PLAN rows (id,
+// parent, notused, detail) under a synthetic root.
+func parseSQLitePlan(results *ResultSet) (*QueryPlan, error) {
+	idCol, parentCol, detailCol := results.ColumnIndex("id"), results.ColumnIndex("parent"), results.ColumnIndex("detail")
+	if idCol < 0 || parentCol < 0 || detailCol < 0 {
+		return nil, fmt.Errorf("unexpected EXPLAIN QUERY PLAN columns %v", results.ColumnNames())
+	}
+
+	root := &PlanNode{Title: "QUERY PLAN", Expanded: true}
+	nodes := map[int64]*PlanNode{0: root}
+	for row := 0; row < results.Len(); row++ {
+		id := planInt(results.Value(row, idCol))
+		parent, ok := nodes[planInt(results.Value(row, parentCol))]
+		if !ok {
+			parent = root
+		}
+		detail := fmt.Sprint(results.Value(row, detailCol))
+		node := &PlanNode{
+			Title:    detail,
+			SeqScan:  strings.HasPrefix(detail, "SCAN ") && !strings.Contains(detail, " USING "),
+			Expanded: true,
+			Level:    parent.Level + 1,
+			Parent:   parent,
+		}
+		parent.Children = append(parent.Children, node)
+		nodes[id] = node
+	}
+	return &QueryPlan{Root: root}, nil
+}
+
+func planInt(value interface{}) int64 {
+	switch v := value.(type) {
+	case int64:
+		return v
+	case int:
+		return int64(v)
+	case float64:
+		return int64(v)
+	default:
+		n, _ := strconv.ParseInt(fmt.Sprint(v), 10, 64)
+		return n
+	}
+}
+
+// measure works out each node's exclusive share of the plan: time when the
+// plan was analyzed, otherwise estimated cost.
+func (qp *QueryPlan) measure() {
+	total := qp.Root.inclusive(qp.Analyzed)
+	if total <= 0 {
+		return
+	}
+	var walk func(node *PlanNode)
+	walk = func(node *PlanNode) {
+		self := node.inclusive(qp.Analyzed)
+		for _, child := range node.Children {
+			self -= child.inclusive(qp.Analyzed)
+			walk(child)
+		}
+		if self > 0 {
+			node.share = self / total
+		}
+	}
+	walk(qp.Root)
+}
+
+func (pn *PlanNode) inclusive(analyzed bool) float64 {
+	if analyzed {
+		return pn.ActualTime * pn.Loops
+	}
+	return pn.TotalCost
+}
+
+// explainStatement shows the plan of the statement under the cursor.
+func (app *XTreeGoldApp) explainStatement(analyze bool) tea.Cmd {
+	statement, ok := app.queryEditor.StatementAtCursor()
+	if !ok {
+		app.setStatus("⚠ Nenhuma instrução para explicar")
+		return nil
+	}
+	return app.explain(statement.Text, analyze)
+}
+
+func (app *XTreeGoldApp) explain(statement string, analyze bool) tea.Cmd {
+	if app.dbLoader == nil || app.currentConnection == nil || !app.tabConnected() {
+		return nil
+	}
+	loader, tx, dialect := app.dbLoader, app.transaction, app.currentConnection.Type
+	label := "Obtendo plano"
+	if analyze {
+		label = "Executando EXPLAIN ANALYZE"
+	}
+	return app.startTask(label, func(ctx context.Context) tea.Msg {
+		plan, err := runExplain(ctx, loader, tx, dialect, statement, analyze)
+		return ExplainResultMsg{plan: plan, err: err}
+	})
+}
+
+// handlePlanInput drives the plan viewer: a re-runs with ANALYZE where the
+// dialect has it, e goes back to the estimated plan and Esc returns to the
+// editor.
+func (app *XTreeGoldApp) handlePlanInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case msg.Type == tea.KeyEscape || msg.Type == tea.KeyCtrlX:
+		app.planViewer = nil
+	case msg.String() == "a":
+		if !app.planViewer.Plan().CanAnalyze {
+			app.setStatus("⚠ SQLite não tem EXPLAIN ANALYZE; só o plano estimado está disponível")
+			return app, nil
+		}
+		return app, app.explain(app.planViewer.Plan().Statement, true)
+	case msg.String() == "e":
+		return app, app.explain(app.planViewer.Plan().Statement, false)
+	default:
+		app.planViewer.Update(msg)
+	}
+	return app, nil
+}
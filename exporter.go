@@ -0,0 +1,476 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+type ExportFormat int
+
+const (
+	ExportCSV ExportFormat = iota
+	ExportJSON
+	ExportNDJSON
+	ExportMarkdown
+	ExportSQL
+)
+
+var exportFormats = []ExportFormat{ExportCSV, ExportJSON, ExportNDJSON, ExportMarkdown, ExportSQL}
+
+const exportPageSize = 1000
+
+// Query results have no table of their own; SQL exports target this name.
+const queryExportTable = "query_result"
+
+func (ef ExportFormat) String() string {
+	switch ef {
+	case ExportCSV:
+		return "CSV"
+	case ExportJSON:
+		return "JSON"
+	case ExportNDJSON:
+		return "NDJSON"
+	case ExportMarkdown:
+		return "Markdown"
+	case ExportSQL:
+		return "SQL INSERT"
+	default:
+		return "Unknown"
+	}
+}
+
+func (ef ExportFormat) Extension() string {
+	switch ef {
+	case ExportJSON:
+		return ".json"
+	case ExportNDJSON:
+		return ".ndjson"
+	case ExportMarkdown:
+		return ".md"
+	case ExportSQL:
+		return ".sql"
+	default:
+		return ".csv"
+	}
+}
+
+func exportFormatFromPath(path string) (ExportFormat, bool) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return ExportCSV, true
+	case ".json":
+		return ExportJSON, true
+	case ".ndjson", ".jsonl":
+		return ExportNDJSON, true
+	case ".md", ".markdown":
+		return ExportMarkdown, true
+	case ".sql":
+		return ExportSQL, true
+	default:
+		return ExportCSV, false
+	}
+}
+
+func withExportExtension(path string, format ExportFormat) string {
+	if _, ok := exportFormatFromPath(path); ok {
+		path = strings.TrimSuffix(path, filepath.Ext(path))
+	}
+	return path + format.Extension()
+}
+
+// ExportSource streams rows page by page so large tables never have to be
+// held in memory at once. NextPage returns an empty page when exhausted.
+type ExportSource struct {
+	Columns  []string
+	Table    string
+	Dialect  sqlDialect
+	NextPage func() (*ResultSet, error)
+}
+
+func staticExportSource(results *ResultSet) ExportSource {
+	done := false
+	return ExportSource{
+		Columns: results.ColumnNames(),
+		NextPage: func() (*ResultSet, error) {
+			if done {
+				return nil, nil
+			}
+			done = true
+			return results, nil
+		},
+	}
+}
+
+// tableExportSource pages through a table in the order the Data pane shows
+// it: the view's sort and filters, with the key columns breaking ties. Like
+// dataPager it seeks past the previous page's last key instead of skipping
+// rows.
+func tableExportSource(columns []string, view TableView, dialect sqlDialect, keyColumns func() ([]string, error), fetch func(opts TableQueryOptions) (*ResultSet, error)) ExportSource {
+	opts := TableQueryOptions{TableView: view, Limit: exportPageSize}
+	pager := dataPager{}
+	started, done := false, false
+	return ExportSource{
+		Columns: columns,
+		Dialect: dialect,
+		NextPage: func() (*ResultSet, error) {
+			if done {
+				return nil, nil
+			}
+			if !started {
+				keyCols, err := keyColumns()
+				if err != nil {
+					return nil, err
+				}
+				pager.keyColumns = keyCols
+				started = true
+			}
+			page, err := fetch(opts)
+			if err != nil {
+				return nil, err
+			}
+			opts.Offset += page.Len()
+			if page.Len() > 0 {
+				opts.After = pager.keyValues(page, page.Len()-1)
+			}
+			if page.Len() < exportPageSize {
+				done = true
+			}
+			return page, nil
+		},
+	}
+}
+
+// exportColumnIndexes maps the exported columns onto a page. Pages whose
+// columns match exactly are read positionally so duplicate names survive.
+func exportColumnIndexes(columns []string, page *ResultSet) []int {
+	indexes := make([]int, len(columns))
+	names := page.ColumnNames()
+	positional := len(names) == len(columns)
+	for i := range columns {
+		if positional && names[i] != columns[i] {
+			positional = false
+		}
+	}
+	for i, col := range columns {
+		if positional {
+			indexes[i] = i
+		} else {
+			indexes[i] = page.ColumnIndex(col)
+		}
+	}
+	return indexes
+}
+
+// ExportToFile writes the export to path. An existing file is only replaced
+// when overwrite is set; otherwise the error wraps os.ErrExist.
+func ExportToFile(path string, format ExportFormat, source ExportSource, overwrite bool) (int, error) {
+	flags := os.O_WRONLY | os.O_CREATE | os.O_EXCL
+	if overwrite {
+		flags = os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	}
+	file, err := os.OpenFile(path, flags, 0o666)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create %s: %w", path, err)
+	}
+
+	count, err := WriteExport(file, format, source)
+	if closeErr := file.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("failed to close %s: %w", path, closeErr)
+	}
+	return count, err
+}
+
+func WriteExport(w io.Writer, format ExportFormat, source ExportSource) (int, error) {
+	buf := bufio.NewWriter(w)
+	var writer exportWriter
+	switch format {
+	case ExportCSV:
+		writer = &csvExportWriter{w: csv.NewWriter(buf), out: buf}
+	case ExportJSON:
+		writer = &jsonExportWriter{w: buf}
+	case ExportNDJSON:
+		writer = &jsonExportWriter{w: buf, lines: true}
+	case ExportMarkdown:
+		writer = &markdownExportWriter{w: buf}
+	case ExportSQL:
+		if source.Table == "" {
+			return 0, fmt.Errorf("SQL export requires a target table")
+		}
+		writer = &sqlExportWriter{w: buf, table: source.Table, dialect: source.Dialect}
+	default:
+		return 0, fmt.Errorf("unsupported export format %d", format)
+	}
+
+	if err := writer.Begin(source.Columns); err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for {
+		page, err := source.NextPage()
+		if err != nil {
+			return count, err
+		}
+		if page.Len() == 0 {
+			break
+		}
+		indexes := exportColumnIndexes(source.Columns, page)
+		values := make([]interface{}, len(indexes))
+		for rowIdx := 0; rowIdx < page.Len(); rowIdx++ {
+			for i, colIdx := range indexes {
+				values[i] = page.Value(rowIdx, colIdx)
+			}
+			if err := writer.Row(source.Columns, values); err != nil {
+				return count, err
+			}
+			count++
+		}
+	}
+
+	if err := writer.End(); err != nil {
+		return count, err
+	}
+	return count, buf.Flush()
+}
+
+type exportWriter interface {
+	Begin(columns []string) error
+	Row(columns []string, values []interface{}) error
+	End() error
+}
+
+// csvNull marks NULL in CSV files, which have no other way to tell it from an
+// empty string; it is the marker PostgreSQL COPY and MySQL LOAD DATA use.
+const csvNull = `\N`
+
+type csvExportWriter struct {
+	w   *csv.Writer
+	out *bufio.Writer
+}
+
+func (cw *csvExportWriter) Begin(columns []string) error {
+	return cw.w.Write(columns)
+}
+
+func (cw *csvExportWriter) Row(columns []string, values []interface{}) error {
+	record := make([]string, len(values))
+	for idx, value := range values {
+		if value == nil {
+			record[idx] = csvNull
+			continue
+		}
+		record[idx] = exportText(value)
+	}
+	// encoding/csv writes a lone empty field as a blank line, which readers
+	// skip; quote it so the row survives an import.
+	if len(record) == 1 && record[0] == "" {
+		cw.w.Flush()
+		if err := cw.w.Error(); err != nil {
+			return err
+		}
+		_, err := cw.out.WriteString("\"\"\n")
+		return err
+	}
+	return cw.w.Write(record)
+}
+
+func (cw *csvExportWriter) End() error {
+	cw.w.Flush()
+	return cw.w.Error()
+}
+
+type jsonExportWriter struct {
+	w     *bufio.Writer
+	lines bool
+	count int
+}
+
+func (jw *jsonExportWriter) Begin(columns []string) error {
+	if jw.lines {
+		return nil
+	}
+	_, err := jw.w.WriteString("[\n")
+	return err
+}
+
+// Objects are written by hand so keys keep the result set's column order.
+func (jw *jsonExportWriter) Row(columns []string, values []interface{}) error {
+	var sb strings.Builder
+	if !jw.lines {
+		if jw.count > 0 {
+			sb.WriteString(",\n")
+		}
+		sb.WriteString("  ")
+	}
+	sb.WriteString("{")
+	for idx, col := range columns {
+		if idx > 0 {
+			sb.WriteString(",")
+		}
+		key, err := json.Marshal(col)
+		if err != nil {
+			return err
+		}
+		value, err := json.Marshal(exportJSONValue(values[idx]))
+		if err != nil {
+			return fmt.Errorf("failed to encode %s: %w", col, err)
+		}
+		sb.Write(key)
+		sb.WriteString(":")
+		sb.Write(value)
+	}
+	sb.WriteString("}")
+	if jw.lines {
+		sb.WriteString("\n")
+	}
+	jw.count++
+	_, err := jw.w.WriteString(sb.String())
+	return err
+}
+
+func (jw *jsonExportWriter) End() error {
+	if jw.lines {
+		return nil
+	}
+	if jw.count > 0 {
+		if _, err := jw.w.WriteString("\n"); err != nil {
+			return err
+		}
+	}
+	_, err := jw.w.WriteString("]\n")
+	return err
+}
+
+type markdownExportWriter struct {
+	w *bufio.Writer
+}
+
+func (mw *markdownExportWriter) Begin(columns []string) error {
+	header := make([]string, len(columns))
+	divider := make([]string, len(columns))
+	for idx, col := range columns {
+		header[idx] = markdownEscape(col)
+		divider[idx] = "---"
+	}
+	_, err := fmt.Fprintf(mw.w, "| %s |\n| %s |\n", strings.Join(header, " | "), strings.Join(divider, " | "))
+	return err
+}
+
+func (mw *markdownExportWriter) Row(columns []string, values []interface{}) error {
+	cells := make([]string, len(values))
+	for idx, value := range values {
+		if value == nil {
+			cells[idx] = "NULL"
+			continue
+		}
+		cells[idx] = markdownEscape(exportText(value))
+	}
+	_, err := fmt.Fprintf(mw.w, "| %s |\n", strings.Join(cells, " | "))
+	return err
+}
+
+func (mw *markdownExportWriter) End() error {
+	return nil
+}
+
+func markdownEscape(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, "|", `\|`)
+	value = strings.ReplaceAll(value, "\r\n", "<br>")
+	return strings.ReplaceAll(value, "\n", "<br>")
+}
+
+type sqlExportWriter struct {
+	w       *bufio.Writer
+	table   string
+	dialect sqlDialect
+	columns string
+}
+
+func (sw *sqlExportWriter) Begin(columns []string) error {
+	sw.columns = sw.dialect.quoteList(columns)
+	return nil
+}
+
+func (sw *sqlExportWriter) Row(columns []string, values []interface{}) error {
+	literals := make([]string, len(values))
+	for idx, value := range values {
+		literals[idx] = sw.dialect.literal(value)
+	}
+	_, err := fmt.Fprintf(sw.w, "INSERT INTO %s (%s) VALUES (%s);\n", sw.table, sw.columns, strings.Join(literals, ", "))
+	return err
+}
+
+func (sw *sqlExportWriter) End() error {
+	return nil
+}
+
+// Loaders only keep []byte for binary columns; bytes that happen to be valid
+// UTF-8 are still exported as readable text and the rest is hex encoded.
+func exportBytes(b []byte) (string, bool) {
+	if utf8.Valid(b) {
+		return string(b), false
+	}
+	return hex.EncodeToString(b), true
+}
+
+func exportText(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case []byte:
+		text, binary := exportBytes(v)
+		if binary {
+			return `\x` + text
+		}
+		return text
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case string:
+		return v
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+func exportJSONValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case []byte:
+		text, binary := exportBytes(v)
+		if binary {
+			return `\x` + text
+		}
+		return text
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	default:
+		return v
+	}
+}
+
+func defaultExportPath(name string, format ExportFormat) string {
+	name = strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r == ' ' {
+			return '_'
+		}
+		return r
+	}, name)
+	if name == "" {
+		name = "query_" + time.Now().Format("20060102_150405")
+	}
+	return name + format.Extension()
+}
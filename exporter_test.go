@@ -0,0 +1,43 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestWriteExportCSV(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+		want  string
+	}{
+		{name: "null uses the marker", value: nil, want: "v\n\\N\n"},
+		{name: "empty string stays empty", value: "", want: "v\n\"\"\n"},
+		{name: "text with a comma is quoted", value: "a,b", want: "v\n\"a,b\"\n"},
+		{name: "readable bytes are text", value: []byte("abc"), want: "v\nabc\n"},
+		{name: "binary bytes are hex", value: []byte{0xff, 0x00}, want: "v\n\\xff00\n"},
+		{name: "bool", value: true, want: "v\ntrue\n"},
+		{name: "time is RFC 3339", value: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC), want: "v\n2024-05-01T12:00:00Z\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := &ResultSet{
+				Columns: []ResultColumn{{Name: "v"}},
+				Rows:    [][]interface{}{{tt.value}},
+			}
+			var out bytes.Buffer
+			count, err := WriteExport(&out, ExportCSV, staticExportSource(results))
+			if err != nil {
+				t.Fatalf("WriteExport() error = %v", err)
+			}
+			if count != 1 {
+				t.Errorf("WriteExport() count = %d, want 1", count)
+			}
+			if out.String() != tt.want {
+				t.Errorf("WriteExport() = %q, want %q", out.String(), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// EditorFinishedMsg is sent when the external editor exits; run asks for the
+// edited query to be executed right away.
+type EditorFinishedMsg struct {
+	path string
+	run  bool
+	err  error
+}
+
+// editorCommand splits $VISUAL or $EDITOR so values such as "code --wait"
+// work; vi is the fallback.
+func editorCommand() (string, []string) {
+	for _, name := range []string{"VISUAL", "EDITOR"} {
+		if fields := strings.Fields(os.Getenv(name)); len(fields) > 0 {
+			return fields[0], fields[1:]
+		}
+	}
+	return "vi", nil
+}
+
+// openInEditor writes text to a temporary .sql file and suspends the program
+// while the user's editor runs on it.
+func openInEditor(text string, run bool) tea.Cmd {
+	file, err := os.CreateTemp("", "windsurf-tui-*.sql")
+	if err != nil {
+		return func() tea.Msg {
+			return EditorFinishedMsg{err: fmt.Errorf("failed to create temp file: %w", err)}
+		}
+	}
+	path := file.Name()
+	if _, err := file.WriteString(text); err != nil {
+		file.Close()
+		os.Remove(path)
+		return func() tea.Msg {
+			return EditorFinishedMsg{err: fmt.Errorf("failed to write temp file: %w", err)}
+		}
+	}
+	file.Close()
+
+	name, args := editorCommand()
+	cmd := exec.Command(name, append(args, path)...)
+	return tea.ExecProcess(cmd, func(err error) tea.Msg {
+		if err != nil {
+			// A failed run (or :cq in vim) keeps the query as it was.
+			os.Remove(path)
+			return EditorFinishedMsg{err: fmt.Errorf("%s: %w", name, err)}
+		}
+		return EditorFinishedMsg{path: path, run: run}
+	})
+}
+
+// readEditedQuery loads the file back and removes it. Editors usually end
+// the file with a newline, which is dropped.
+func readEditedQuery(path string) (string, error) {
+	defer os.Remove(path)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read edited query: %w", err)
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
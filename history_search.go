@@ -0,0 +1,136 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+)
+
+const historySearchRows = 10
+
+// HistorySearch is the incremental search overlay of the query view.
+type HistorySearch struct {
+	history  *QueryHistory
+	input    *TextInput
+	matches  []HistoryEntry
+	selected int
+	closed   bool
+}
+
+// HistoryPickMsg carries the query chosen in the search overlay; run asks
+// for it to be executed right away instead of only loaded in the editor.
+type HistoryPickMsg struct {
+	query string
+	run   bool
+}
+
+func NewHistorySearch(history *QueryHistory, width int) *HistorySearch {
+	input := NewTextInput()
+	input.SetWidth(width)
+	input.SetPlaceholder("palavras da consulta")
+	hs := &HistorySearch{history: history, input: input}
+	hs.refresh()
+	return hs
+}
+
+func (hs *HistorySearch) refresh() {
+	hs.matches = hs.history.Search(hs.input.Value())
+	if hs.selected >= len(hs.matches) {
+		hs.selected = len(hs.matches) - 1
+	}
+	if hs.selected < 0 {
+		hs.selected = 0
+	}
+}
+
+func (hs *HistorySearch) IsClosed() bool {
+	return hs.closed
+}
+
+func (hs *HistorySearch) Init() tea.Cmd { return nil }
+
+func (hs *HistorySearch) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return hs, nil
+	}
+
+	switch keyMsg.Type {
+	case tea.KeyEscape:
+		hs.closed = true
+		return hs, nil
+	case tea.KeyUp:
+		if hs.selected > 0 {
+			hs.selected--
+		}
+		return hs, nil
+	case tea.KeyDown, tea.KeyCtrlR:
+		if hs.selected < len(hs.matches)-1 {
+			hs.selected++
+		}
+		return hs, nil
+	case tea.KeyEnter, tea.KeyTab:
+		if hs.selected >= len(hs.matches) {
+			return hs, nil
+		}
+		hs.closed = true
+		pick := HistoryPickMsg{query: hs.matches[hs.selected].Query, run: keyMsg.Type == tea.KeyTab}
+		return hs, func() tea.Msg { return pick }
+	}
+
+	if hs.input.HandleKey(keyMsg) {
+		hs.selected = 0
+		hs.refresh()
+	}
+	return hs, nil
+}
+
+func (hs *HistorySearch) View() string {
+	lines := []string{hs.input.View(fmt.Sprintf("🔎 Histórico (%d consulta(s))", len(hs.matches)))}
+
+	start := 0
+	if hs.selected >= historySearchRows {
+		start = hs.selected - historySearchRows + 1
+	}
+	for idx := start; idx < len(hs.matches) && idx < start+historySearchRows; idx++ {
+		line := describeHistoryEntry(hs.matches[idx], hs.input.width)
+		style := lipgloss.NewStyle().Foreground(lipgloss.Color("#FFFFFF"))
+		if hs.matches[idx].Error != "" {
+			style = style.Foreground(lipgloss.Color("#FF6B6B"))
+		}
+		if idx == hs.selected {
+			style = style.Background(lipgloss.Color("#083863")).Bold(true)
+		}
+		lines = append(lines, style.Render(line))
+	}
+	if len(hs.matches) == 0 {
+		lines = append(lines, lipgloss.NewStyle().Foreground(lipgloss.Color("#808080")).Render("  (nenhuma consulta encontrada)"))
+	}
+
+	lines = append(lines, lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#888888")).
+		Italic(true).
+		Render("↑/↓ Ctrl+R Navega | Enter Edita | Tab Executa | Esc Fecha"))
+	return strings.Join(lines, "\n")
+}
+
+func describeHistoryEntry(entry HistoryEntry, width int) string {
+	outcome := fmt.Sprintf("%d linha(s)", entry.Rows)
+	if entry.Error != "" {
+		outcome = "erro"
+	}
+	prefix := fmt.Sprintf("%s %8s %-12s ",
+		entry.ExecutedAt.Local().Format("2006-01-02 15:04"),
+		entry.Duration.Round(time.Millisecond),
+		outcome)
+
+	query := strings.Join(strings.Fields(entry.Query), " ")
+	if room := width - runewidth.StringWidth(prefix); room > 3 {
+		query = runewidth.Truncate(query, room, "...")
+	}
+	return prefix + query
+}
@@ -0,0 +1,315 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+type importStep int
+
+const (
+	importStepFile importStep = iota
+	importStepMapping
+	importStepRunning
+	importStepResult
+)
+
+const (
+	importPreviewRows  = 3
+	importFailureLines = 10
+)
+
+type ImportWizard struct {
+	database     string
+	schema       string
+	table        string
+	tableColumns []*TreeNode
+	step         importStep
+	pathInput    *TextInput
+	file         *ImportFile
+	targets      []string
+	cursor       int
+	err          string
+	result       ImportResult
+	resultErr    error
+	closed       bool
+}
+
+type RunImportMsg struct {
+	database string
+	schema   string
+	table    string
+	rows     []map[string]interface{}
+}
+
+func NewImportWizard(database, schema, table string, columns []*TreeNode) *ImportWizard {
+	pathInput := NewTextInput()
+	pathInput.SetWidth(60)
+	pathInput.SetPlaceholder(table + ".csv")
+	return &ImportWizard{
+		database:     database,
+		schema:       schema,
+		table:        table,
+		tableColumns: columns,
+		pathInput:    pathInput,
+	}
+}
+
+func (iw *ImportWizard) Init() tea.Cmd { return nil }
+
+func (iw *ImportWizard) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return iw, nil
+	}
+
+	switch iw.step {
+	case importStepFile:
+		return iw.updateFileStep(keyMsg)
+	case importStepMapping:
+		return iw.updateMappingStep(keyMsg)
+	case importStepResult:
+		if keyMsg.Type == tea.KeyEnter || keyMsg.Type == tea.KeyEscape {
+			iw.closed = true
+		}
+	}
+	return iw, nil
+}
+
+func (iw *ImportWizard) updateFileStep(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEscape:
+		iw.closed = true
+		return iw, nil
+	case tea.KeyEnter:
+		path := strings.TrimSpace(iw.pathInput.Value())
+		if path == "" {
+			return iw, nil
+		}
+		file, err := ReadImportFile(path)
+		if err != nil {
+			iw.err = err.Error()
+			return iw, nil
+		}
+		iw.file = file
+		iw.targets = autoMapColumns(file.Columns, iw.tableColumns)
+		iw.cursor = 0
+		iw.err = ""
+		iw.step = importStepMapping
+		return iw, nil
+	}
+
+	iw.pathInput.HandleKey(msg)
+	return iw, nil
+}
+
+func (iw *ImportWizard) updateMappingStep(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEscape:
+		iw.step = importStepFile
+		iw.err = ""
+	case tea.KeyUp:
+		if iw.cursor > 0 {
+			iw.cursor--
+		}
+	case tea.KeyDown:
+		if iw.cursor < len(iw.file.Columns)-1 {
+			iw.cursor++
+		}
+	case tea.KeyLeft:
+		iw.cycleTarget(-1)
+	case tea.KeyRight:
+		iw.cycleTarget(1)
+	case tea.KeyEnter:
+		if !iw.hasMappedColumns() {
+			iw.err = "Mapeie ao menos uma coluna"
+			return iw, nil
+		}
+		if len(iw.file.Rows) == 0 {
+			iw.err = "O arquivo não contém linhas"
+			return iw, nil
+		}
+		iw.err = ""
+		iw.step = importStepRunning
+		database, schema, table := iw.database, iw.schema, iw.table
+		rows := iw.file.MappedRows(iw.targets)
+		return iw, func() tea.Msg {
+			return RunImportMsg{database: database, schema: schema, table: table, rows: rows}
+		}
+	}
+	return iw, nil
+}
+
+// cycleTarget moves the selected file column through "skip" and every table
+// column in order.
+func (iw *ImportWizard) cycleTarget(delta int) {
+	if iw.cursor < 0 || iw.cursor >= len(iw.targets) {
+		return
+	}
+
+	options := make([]string, 0, len(iw.tableColumns)+1)
+	options = append(options, "")
+	current := 0
+	for idx, col := range iw.tableColumns {
+		options = append(options, col.Name)
+		if col.Name == iw.targets[iw.cursor] {
+			current = idx + 1
+		}
+	}
+
+	next := (current + delta + len(options)) % len(options)
+	iw.targets[iw.cursor] = options[next]
+}
+
+func (iw *ImportWizard) hasMappedColumns() bool {
+	for _, target := range iw.targets {
+		if target != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func (iw *ImportWizard) SetResult(result ImportResult, err error) {
+	iw.result = result
+	iw.resultErr = err
+	iw.step = importStepResult
+}
+
+// Running reports whether the wizard is waiting for the import to finish.
+func (iw *ImportWizard) Running() bool {
+	return iw.step == importStepRunning
+}
+
+func (iw *ImportWizard) IsClosed() bool {
+	return iw.closed
+}
+
+func (iw *ImportWizard) tableColumn(name string) *TreeNode {
+	for _, col := range iw.tableColumns {
+		if col.Name == name {
+			return col
+		}
+	}
+	return nil
+}
+
+func (iw *ImportWizard) View() string {
+	title := fmt.Sprintf("📥 Importar em %s.%s", iw.schema, iw.table)
+
+	var lines []string
+	header := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#FFD700")).
+		Bold(true).
+		Render(title)
+	lines = append(lines, header, "")
+
+	var helpText string
+	switch iw.step {
+	case importStepFile:
+		lines = append(lines, iw.pathInput.View("Arquivo CSV ou JSON (.csv, .json, .ndjson)"))
+		helpText = "Enter Lê o arquivo | Esc Cancela"
+	case importStepMapping:
+		lines = append(lines, iw.renderMapping()...)
+		lines = append(lines, "")
+		lines = append(lines, iw.renderPreview()...)
+		helpText = "↑/↓ Coluna do arquivo | ←/→ Coluna destino | Enter Importa | Esc Volta"
+	case importStepRunning:
+		lines = append(lines, fmt.Sprintf("Importando %d linha(s)...", len(iw.file.Rows)))
+	case importStepResult:
+		lines = append(lines, iw.renderResult()...)
+		helpText = "Enter/Esc Fecha"
+	}
+
+	if iw.err != "" {
+		errLine := lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#FF6B6B")).
+			Bold(true).
+			Render("⚠ " + iw.err)
+		lines = append(lines, "", errLine)
+	}
+
+	if helpText != "" {
+		helpLine := lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#888888")).
+			Italic(true).
+			Render(helpText)
+		lines = append(lines, "", helpLine)
+	}
+
+	return lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("#4169E1")).
+		Padding(1, 2).
+		Render(strings.Join(lines, "\n"))
+}
+
+func (iw *ImportWizard) renderMapping() []string {
+	lines := []string{fmt.Sprintf("%s: %d linha(s), %d coluna(s)", iw.file.Path, len(iw.file.Rows), len(iw.file.Columns))}
+
+	width := 0
+	for _, col := range iw.file.Columns {
+		width = max(width, len(col))
+	}
+
+	for idx, fileCol := range iw.file.Columns {
+		target := "(ignorar)"
+		if col := iw.tableColumn(iw.targets[idx]); col != nil {
+			target = fmt.Sprintf("%s %s", col.Name, col.Metadata.DataType)
+		}
+
+		marker := " "
+		style := lipgloss.NewStyle().Foreground(lipgloss.Color("#FFFFFF"))
+		if idx == iw.cursor {
+			marker = "▶"
+			style = style.Background(lipgloss.Color("#1F3B73"))
+		}
+		if iw.targets[idx] == "" {
+			style = style.Foreground(lipgloss.Color("#808080"))
+		}
+		lines = append(lines, style.Render(fmt.Sprintf("%s %-*s → %s", marker, width, fileCol, target)))
+	}
+	return lines
+}
+
+func (iw *ImportWizard) renderPreview() []string {
+	lines := []string{lipgloss.NewStyle().Bold(true).Render("Prévia dos valores convertidos")}
+	for rowIdx := 0; rowIdx < len(iw.file.Rows) && rowIdx < importPreviewRows; rowIdx++ {
+		values := iw.file.MappedRow(rowIdx, iw.targets)
+		var cells []string
+		for _, col := range sortedValueColumns(values) {
+			cells = append(cells, fmt.Sprintf("%s=%s", col, describeImportValue(values[col])))
+		}
+		lines = append(lines, fmt.Sprintf("%d: %s", rowIdx+1, strings.Join(cells, ", ")))
+	}
+	return lines
+}
+
+func describeImportValue(value interface{}) string {
+	if value == nil {
+		return "NULL"
+	}
+	return fmt.Sprintf("%s (%T)", formatPreviewArg(value), value)
+}
+
+func (iw *ImportWizard) renderResult() []string {
+	var lines []string
+	if iw.resultErr != nil {
+		// The import runs in one transaction, so an error means nothing was kept.
+		lines = append(lines, fmt.Sprintf("✖ Importação desfeita, nenhuma linha gravada: %v", iw.resultErr))
+	} else {
+		lines = append(lines, fmt.Sprintf("✔ %d linha(s) inserida(s), %d falha(s)", iw.result.Inserted, len(iw.result.Failures)))
+	}
+
+	for idx, failure := range iw.result.Failures {
+		if idx == importFailureLines {
+			lines = append(lines, fmt.Sprintf("... e mais %d falha(s)", len(iw.result.Failures)-idx))
+			break
+		}
+		lines = append(lines, fmt.Sprintf("linha %d: %v", failure.Row, failure.Err))
+	}
+	return lines
+}
@@ -0,0 +1,289 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ImportFile holds the raw records of a CSV or JSON file. Columns keep the
+// order in which they first appear in the file.
+type ImportFile struct {
+	Path    string
+	Columns []string
+	Rows    []map[string]interface{}
+}
+
+type ImportFailure struct {
+	Row int
+	Err error
+}
+
+type ImportResult struct {
+	Inserted int
+	Failures []ImportFailure
+}
+
+func ReadImportFile(path string) (*ImportFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	var file *ImportFile
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json", ".ndjson", ".jsonl":
+		file, err = parseJSONImport(data)
+	default:
+		file, err = parseCSVImport(data)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if len(file.Columns) == 0 {
+		return nil, fmt.Errorf("%s has no columns", path)
+	}
+
+	file.Path = path
+	return file, nil
+}
+
+func parseCSVImport(data []byte) (*ImportFile, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return &ImportFile{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(header) > 0 {
+		header[0] = strings.TrimPrefix(header[0], "\ufeff")
+	}
+
+	file := &ImportFile{Columns: header}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		row := make(map[string]interface{}, len(header))
+		for idx, col := range header {
+			if idx >= len(record) {
+				continue
+			}
+			if record[idx] == csvNull {
+				row[col] = nil
+			} else {
+				row[col] = record[idx]
+			}
+		}
+		file.Rows = append(file.Rows, row)
+	}
+	return file, nil
+}
+
+// parseJSONImport accepts either an array of objects or newline-delimited
+// objects.
+func parseJSONImport(data []byte) (*ImportFile, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	file := &ImportFile{}
+	seen := make(map[string]bool)
+
+	inArray := false
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		if _, err := decoder.Token(); err != nil {
+			return nil, err
+		}
+		inArray = true
+	}
+
+	for decoder.More() {
+		keys, row, err := decodeJSONObject(decoder)
+		if err != nil {
+			return nil, fmt.Errorf("record %d: %w", len(file.Rows)+1, err)
+		}
+		for _, key := range keys {
+			if !seen[key] {
+				seen[key] = true
+				file.Columns = append(file.Columns, key)
+			}
+		}
+		file.Rows = append(file.Rows, row)
+	}
+
+	if inArray {
+		if _, err := decoder.Token(); err != nil {
+			return nil, err
+		}
+	}
+	return file, nil
+}
+
+func decodeJSONObject(decoder *json.Decoder) ([]string, map[string]interface{}, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, nil, err
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '{' {
+		return nil, nil, fmt.Errorf("expected an object")
+	}
+
+	var keys []string
+	row := make(map[string]interface{})
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, nil, err
+		}
+		key, ok := token.(string)
+		if !ok {
+			return nil, nil, fmt.Errorf("expected an object key")
+		}
+		var value interface{}
+		if err := decoder.Decode(&value); err != nil {
+			return nil, nil, err
+		}
+		keys = append(keys, key)
+		row[key] = value
+	}
+
+	if _, err := decoder.Token(); err != nil {
+		return nil, nil, err
+	}
+	return keys, row, nil
+}
+
+// autoMapColumns pairs file columns with table columns of the same name,
+// ignoring case. Unmatched file columns map to "" and are skipped.
+func autoMapColumns(fileColumns []string, tableColumns []*TreeNode) []string {
+	targets := make([]string, len(fileColumns))
+	for idx, fileCol := range fileColumns {
+		for _, tableCol := range tableColumns {
+			if strings.EqualFold(strings.TrimSpace(fileCol), tableCol.Name) {
+				targets[idx] = tableCol.Name
+				break
+			}
+		}
+	}
+	return targets
+}
+
+func (f *ImportFile) MappedRow(rowIdx int, targets []string) map[string]interface{} {
+	values := make(map[string]interface{})
+	if rowIdx < 0 || rowIdx >= len(f.Rows) {
+		return values
+	}
+	row := f.Rows[rowIdx]
+	for idx, fileCol := range f.Columns {
+		if idx >= len(targets) || targets[idx] == "" {
+			continue
+		}
+		raw, ok := row[fileCol]
+		if !ok {
+			continue
+		}
+		values[targets[idx]] = coerceImportValue(raw)
+	}
+	return values
+}
+
+func (f *ImportFile) MappedRows(targets []string) []map[string]interface{} {
+	rows := make([]map[string]interface{}, len(f.Rows))
+	for idx := range f.Rows {
+		rows[idx] = f.MappedRow(idx, targets)
+	}
+	return rows
+}
+
+// Text values go through the same rules as values typed in the Data pane,
+// except that an empty string stays empty: NULL has its own marker in CSV and
+// its own literal in JSON. JSON scalars keep their own type and nested values
+// are stored as JSON.
+func coerceImportValue(raw interface{}) interface{} {
+	switch v := raw.(type) {
+	case nil:
+		return nil
+	case string:
+		if v == "" {
+			return v
+		}
+		return coerceInputValue(v)
+	case json.Number:
+		return coerceInputValue(v.String())
+	case bool:
+		return v
+	default:
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprintf("%v", v)
+		}
+		return string(encoded)
+	}
+}
+
+// importRowsInTx inserts rows in a single transaction, so a cancelled or
+// failed import leaves the table as it was. Each row runs under a savepoint
+// so a failing row is recorded and skipped without discarding the others.
+func importRowsInTx(ctx context.Context, db *sql.DB, rows []map[string]interface{}, build func(PendingChange) (string, []interface{}, error)) (ImportResult, error) {
+	var result ImportResult
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return result, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	inserted := 0
+	for idx, row := range rows {
+		if err := insertImportRow(ctx, tx, row, build); err != nil {
+			if ctx.Err() != nil {
+				tx.Rollback()
+				return result, fmt.Errorf("import stopped at row %d, nothing was inserted: %w", idx+1, ctx.Err())
+			}
+			result.Failures = append(result.Failures, ImportFailure{Row: idx + 1, Err: err})
+			continue
+		}
+		inserted++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return result, fmt.Errorf("failed to commit import, nothing was inserted: %w", err)
+	}
+	result.Inserted = inserted
+	return result, nil
+}
+
+func insertImportRow(ctx context.Context, tx *sql.Tx, values map[string]interface{}, build func(PendingChange) (string, []interface{}, error)) error {
+	query, args, err := build(PendingChange{Kind: ChangeInsert, Values: values})
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, "SAVEPOINT import_row"); err != nil {
+		return fmt.Errorf("failed to create savepoint: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+		if _, rbErr := tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT import_row"); rbErr != nil {
+			return fmt.Errorf("%v (rollback failed: %w)", err, rbErr)
+		}
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, "RELEASE SAVEPOINT import_row"); err != nil {
+		return fmt.Errorf("failed to release savepoint: %w", err)
+	}
+	return nil
+}
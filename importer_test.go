@@ -0,0 +1,111 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCSVNullRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		rows [][]interface{}
+		want []map[string]interface{}
+	}{
+		{
+			name: "null and empty string stay apart",
+			rows: [][]interface{}{{int64(1), nil}, {int64(2), ""}, {int64(3), "x"}},
+			want: []map[string]interface{}{
+				{"id": int64(1), "name": nil},
+				{"id": int64(2), "name": ""},
+				{"id": int64(3), "name": "x"},
+			},
+		},
+		{
+			name: "text that looks like a value is coerced",
+			rows: [][]interface{}{{int64(1), "true"}, {int64(2), "2.5"}},
+			want: []map[string]interface{}{
+				{"id": int64(1), "name": true},
+				{"id": int64(2), "name": 2.5},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := &ResultSet{
+				Columns: []ResultColumn{{Name: "id"}, {Name: "name"}},
+				Rows:    tt.rows,
+			}
+			path := filepath.Join(t.TempDir(), "rows.csv")
+			if _, err := ExportToFile(path, ExportCSV, staticExportSource(results), false); err != nil {
+				t.Fatalf("ExportToFile() error = %v", err)
+			}
+
+			file, err := ReadImportFile(path)
+			if err != nil {
+				t.Fatalf("ReadImportFile() error = %v", err)
+			}
+			if got := file.MappedRows([]string{"id", "name"}); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("MappedRows() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCSVSingleEmptyColumnRoundTrip(t *testing.T) {
+	results := &ResultSet{
+		Columns: []ResultColumn{{Name: "name"}},
+		Rows:    [][]interface{}{{""}, {nil}, {""}},
+	}
+	var out bytes.Buffer
+	if _, err := WriteExport(&out, ExportCSV, staticExportSource(results)); err != nil {
+		t.Fatalf("WriteExport() error = %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "rows.csv")
+	if err := os.WriteFile(path, out.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := ReadImportFile(path)
+	if err != nil {
+		t.Fatalf("ReadImportFile() error = %v", err)
+	}
+	want := []map[string]interface{}{{"name": ""}, {"name": nil}, {"name": ""}}
+	if !reflect.DeepEqual(file.Rows, want) {
+		t.Errorf("Rows = %#v, want %#v", file.Rows, want)
+	}
+}
+
+func TestCoerceImportValue(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  interface{}
+		want interface{}
+	}{
+		{name: "nil", raw: nil, want: nil},
+		{name: "empty string is not null", raw: "", want: ""},
+		{name: "null text", raw: "NULL", want: nil},
+		{name: "integer text", raw: "42", want: int64(42)},
+		{name: "float text", raw: "1.5", want: 1.5},
+		{name: "bool text", raw: "false", want: false},
+		{name: "date text", raw: "2024-05-01", want: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)},
+		{name: "plain text", raw: "hello", want: "hello"},
+		{name: "json number", raw: json.Number("7"), want: int64(7)},
+		{name: "json bool", raw: true, want: true},
+		{name: "json object", raw: map[string]interface{}{"a": json.Number("1")}, want: `{"a":1}`},
+		{name: "json array", raw: []interface{}{"x", nil}, want: `["x",null]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := coerceImportValue(tt.raw); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("coerceImportValue(%#v) = %#v, want %#v", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
@@ -21,6 +21,8 @@ func NewDatabaseLoader(db *sql.DB, connInfo *ConnectionInfo) (DatabaseLoader, er
 	switch connType {
 	case ConnectionSQLite:
 		return NewSQLiteTreeLoader(db, connInfo), nil
+	case ConnectionMySQL:
+		return NewMySQLTreeLoader(db, connInfo), nil
 	case ConnectionPostgres:
 		fallthrough
 	default:
@@ -1,7 +1,9 @@
 package main
 
 import (
+	"context"
 	"fmt"
+	"os"
 	"strconv"
 	"strings"
 	"time"
@@ -37,6 +39,35 @@ type XTreeGoldApp struct {
 	connectionStep    ConnectionStep
 	statusMessage     string
 	statusTimestamp   time.Time
+	showChangePreview bool
+	exportInput       *TextInput
+	filterInput       *TextInput
+	filtering         bool
+	exportFormat      ExportFormat
+	exporting         bool
+	exportFromQuery   bool
+	exportReplace     string // existing file the user was asked to replace
+	importWizard      *ImportWizard
+	task              *backgroundTask
+	nextTaskID        int
+	history           *QueryHistory
+	historySearch     *HistorySearch
+	historyPos        int
+	historyDraft      string
+	transaction       *QueryTransaction
+	txPrompt          bool
+	txAfter           tea.Cmd
+	planViewer        *PlanViewer
+	paramPrompt       *ParamPrompt
+	resultsFocused    bool
+	tabs              []*QueryTab
+	activeTab         int
+	nextTabID         int
+	renamingTab       bool
+	tabNameInput      *TextInput
+	catalog           *SchemaCatalog
+	snippets          *SnippetLibrary
+	snippetBrowser    *SnippetBrowser
 }
 
 type AppStyles struct {
@@ -55,6 +86,7 @@ const (
 	FocusData
 	FocusConnectionDialog
 	FocusAddConnectionForm
+	FocusImport
 )
 
 type DataEditMode int
@@ -65,6 +97,8 @@ const (
 	DataEditInsertRow
 )
 
+const statusMessageTTL = 10 * time.Second
+
 type ConnectionStep int
 
 const (
@@ -86,7 +120,8 @@ type TreeLoadedMsg struct {
 }
 
 type ExecuteQueryMsg struct {
-	query string
+	query  string
+	params *QueryParams
 }
 
 type LoadTableDataMsg struct {
@@ -95,35 +130,98 @@ type LoadTableDataMsg struct {
 	table    string
 	rowIndex int
 	colIndex int
+	view     TableView
+}
+
+type QueryResultMsg struct {
+	query      string
+	started    time.Time
+	duration   time.Duration
+	statements []StatementResult
+	skipped    int
+	params     *QueryParams
 }
 
-type UpdateCellMsg struct {
+type TableDataLoadedMsg struct {
 	database string
 	schema   string
 	table    string
-	rowID    string
-	column   string
-	value    interface{}
 	rowIndex int
 	colIndex int
+	view     TableView
+	keyCols  []string
+	results  *ResultSet
+	complete bool
+	total    int64
+	err      error
 }
 
-type InsertRowMsg struct {
+type DataPageLoadedMsg struct {
+	request DataPageRequest
+	results *ResultSet
+	err     error
+}
+
+type ApplyChangesMsg struct {
 	database string
 	schema   string
 	table    string
-	values   map[string]interface{}
+	changes  []PendingChange
 	rowIndex int
 	colIndex int
 }
 
-type DeleteRowMsg struct {
+type ChangesAppliedMsg struct {
 	database string
 	schema   string
 	table    string
-	rowID    string
+	count    int
 	rowIndex int
 	colIndex int
+	err      error
+}
+
+type ExportMsg struct {
+	path      string
+	format    ExportFormat
+	fromQuery bool
+	overwrite bool
+}
+
+type ExportDoneMsg struct {
+	path      string
+	fromQuery bool
+	count     int
+	err       error
+}
+
+type OpenImportMsg struct {
+	node     *TreeNode
+	database string
+	schema   string
+	table    string
+	columns  []*TreeNode
+	err      error
+}
+
+type ImportDoneMsg struct {
+	result ImportResult
+	err    error
+}
+
+// PaneChildrenLoadedMsg and TreeChildrenLoadedMsg carry children loaded in
+// the background; they are attached to node when the message is handled.
+type PaneChildrenLoadedMsg struct {
+	node     *TreeNode
+	children []*TreeNode
+	pane     PaneType
+	err      error
+}
+
+type TreeChildrenLoadedMsg struct {
+	node     *TreeNode
+	children []*TreeNode
+	err      error
 }
 
 type ConnectionSavedMsg struct {
@@ -146,6 +244,7 @@ func NewXTreeGoldApp() (*XTreeGoldApp, error) {
 
 	tree := NewTreeModel(nil)
 	paneModel := NewPaneModel()
+	tab := NewQueryTab("Consulta 1")
 	app := &XTreeGoldApp{
 		tree:           &tree,
 		navigator:      NewTreeNavigator(&tree),
@@ -157,9 +256,17 @@ func NewXTreeGoldApp() (*XTreeGoldApp, error) {
 		connectionStep: StepSelectConnection,
 		width:          80,
 		height:         24,
-		queryEditor:    NewQueryEditor(),
-		dataViewer:     NewDataViewer(),
+		queryEditor:    tab.editor,
+		dataViewer:     tab.results,
+		tabs:           []*QueryTab{tab},
+		nextTabID:      1,
+		tabNameInput:   NewTextInput(),
 		dataEditor:     NewTextInput(),
+		exportInput:    NewTextInput(),
+		filterInput:    NewTextInput(),
+		history:        NewQueryHistory(defaultHistoryPath()),
+		historyPos:     -1,
+		snippets:       NewSnippetLibrary(defaultSnippetsDir(), projectSnippetsPath()),
 		styles: AppStyles{
 			Header:  lipgloss.NewStyle().Background(lipgloss.Color("#1a1a1a")).Foreground(lipgloss.Color("#FFD700")).Bold(true).Padding(0, 1),
 			Body:    lipgloss.NewStyle().Background(lipgloss.Color("#000000")).Foreground(lipgloss.Color("#FFFFFF")),
@@ -231,27 +338,13 @@ func (app *XTreeGoldApp) beginInsertRow() {
 	app.dataEditor.SetPlaceholder("coluna=valor, outra=valor2")
 }
 
-func (app *XTreeGoldApp) requestDeleteRow() tea.Cmd {
+func (app *XTreeGoldApp) stageDeleteRow() {
 	if !app.paneModel.HasDataContext() {
-		return nil
+		return
 	}
 	rowIdx := app.paneModel.GetSelectedDataRowIndex()
-	rowID := app.paneModel.GetSelectedRowID()
-	if rowID == "" {
-		return nil
-	}
-	db, schema, table := app.paneModel.GetDataContext()
-	colIdx := app.paneModel.GetSelectedDataColIndex()
-	targetRow := rowIdx - 1
-	return func() tea.Msg {
-		return DeleteRowMsg{
-			database: db,
-			schema:   schema,
-			table:    table,
-			rowID:    rowID,
-			rowIndex: targetRow,
-			colIndex: colIdx,
-		}
+	if !app.paneModel.ToggleRowDelete(rowIdx) {
+		app.setStatus("⚠ Não foi possível identificar a linha para exclusão")
 	}
 }
 
@@ -286,30 +379,14 @@ func (app *XTreeGoldApp) commitUpdateCell() (tea.Model, tea.Cmd) {
 		return app, nil
 	}
 
-	rowID := app.paneModel.GetRowID(rowIdx)
-	if rowID == "" {
-		app.cancelDataEdit()
-		return app, nil
-	}
-
 	currentValue := app.getCurrentCellValue(rowIdx, colName)
 	converted := app.convertInputValue(app.dataEditor.Value(), currentValue)
-	db, schema, table := app.paneModel.GetDataContext()
-	colIdx := app.paneModel.GetColumnIndexByName(colName)
 	app.cancelDataEdit()
 
-	return app, func() tea.Msg {
-		return UpdateCellMsg{
-			database: db,
-			schema:   schema,
-			table:    table,
-			rowID:    rowID,
-			column:   colName,
-			value:    converted,
-			rowIndex: rowIdx,
-			colIndex: colIdx,
-		}
+	if !app.paneModel.StageCellUpdate(rowIdx, colName, converted) {
+		app.setStatus("⚠ Linha não editável: marcada para exclusão ou sem identificador")
 	}
+	return app, nil
 }
 
 func (app *XTreeGoldApp) commitInsertRow() (tea.Model, tea.Cmd) {
@@ -333,22 +410,173 @@ func (app *XTreeGoldApp) commitInsertRow() (tea.Model, tea.Cmd) {
 		values[col] = app.convertInputValue(val, nil)
 	}
 
+	app.cancelDataEdit()
+
+	rowIdx := app.paneModel.StageInsertRow(values)
+	app.paneModel.SetDataSelection(rowIdx, 0)
+	return app, nil
+}
+
+func (app *XTreeGoldApp) applyPendingChanges() tea.Cmd {
+	if !app.paneModel.HasDataContext() || !app.paneModel.HasPendingChanges() {
+		return nil
+	}
+
 	db, schema, table := app.paneModel.GetDataContext()
+	changes := app.paneModel.GetPendingChanges()
 	rowIdx := app.paneModel.GetSelectedDataRowIndex()
-	app.cancelDataEdit()
+	colIdx := app.paneModel.GetSelectedDataColIndex()
+	return func() tea.Msg {
+		return ApplyChangesMsg{
+			database: db,
+			schema:   schema,
+			table:    table,
+			changes:  changes,
+			rowIndex: rowIdx,
+			colIndex: colIdx,
+		}
+	}
+}
+
+// fetchDataPage loads the next page the Data pane needs, if any, as the
+// selection approaches rows that have not been fetched. Pages are fetched
+// outside the task slot so scrolling stays responsive and a running apply,
+// import or export is never interrupted by a prefetch.
+func (app *XTreeGoldApp) fetchDataPage() tea.Cmd {
+	if app.dbLoader == nil || !app.paneModel.HasDataContext() {
+		return nil
+	}
+	req, ok := app.paneModel.NextDataPageRequest()
+	if !ok {
+		return nil
+	}
+
+	loader := app.dbLoader
+	db, schema, table := app.paneModel.GetDataContext()
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), dataPageTimeout)
+		defer cancel()
+		results, err := loader.GetTableData(ctx, db, schema, table, req.options)
+		return DataPageLoadedMsg{request: req, results: results, err: err}
+	}
+}
+
+func (app *XTreeGoldApp) discardPendingChanges() tea.Cmd {
+	if !app.paneModel.HasDataContext() || !app.paneModel.HasPendingChanges() {
+		return nil
+	}
 
-	return app, func() tea.Msg {
-		return InsertRowMsg{
+	count := app.paneModel.PendingChangeCount()
+	app.paneModel.ClearPendingChanges()
+	app.showChangePreview = false
+	app.setStatus(fmt.Sprintf("%d alteração(ões) descartada(s)", count))
+
+	return app.reloadData(app.paneModel.GetDataView(), app.paneModel.GetSelectedDataRowIndex())
+}
+
+// reloadData reads the Data pane's table again with the given sort and
+// filter, keeping the selected column.
+func (app *XTreeGoldApp) reloadData(view TableView, rowIdx int) tea.Cmd {
+	db, schema, table := app.paneModel.GetDataContext()
+	colIdx := app.paneModel.GetSelectedDataColIndex()
+	return func() tea.Msg {
+		return LoadTableDataMsg{
 			database: db,
 			schema:   schema,
 			table:    table,
-			values:   values,
 			rowIndex: rowIdx,
-			colIndex: 0,
+			colIndex: colIdx,
+			view:     view,
 		}
 	}
 }
 
+func (app *XTreeGoldApp) cycleDataSort() tea.Cmd {
+	column := app.paneModel.GetSelectedDataColumnName()
+	if !app.paneModel.HasDataContext() || column == "" {
+		return nil
+	}
+	return app.reloadData(app.paneModel.GetDataView().CycleSort(column), 0)
+}
+
+func (app *XTreeGoldApp) clearDataSort() tea.Cmd {
+	view := app.paneModel.GetDataView()
+	if !app.paneModel.HasDataContext() || len(view.OrderBy) == 0 {
+		return nil
+	}
+	view.OrderBy = nil
+	return app.reloadData(view, 0)
+}
+
+// filterBySelectedCell keeps only rows whose selected column equals the
+// selected cell.
+func (app *XTreeGoldApp) filterBySelectedCell() tea.Cmd {
+	rowIdx, column, value := app.paneModel.GetSelectedDataCell()
+	if !app.paneModel.HasDataContext() || column == "" || !app.paneModel.IsDataRowLoaded(rowIdx) {
+		return nil
+	}
+	return app.reloadData(app.paneModel.GetDataView().WithFilter(column, value), 0)
+}
+
+func (app *XTreeGoldApp) clearDataFilters() tea.Cmd {
+	view := app.paneModel.GetDataView()
+	if !app.paneModel.HasDataContext() || (len(view.Filters) == 0 && view.Where == "") {
+		return nil
+	}
+	view.Filters = nil
+	view.Where = ""
+	return app.reloadData(view, 0)
+}
+
+func (app *XTreeGoldApp) beginFilter() {
+	if !app.paneModel.HasDataContext() {
+		return
+	}
+	app.filtering = true
+	app.filterInput.SetWidth(max(app.width-4, 30))
+	app.filterInput.SetValue(app.paneModel.GetDataView().Where)
+	app.filterInput.SetPlaceholder("status = 'ativo' AND total > 100")
+}
+
+func (app *XTreeGoldApp) handleFilterInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEscape:
+		app.cancelFilter()
+		return app, nil
+	case tea.KeyEnter:
+		view := app.paneModel.GetDataView()
+		view.Where = strings.TrimSpace(app.filterInput.Value())
+		app.cancelFilter()
+		return app, app.reloadData(view, 0)
+	}
+
+	app.filterInput.HandleKey(msg)
+	return app, nil
+}
+
+func (app *XTreeGoldApp) cancelFilter() {
+	app.filtering = false
+	app.filterInput.Reset()
+}
+
+func (app *XTreeGoldApp) pendingChangesPreview() []string {
+	if app.dbLoader == nil {
+		return nil
+	}
+
+	_, schema, table := app.paneModel.GetDataContext()
+	var lines []string
+	for _, change := range app.paneModel.GetPendingChanges() {
+		query, args, err := app.dbLoader.BuildChangeSQL(schema, table, change)
+		if err != nil {
+			lines = append(lines, fmt.Sprintf("-- %s: %v", change.Kind, err))
+			continue
+		}
+		lines = append(lines, formatSQLPreview(query, args))
+	}
+	return lines
+}
+
 func (app *XTreeGoldApp) cancelDataEdit() {
 	app.dataEditMode = DataEditNone
 	app.dataEditRow = -1
@@ -370,6 +598,10 @@ func (app *XTreeGoldApp) dataEditPrompt() string {
 }
 
 func (app *XTreeGoldApp) convertInputValue(input string, current interface{}) interface{} {
+	return coerceInputValue(input)
+}
+
+func coerceInputValue(input string) interface{} {
 	trimmed := strings.TrimSpace(input)
 	if trimmed == "" || strings.EqualFold(trimmed, "null") {
 		return nil
@@ -405,18 +637,128 @@ func (app *XTreeGoldApp) convertInputValue(input string, current interface{}) in
 }
 
 func (app *XTreeGoldApp) getCurrentCellValue(rowIdx int, column string) interface{} {
-	data := app.paneModel.GetData()
-	if rowIdx < 0 || rowIdx >= len(data) {
-		return nil
-	}
 	if column == "" {
 		column = app.paneModel.GetSelectedDataColumnName()
 	}
 	if column == "" {
 		return nil
 	}
-	row := data[rowIdx]
-	return row[column]
+	return app.paneModel.GetDataValue(rowIdx, column)
+}
+
+func (app *XTreeGoldApp) beginExport(fromQuery bool) {
+	name := ""
+	if fromQuery {
+		if app.dataViewer.GetResults().ColumnCount() == 0 {
+			app.setStatus("⚠ Nenhum resultado de consulta para exportar")
+			return
+		}
+	} else {
+		if !app.paneModel.HasDataContext() {
+			return
+		}
+		_, _, name = app.paneModel.GetDataContext()
+	}
+
+	app.exporting = true
+	app.exportFromQuery = fromQuery
+	app.exportInput.SetWidth(max(app.width-4, 30))
+	app.exportInput.SetValue(defaultExportPath(name, app.exportFormat))
+	app.exportInput.SetPlaceholder("arquivo.csv")
+}
+
+func (app *XTreeGoldApp) handleExportInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEscape:
+		app.cancelExport()
+		return app, nil
+	case tea.KeyTab:
+		next := (int(app.exportFormat) + 1) % len(exportFormats)
+		app.exportFormat = exportFormats[next]
+		app.exportInput.SetValue(withExportExtension(app.exportInput.Value(), app.exportFormat))
+		return app, nil
+	case tea.KeyEnter:
+		path := strings.TrimSpace(app.exportInput.Value())
+		if path == "" {
+			return app, nil
+		}
+		format := app.exportFormat
+		if detected, ok := exportFormatFromPath(path); ok {
+			format = detected
+		}
+		// An existing file is replaced only after a second Enter on the
+		// same path.
+		overwrite := app.exportReplace == path
+		if _, err := os.Stat(path); err == nil && !overwrite {
+			app.exportReplace = path
+			return app, nil
+		}
+		fromQuery := app.exportFromQuery
+		app.cancelExport()
+		return app, func() tea.Msg {
+			return ExportMsg{path: path, format: format, fromQuery: fromQuery, overwrite: overwrite}
+		}
+	}
+
+	app.exportInput.HandleKey(msg)
+	if detected, ok := exportFormatFromPath(app.exportInput.Value()); ok {
+		app.exportFormat = detected
+	}
+	return app, nil
+}
+
+func (app *XTreeGoldApp) cancelExport() {
+	app.exporting = false
+	app.exportFromQuery = false
+	app.exportReplace = ""
+	app.exportInput.Reset()
+}
+
+func (app *XTreeGoldApp) exportPrompt() string {
+	source := "dados da tabela"
+	if app.exportFromQuery {
+		source = "resultado da consulta"
+	}
+	if app.exportReplace != "" && app.exportReplace == strings.TrimSpace(app.exportInput.Value()) {
+		return fmt.Sprintf("⚠ %s já existe: Enter substitui, Esc cancela", app.exportReplace)
+	}
+	return fmt.Sprintf("Exportar %s como %s (Tab: formato)", source, app.exportFormat)
+}
+
+// exportSource captures what to export now so the background task does not
+// read the pane model while the user keeps navigating.
+func (app *XTreeGoldApp) exportSource(fromQuery bool) func(ctx context.Context) ExportSource {
+	loader := app.dbLoader
+	if fromQuery {
+		results := app.dataViewer.GetResults()
+		return func(ctx context.Context) ExportSource {
+			return loader.QueryExportSource(results)
+		}
+	}
+	db, schema, table := app.paneModel.GetDataContext()
+	columns := app.paneModel.GetDataColumns()
+	view := app.paneModel.GetDataView()
+	return func(ctx context.Context) ExportSource {
+		return loader.TableExportSource(ctx, db, schema, table, columns, view)
+	}
+}
+
+func (app *XTreeGoldApp) setStatus(message string) {
+	app.statusMessage = message
+	app.statusTimestamp = time.Now()
+}
+
+func (app *XTreeGoldApp) currentStatus() string {
+	if status := app.taskStatus(); status != "" {
+		return status
+	}
+	if app.txPrompt {
+		return app.transactionPrompt()
+	}
+	if app.statusMessage == "" || time.Since(app.statusTimestamp) > statusMessageTTL {
+		return ""
+	}
+	return app.statusMessage
 }
 
 func max(a, b int) int {
@@ -426,6 +768,13 @@ func max(a, b int) int {
 	return b
 }
 
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
 func (app *XTreeGoldApp) Init() tea.Cmd {
 	app.connectionDialog = NewConnectionDialog(app.connectionMgr)
 	app.addConnectionForm = NewAddConnectionForm()
@@ -442,17 +791,32 @@ func (app *XTreeGoldApp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		return app, nil
 	case tea.KeyMsg:
+		if app.task != nil {
+			if msg.Type == tea.KeyEscape || msg.Type == tea.KeyCtrlC {
+				app.cancelTask()
+			}
+			return app, nil
+		}
+		if app.txPrompt {
+			return app.handleTransactionPrompt(msg)
+		}
 		if app.focusMode == FocusConnectionDialog {
 			return app.handleConnectionDialog(msg)
 		} else if app.focusMode == FocusAddConnectionForm {
 			return app.handleAddConnectionForm(msg)
 		} else if app.focusMode == FocusTree && app.paneNavigator != nil {
+			if msg.Type == tea.KeyCtrlX && app.transaction != nil {
+				app.confirmTransaction(tea.Quit)
+				return app, nil
+			}
 			_, cmd := app.paneNavigator.HandleKeyMsg(msg)
 			return app, cmd
 		} else if app.focusMode == FocusQuery {
 			return app.handleQueryInput(msg)
 		} else if app.focusMode == FocusData {
 			return app.handleDataView(msg)
+		} else if app.focusMode == FocusImport {
+			return app.handleImportWizard(msg)
 		}
 
 		return app, nil
@@ -473,95 +837,300 @@ func (app *XTreeGoldApp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		app.initialized = true
 		app.connectionStep = StepConnected
+		database := ""
+		if app.currentConnection != nil {
+			database = app.currentConnection.Database
+		}
+		app.setTabsCatalog(NewSchemaCatalog(msg.tree, database, app.dbLoader))
+		return app, nil
+	case EditorFinishedMsg:
+		if msg.err != nil {
+			app.setStatus(fmt.Sprintf("✖ Editor externo: %v", msg.err))
+			return app, nil
+		}
+		query, err := readEditedQuery(msg.path)
+		if err != nil {
+			app.setStatus(fmt.Sprintf("✖ %v", err))
+			return app, nil
+		}
+		app.queryEditor.SetValue(query)
+		app.queryEditor.CursorToEnd()
+		app.historyPos = -1
+		if msg.run && strings.TrimSpace(query) != "" {
+			return app, func() tea.Msg {
+				return ExecuteQueryMsg{query: query}
+			}
+		}
+		app.setStatus("✔ Consulta atualizada pelo editor externo")
+		return app, nil
+	case CatalogLoadedMsg:
+		return app, app.queryEditor.AttachCatalog(msg)
+	case ExplainResultMsg:
+		if msg.err != nil {
+			app.setStatus(fmt.Sprintf("✖ EXPLAIN: %v", msg.err))
+			return app, nil
+		}
+		app.planViewer = NewPlanViewer(msg.plan)
+		return app, nil
+	case ClipboardCopiedMsg:
+		if msg.err != nil {
+			app.setStatus(fmt.Sprintf("✖ Falha ao copiar %s: %v", msg.what, msg.err))
+			return app, nil
+		}
+		app.setStatus(fmt.Sprintf("📋 %s copiada (%d caractere(s))", msg.what, msg.chars))
 		return app, nil
 	case SearchResultMsg:
 		if msg.node != nil {
 			app.navigator.selectNode(msg.node)
 		}
 		return app, nil
+	case RunTaskMsg:
+		return app, app.startTask(msg.label, msg.run)
+	case taskDoneMsg:
+		if result := app.finishTask(msg); result != nil {
+			return app.Update(result)
+		}
+		return app, nil
+	case taskTickMsg:
+		if app.task != nil && app.task.id == msg.id {
+			return app, taskTick(msg.id)
+		}
+		return app, nil
 	case ExecuteQueryMsg:
+		if !app.tabConnected() {
+			return app, nil
+		}
+		if msg.params == nil && app.promptParams(msg.query) {
+			return app, nil
+		}
 		if app.dbLoader != nil {
-			results, err := app.dbLoader.ExecuteQuery(msg.query)
-			if err != nil {
-				app.tree.error = err
-				return app, nil
-			}
-			app.dataViewer.SetResults(results)
-			app.focusMode = FocusData
+			loader, query, tx, params := app.dbLoader, msg.query, app.transaction, msg.params
+			dialect := app.currentConnection.Type
+			return app, app.startTask("Executando consulta", func(ctx context.Context) tea.Msg {
+				started := time.Now()
+				statements, skipped := runScript(ctx, loader, tx, dialect, query, params)
+				return QueryResultMsg{query: query, started: started, duration: time.Since(started), statements: statements, skipped: skipped, params: params}
+			})
+		}
+		return app, nil
+	case ParamsEnteredMsg:
+		if app.currentConnection == nil {
+			return app, nil
 		}
+		executed := ExecuteQueryMsg{query: msg.query, params: app.queryParams(msg.inputs)}
+		return app, func() tea.Msg { return executed }
+	case QueryResultMsg:
+		app.recordHistory(msg)
+		app.dataViewer.SetStatements(msg.statements, msg.skipped)
+		app.resultsFocused = false
 		return app, nil
 	case LoadTableDataMsg:
-		if app.dbLoader != nil {
-			results, err := app.dbLoader.GetTableData(msg.database, msg.schema, msg.table, 100, 0)
-			if err != nil {
-				app.tree.error = err
-				return app, nil
-			}
-			app.paneModel.SetData(results)
-			app.paneModel.SetDataContext(msg.database, msg.schema, msg.table)
-			app.paneModel.SetFocus(PaneData)
-			app.paneModel.SetDataSelection(msg.rowIndex, msg.colIndex)
+		if app.paneModel.HasPendingChanges() {
 			app.focusMode = FocusData
+			app.paneModel.SetFocus(PaneData)
+			app.setStatus("⚠ Há alterações pendentes: Ctrl+S aplica, Ctrl+R descarta")
+			return app, nil
 		}
-		return app, nil
-	case UpdateCellMsg:
 		if app.dbLoader != nil {
-			if err := app.dbLoader.UpdateCell(msg.database, msg.schema, msg.table, msg.column, msg.rowID, msg.value); err != nil {
-				app.tree.error = err
-				return app, nil
-			}
-			return app, func() tea.Msg {
-				return LoadTableDataMsg{
+			loader := app.dbLoader
+			return app, app.startTask("Carregando "+msg.table, func(ctx context.Context) tea.Msg {
+				loaded := TableDataLoadedMsg{
 					database: msg.database,
 					schema:   msg.schema,
 					table:    msg.table,
 					rowIndex: msg.rowIndex,
 					colIndex: msg.colIndex,
+					view:     msg.view,
 				}
-			}
+				loaded.keyCols, loaded.err = loader.GetRowKeyColumns(ctx, msg.database, msg.schema, msg.table)
+				if loaded.err != nil {
+					return loaded
+				}
+				// Fetch enough pages to reach the row that should be selected.
+				limit := dataPageSize * (msg.rowIndex/dataPageSize + 1)
+				loaded.results, loaded.err = loader.GetTableData(ctx, msg.database, msg.schema, msg.table, TableQueryOptions{TableView: msg.view, Limit: limit})
+				if loaded.err != nil {
+					return loaded
+				}
+				loaded.complete = loaded.results.Len() < limit
+				loaded.total = -1
+				if count, err := loader.GetTableRowCount(ctx, msg.database, msg.schema, msg.table, msg.view); err == nil {
+					loaded.total = count
+				}
+				return loaded
+			})
+		}
+		return app, nil
+	case TableDataLoadedMsg:
+		if msg.err != nil && !msg.view.IsEmpty() {
+			// Keep the rows on screen so a mistyped filter can be fixed.
+			app.setStatus(fmt.Sprintf("✖ Falha ao aplicar ordem/filtro: %v", msg.err))
+			return app, nil
+		}
+		if msg.err != nil {
+			app.tree.error = msg.err
+			return app, nil
 		}
+		app.paneModel.SetData(msg.results)
+		app.paneModel.SetDataContext(msg.database, msg.schema, msg.table)
+		app.paneModel.SetDataKeyColumns(msg.keyCols)
+		app.paneModel.SetDataPaging(msg.keyCols, msg.view, msg.complete, msg.total)
+		app.paneModel.SetFocus(PaneData)
+		app.paneModel.SetDataSelection(msg.rowIndex, msg.colIndex)
+		app.focusMode = FocusData
 		return app, nil
-	case InsertRowMsg:
+	case DataPageLoadedMsg:
+		if msg.err != nil {
+			app.paneModel.FailDataPage(msg.request)
+			app.setStatus(fmt.Sprintf("✖ Falha ao carregar linhas: %v", msg.err))
+			return app, nil
+		}
+		if !app.paneModel.ApplyDataPage(msg.request, msg.results) {
+			return app, nil
+		}
+		return app, app.fetchDataPage()
+	case ApplyChangesMsg:
 		if app.dbLoader != nil {
-			if err := app.dbLoader.InsertRow(msg.database, msg.schema, msg.table, msg.values); err != nil {
-				app.tree.error = err
-				return app, nil
-			}
-			return app, func() tea.Msg {
-				return LoadTableDataMsg{
+			loader := app.dbLoader
+			return app, app.startTask("Aplicando alterações", func(ctx context.Context) tea.Msg {
+				return ChangesAppliedMsg{
 					database: msg.database,
 					schema:   msg.schema,
 					table:    msg.table,
+					count:    len(msg.changes),
 					rowIndex: msg.rowIndex,
 					colIndex: msg.colIndex,
+					err:      loader.ApplyChanges(ctx, msg.database, msg.schema, msg.table, msg.changes),
 				}
-			}
+			})
 		}
 		return app, nil
-	case DeleteRowMsg:
-		if app.dbLoader != nil {
-			if err := app.dbLoader.DeleteRow(msg.database, msg.schema, msg.table, msg.rowID); err != nil {
-				app.tree.error = err
-				return app, nil
-			}
-			targetRow := msg.rowIndex
-			if targetRow < 0 {
-				targetRow = 0
+	case ChangesAppliedMsg:
+		if msg.err != nil {
+			app.setStatus(fmt.Sprintf("✖ Nenhuma alteração aplicada (rollback): %v", msg.err))
+			return app, nil
+		}
+		app.paneModel.ClearPendingChanges()
+		app.showChangePreview = false
+		app.setStatus(fmt.Sprintf("✔ %d alteração(ões) aplicada(s)", msg.count))
+		view := app.paneModel.GetDataView()
+		return app, func() tea.Msg {
+			return LoadTableDataMsg{
+				database: msg.database,
+				schema:   msg.schema,
+				table:    msg.table,
+				rowIndex: msg.rowIndex,
+				colIndex: msg.colIndex,
+				view:     view,
 			}
+		}
+	case ExportMsg:
+		if app.dbLoader != nil {
+			source := app.exportSource(msg.fromQuery)
+			return app, app.startTask("Exportando para "+msg.path, func(ctx context.Context) tea.Msg {
+				count, err := ExportToFile(msg.path, msg.format, source(ctx), msg.overwrite)
+				return ExportDoneMsg{path: msg.path, fromQuery: msg.fromQuery, count: count, err: err}
+			})
+		}
+		return app, nil
+	case ExportDoneMsg:
+		if msg.err != nil {
+			app.setStatus(fmt.Sprintf("✖ Falha ao exportar: %v", msg.err))
+			return app, nil
+		}
+		status := fmt.Sprintf("✔ %d linha(s) exportada(s) para %s", msg.count, msg.path)
+		if !msg.fromQuery && app.paneModel.HasPendingChanges() {
+			status += " (alterações pendentes não incluídas)"
+		}
+		app.setStatus(status)
+		return app, nil
+	case OpenImportMsg:
+		if msg.err != nil {
+			app.setStatus(fmt.Sprintf("✖ Importação indisponível: %v", msg.err))
+			return app, nil
+		}
+		msg.node.attachChildren(msg.columns)
+		app.importWizard = NewImportWizard(msg.database, msg.schema, msg.table, msg.columns)
+		app.focusMode = FocusImport
+		return app, nil
+	case RunImportMsg:
+		if app.dbLoader != nil && app.importWizard != nil {
+			loader := app.dbLoader
+			return app, app.startTask("Importando "+msg.table, func(ctx context.Context) tea.Msg {
+				result, err := loader.ImportRows(ctx, msg.database, msg.schema, msg.table, msg.rows)
+				return ImportDoneMsg{result: result, err: err}
+			})
+		}
+		return app, nil
+	case ImportDoneMsg:
+		if app.importWizard != nil {
+			app.importWizard.SetResult(msg.result, msg.err)
+		}
+		return app, nil
+	case PaneChildrenLoadedMsg:
+		if msg.err != nil {
+			app.setStatus(fmt.Sprintf("✖ Falha ao carregar %s: %v", msg.node.Name, msg.err))
+			return app, nil
+		}
+		msg.node.attachChildren(msg.children)
+		app.paneNavigator.showChildren(msg.node, msg.pane)
+		return app, nil
+	case TreeChildrenLoadedMsg:
+		if msg.err != nil {
+			app.setStatus(fmt.Sprintf("✖ Falha ao carregar %s: %v", msg.node.Name, msg.err))
+			return app, nil
+		}
+		msg.node.attachChildren(msg.children)
+		msg.node.Expand()
+		return app, nil
+	case SnippetPickMsg:
+		return app, app.insertSnippet(msg.snippet)
+	case SnippetFilledMsg:
+		app.queryEditor.InsertText(msg.text)
+		return app, nil
+	case SnippetSaveMsg:
+		app.saveSnippet(msg.path)
+		return app, nil
+	case HistoryPickMsg:
+		app.queryEditor.SetValue(msg.query)
+		app.queryEditor.CursorToEnd()
+		app.historyPos = -1
+		if msg.run {
+			query := msg.query
 			return app, func() tea.Msg {
-				return LoadTableDataMsg{
-					database: msg.database,
-					schema:   msg.schema,
-					table:    msg.table,
-					rowIndex: targetRow,
-					colIndex: msg.colIndex,
-				}
+				return ExecuteQueryMsg{query: query}
 			}
 		}
 		return app, nil
 	case FocusModeMsg:
+		if msg.focusMode == FocusConnectionDialog && app.transaction != nil {
+			app.confirmTransaction(func() tea.Msg { return msg })
+			return app, nil
+		}
 		app.focusMode = msg.focusMode
 		return app, nil
+	case TransactionStartedMsg:
+		if msg.err != nil {
+			app.setStatus(fmt.Sprintf("✖ %v", msg.err))
+			return app, nil
+		}
+		app.transaction = msg.tx
+		app.setStatus("Transação iniciada: as instruções só valem após o COMMIT")
+		return app, nil
+	case TransactionEndedMsg:
+		if msg.err != nil {
+			app.setStatus(fmt.Sprintf("✖ %v", msg.err))
+			if msg.commit {
+				return app, nil
+			}
+			return app, msg.after
+		}
+		if msg.commit {
+			app.setStatus(fmt.Sprintf("✔ Transação confirmada (%d instrução(ões))", msg.statements))
+		} else {
+			app.setStatus(fmt.Sprintf("↩ Transação desfeita (%d instrução(ões))", msg.statements))
+		}
+		return app, msg.after
 	default:
 		return app, nil
 	}
@@ -600,10 +1169,12 @@ func (app *XTreeGoldApp) handleConnectionDialog(msg tea.KeyMsg) (tea.Model, tea.
 			app.navigator.SetDatabaseLoader(loader)
 			app.paneNavigator.SetDatabaseLoader(loader)
 			app.dbLoader = loader
+			app.loadHistory()
+			app.connectTabs(conn)
 			app.focusMode = FocusTree
 			app.connectionStep = StepConnected
 			app.initialized = false
-			return app, loader.LoadTreeAsync(app.currentServer)
+			return app, app.loadTree(loader)
 		} else {
 			return app, tea.Quit
 		}
@@ -612,6 +1183,21 @@ func (app *XTreeGoldApp) handleConnectionDialog(msg tea.KeyMsg) (tea.Model, tea.
 	return app, cmd
 }
 
+func (app *XTreeGoldApp) loadHistory() {
+	app.historySearch = nil
+	app.historyPos = -1
+	if err := app.history.Load(app.currentServer); err != nil {
+		app.setStatus(fmt.Sprintf("⚠ Histórico indisponível: %v", err))
+	}
+}
+
+func (app *XTreeGoldApp) loadTree(loader DatabaseLoader) tea.Cmd {
+	server := app.currentServer
+	return app.startTask("Carregando estrutura", func(ctx context.Context) tea.Msg {
+		return loader.LoadTreeAsync(ctx, server)()
+	})
+}
+
 func (app *XTreeGoldApp) handleAddConnectionForm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	form := app.addConnectionForm
 	model, cmd := form.Update(msg)
@@ -652,11 +1238,13 @@ func (app *XTreeGoldApp) handleAddConnectionForm(msg tea.KeyMsg) (tea.Model, tea
 			app.navigator.SetDatabaseLoader(loader)
 			app.paneNavigator.SetDatabaseLoader(loader)
 			app.dbLoader = loader
+			app.loadHistory()
+			app.connectTabs(conn)
 			app.focusMode = FocusTree
 			app.connectionStep = StepConnected
 			app.addConnectionForm = NewAddConnectionForm()
 			app.initialized = false
-			return app, loader.LoadTreeAsync(app.currentServer)
+			return app, app.loadTree(loader)
 		}
 	}
 
@@ -664,7 +1252,108 @@ func (app *XTreeGoldApp) handleAddConnectionForm(msg tea.KeyMsg) (tea.Model, tea
 }
 
 func (app *XTreeGoldApp) handleQueryInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if app.exporting {
+		return app.handleExportInput(msg)
+	}
+	if app.paramPrompt != nil {
+		model, cmd := app.paramPrompt.Update(msg)
+		app.paramPrompt = model.(*ParamPrompt)
+		if app.paramPrompt.IsClosed() {
+			app.paramPrompt = nil
+		}
+		return app, cmd
+	}
+	if app.snippetBrowser != nil {
+		model, cmd := app.snippetBrowser.Update(msg)
+		app.snippetBrowser = model.(*SnippetBrowser)
+		if app.snippetBrowser.IsClosed() {
+			app.snippetBrowser = nil
+		}
+		return app, cmd
+	}
+	if app.renamingTab {
+		return app.handleTabRename(msg)
+	}
+	if app.handleTabKey(msg) {
+		return app, nil
+	}
+	if app.planViewer != nil {
+		return app.handlePlanInput(msg)
+	}
+	if app.resultsFocused {
+		return app.handleResultsInput(msg)
+	}
+	if app.historySearch == nil && msg.Paste {
+		model, cmd := app.queryEditor.Update(msg)
+		app.queryEditor = model.(*QueryEditor)
+		return app, cmd
+	}
+	if app.queryEditor.CompletionOpen() {
+		model, cmd := app.queryEditor.Update(msg)
+		app.queryEditor = model.(*QueryEditor)
+		return app, cmd
+	}
+	if app.historySearch != nil {
+		model, cmd := app.historySearch.Update(msg)
+		app.historySearch = model.(*HistorySearch)
+		if app.historySearch.IsClosed() {
+			app.historySearch = nil
+		}
+		return app, cmd
+	}
+
+	if msg.Type == tea.KeyUp && app.queryEditor.OnFirstLine() && app.recallHistory(1) {
+		return app, nil
+	}
+	if msg.Type == tea.KeyDown && app.queryEditor.OnLastLine() && app.recallHistory(-1) {
+		return app, nil
+	}
+
 	switch msg.Type {
+	case tea.KeyCtrlE:
+		app.beginExport(true)
+		return app, nil
+	case tea.KeyCtrlC:
+		return app, app.copyQuery()
+	case tea.KeyCtrlX:
+		return app, app.explainStatement(false)
+	case tea.KeyCtrlR:
+		app.historySearch = NewHistorySearch(app.history, max(app.width-8, 30))
+		return app, nil
+	case tea.KeyCtrlB:
+		app.openSnippets()
+		return app, nil
+	case tea.KeyCtrlS:
+		query := app.queryEditor.CurrentStatement()
+		if query == "" {
+			app.setStatus("⚠ Nenhuma instrução sob o cursor")
+			return app, nil
+		}
+		return app, func() tea.Msg {
+			return ExecuteQueryMsg{query: query}
+		}
+	case tea.KeyCtrlF:
+		if !app.queryEditor.FormatStatement() {
+			app.setStatus("⚠ Nenhuma instrução para formatar")
+		}
+		return app, nil
+	case tea.KeyCtrlO, tea.KeyCtrlG:
+		return app, openInEditor(app.queryEditor.GetValue(), msg.Type == tea.KeyCtrlG)
+	case tea.KeyCtrlT:
+		if app.transaction != nil {
+			app.confirmTransaction(nil)
+			return app, nil
+		}
+		return app, app.beginTransaction()
+	case tea.KeyCtrlN:
+		app.dataViewer.NextStatement()
+		return app, nil
+	case tea.KeyCtrlP:
+		app.dataViewer.PrevStatement()
+		return app, nil
+	case tea.KeyCtrlDown:
+		app.resultsFocused = app.dataViewer.StatementCount() > 0
+		return app, nil
 	case tea.KeyEscape:
 		app.focusMode = FocusTree
 		return app, nil
@@ -683,6 +1372,63 @@ func (app *XTreeGoldApp) handleQueryInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	}
 }
 
+// recallHistory replaces the editor text with an older (delta > 0) or newer
+// query from the history; stepping past the newest restores the draft.
+func (app *XTreeGoldApp) recallHistory(delta int) bool {
+	next := app.historyPos + delta
+	if next < -1 || next >= app.history.Len() {
+		return false
+	}
+	if app.historyPos == -1 {
+		app.historyDraft = app.queryEditor.GetValue()
+	}
+	app.historyPos = next
+
+	query := app.historyDraft
+	if entry, ok := app.history.Entry(next); ok {
+		query = entry.Query
+	}
+	app.queryEditor.SetValue(query)
+	app.queryEditor.CursorToEnd()
+	return true
+}
+
+func (app *XTreeGoldApp) recordHistory(msg QueryResultMsg) {
+	entry := HistoryEntry{
+		Query:      msg.query,
+		ExecutedAt: msg.started,
+		Duration:   msg.duration,
+	}
+	if msg.params != nil {
+		entry.Params = msg.params.inputs
+	}
+	for _, statement := range msg.statements {
+		entry.Rows += int(statement.RowCount())
+		if statement.Err != nil {
+			entry.Error = statement.Err.Error()
+		}
+	}
+	app.historyPos = -1
+	if err := app.history.Add(entry); err != nil {
+		app.setStatus(fmt.Sprintf("⚠ Histórico não salvo: %v", err))
+	}
+}
+
+func (app *XTreeGoldApp) handleImportWizard(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if app.importWizard == nil {
+		app.focusMode = FocusTree
+		return app, nil
+	}
+
+	model, cmd := app.importWizard.Update(msg)
+	app.importWizard = model.(*ImportWizard)
+	if app.importWizard.IsClosed() {
+		app.importWizard = nil
+		app.focusMode = FocusTree
+	}
+	return app, cmd
+}
+
 func (app *XTreeGoldApp) OpenQueryEditor() tea.Cmd {
 	app.focusMode = FocusQuery
 	return nil
@@ -692,6 +1438,16 @@ func (app *XTreeGoldApp) handleDataView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	if app.dataEditMode != DataEditNone {
 		return app.handleDataEditInput(msg)
 	}
+	if app.exporting {
+		return app.handleExportInput(msg)
+	}
+	if app.filtering {
+		return app.handleFilterInput(msg)
+	}
+
+	if app.paneModel.HandleDataKey(msg) {
+		return app, app.fetchDataPage()
+	}
 
 	switch msg.Type {
 	case tea.KeyEscape:
@@ -700,38 +1456,33 @@ func (app *XTreeGoldApp) handleDataView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	case tea.KeyCtrlQ:
 		app.focusMode = FocusQuery
 		return app, nil
-	case tea.KeyUp:
-		app.paneModel.MoveDataSelection(-1, 0)
-		return app, nil
-	case tea.KeyDown:
-		app.paneModel.MoveDataSelection(1, 0)
-		return app, nil
-	case tea.KeyLeft:
-		app.paneModel.MoveDataSelection(0, -1)
-		return app, nil
-	case tea.KeyRight:
-		app.paneModel.MoveDataSelection(0, 1)
-		return app, nil
-	case tea.KeyPgUp:
-		app.paneModel.MoveDataSelection(-app.paneModel.GetDataViewportRows(), 0)
-		return app, nil
-	case tea.KeyPgDown:
-		app.paneModel.MoveDataSelection(app.paneModel.GetDataViewportRows(), 0)
-		return app, nil
-	case tea.KeyHome:
-		app.paneModel.SetDataSelection(app.paneModel.GetSelectedDataRowIndex(), 0)
-		return app, nil
-	case tea.KeyEnd:
-		app.paneModel.SetDataSelection(
-			app.paneModel.GetSelectedDataRowIndex(),
-			app.paneModel.GetDataColCount()-1,
-		)
-		return app, nil
 	case tea.KeyEnter:
 		app.beginCellEdit()
 		return app, nil
 	}
 
+	switch msg.String() {
+	case "s":
+		return app, app.cycleDataSort()
+	case "S":
+		return app, app.clearDataSort()
+	case "f":
+		app.beginFilter()
+		return app, nil
+	case "=":
+		return app, app.filterBySelectedCell()
+	case "F":
+		return app, app.clearDataFilters()
+	case "c":
+		return app, app.copyCell(app.paneModel)
+	case "C":
+		return app, app.copyRow(app.paneModel, RowCopyTSV)
+	case "J":
+		return app, app.copyRow(app.paneModel, RowCopyJSON)
+	case "I":
+		return app, app.copyRow(app.paneModel, RowCopyInsert)
+	}
+
 	switch strings.ToLower(msg.String()) {
 	case "ctrl+q":
 		app.focusMode = FocusQuery
@@ -740,7 +1491,18 @@ func (app *XTreeGoldApp) handleDataView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		app.beginInsertRow()
 		return app, nil
 	case "ctrl+d":
-		return app, app.requestDeleteRow()
+		app.stageDeleteRow()
+		return app, nil
+	case "ctrl+s":
+		return app, app.applyPendingChanges()
+	case "ctrl+r":
+		return app, app.discardPendingChanges()
+	case "ctrl+p":
+		app.showChangePreview = !app.showChangePreview && app.paneModel.HasPendingChanges()
+		return app, nil
+	case "ctrl+e":
+		app.beginExport(!app.paneModel.HasDataContext())
+		return app, nil
 	}
 
 	return app, nil
@@ -791,6 +1553,8 @@ func (app *XTreeGoldApp) renderMainView() string {
 		return app.renderQueryView(width, height, bodyHeight, header)
 	case FocusData:
 		return app.renderDataView(width, height, bodyHeight, header)
+	case FocusImport:
+		return app.renderImportView(header)
 	default:
 		return ""
 	}
@@ -800,36 +1564,103 @@ func (app *XTreeGoldApp) renderTreeView(width, height, bodyHeight int, header st
 	header = app.paneRenderer.renderHeader()
 	footer := app.paneRenderer.renderStatus(app.paneModel)
 
-	content := app.styles.Header.Render(header) + "\n"
+	content := app.styles.Header.Render(header) + " " + app.transactionBadge() + "\n"
 
 	panesView := app.paneRenderer.RenderPanes(app.paneModel, width, bodyHeight)
 	content += panesView + "\n"
+	if status := app.currentStatus(); status != "" {
+		content += app.styles.Footer.Render(status) + "\n"
+	}
 
 	content += app.styles.Footer.Render(footer)
 	return content
 }
 
 func (app *XTreeGoldApp) renderQueryView(width, height, bodyHeight int, header string) string {
-	footer := "SQL Editor | ESC: Return to Tree | Enter: Execute Query | Ctrl+J: Newline"
-	content := app.styles.Header.Render(header) + "\n"
+	footer := "SQL Editor | ESC: Return to Tree | Enter: Execute Query | Ctrl+S: Execute Statement/Selection | Ctrl+J: Newline | Ctrl+Z/Y: Undo/Redo | Shift+Arrows: Select | Ctrl+←/→: Word | Ctrl+K/D: Delete/Duplicate Line | ↑/↓: History | Ctrl+R: Search History | Ctrl+B: Snippets | Ctrl+F: Format | Ctrl+N/P: Next/Prev Result | Ctrl+↓: Results | Ctrl+T: Transaction | Ctrl+L: Dialect | Ctrl+O/G: $EDITOR (edit/run) | Ctrl+C: Copy Query/Selection | Ctrl+X: Explain | Ctrl+E: Export Results | Alt+T/W/R: New/Close/Rename Tab | Ctrl+PgUp/PgDn, Alt+1..9: Switch Tab | Alt+,/.: Move Tab"
+	if app.resultsFocused {
+		footer = "Results | Arrows: Move | PgUp/PgDn: Page | Home/End: First/Last Column | Ctrl+Home/End: First/Last Row | c: Copy Cell | C/J/I: Copy Row (TSV/JSON/INSERT) | Ctrl+N/P: Next/Prev Result | Ctrl+E: Export | ESC/Ctrl+↑: Editor"
+	}
+
+	// With results on screen the editor keeps a third of the body and the
+	// grid below it gets the rest.
+	showResults := app.planViewer == nil && app.dataViewer.StatementCount() > 0
+	if showResults {
+		app.queryEditor.SetHeight(bodyHeight / 3)
+	} else {
+		app.queryEditor.SetHeight(bodyHeight - 6)
+	}
+
+	content := app.styles.Header.Render(header) + " " + app.transactionBadge() + "\n"
+	content += app.renderTabBar() + "\n"
+	if app.renamingTab {
+		content += app.tabNameInput.View("Nome da aba") + "\n"
+	}
 	queryView := app.queryEditor.View()
 	content += queryView + "\n"
-	content += app.styles.Footer.Render(footer)
-	return content
+	if app.historySearch != nil {
+		content += app.historySearch.View() + "\n"
+	}
+	if app.paramPrompt != nil {
+		content += app.paramPrompt.View() + "\n"
+	}
+	if app.snippetBrowser != nil {
+		content += app.snippetBrowser.View() + "\n"
+	}
+
+	var below string
+	if app.exporting {
+		below += app.exportInput.View(app.exportPrompt()) + "\n"
+	}
+	if status := app.currentStatus(); status != "" {
+		below += app.styles.Footer.Render(status) + "\n"
+	}
+	below += app.styles.Footer.Render(footer)
+
+	if app.planViewer != nil {
+		content += app.planViewer.View(width) + "\n"
+	} else if showResults {
+		// The footer is one long line that the terminal wraps.
+		footerLines := (lipgloss.Width(footer) + width - 1) / width
+		remaining := height - lipgloss.Height(content) - lipgloss.Height(below) - footerLines + 1
+		content += app.dataViewer.View(width, remaining, app.resultsFocused) + "\n"
+	}
+	return content + below
 }
 
 func (app *XTreeGoldApp) renderDataView(width, height, bodyHeight int, header string) string {
-	footer := "Data View | ESC: Return to Tree | Ctrl+Q: Query | Enter: Edit | Ctrl+N: Insert | Ctrl+D: Delete"
+	footer := "Data View | ESC: Return to Tree | Ctrl+Q: Query | Enter: Edit | Ctrl+N: Insert | Ctrl+D: Delete | Ctrl+P: Preview SQL | Ctrl+S: Apply | Ctrl+R: Discard | Ctrl+E: Export | s/S: Sort | f/=/F: Filter | c: Copy Cell | C/J/I: Copy Row (TSV/JSON/INSERT)"
 	content := app.styles.Header.Render(header) + "\n"
 	dataView := app.paneRenderer.renderDataPane(app.paneModel, "Data", width, bodyHeight, app.paneModel.GetFocus() == PaneData)
 	content += dataView + "\n"
 	if app.dataEditMode != DataEditNone && app.dataEditor != nil {
 		content += app.dataEditor.View(app.dataEditPrompt()) + "\n"
 	}
+	if app.exporting {
+		content += app.exportInput.View(app.exportPrompt()) + "\n"
+	}
+	if app.filtering {
+		content += app.filterInput.View("Filtro WHERE (vazio remove)") + "\n"
+	}
+	if app.showChangePreview && app.paneModel.HasPendingChanges() {
+		content += app.paneRenderer.renderChangePreview(app.pendingChangesPreview(), width) + "\n"
+	}
+	if status := app.currentStatus(); status != "" {
+		content += app.styles.Footer.Render(status) + "\n"
+	}
 	content += app.styles.Footer.Render(footer)
 	return content
 }
 
+func (app *XTreeGoldApp) renderImportView(header string) string {
+	content := app.styles.Header.Render(header) + "\n"
+	if app.importWizard != nil {
+		content += app.importWizard.View() + "\n"
+	}
+	content += app.styles.Footer.Render("Import | ESC: Back")
+	return content
+}
+
 func (app *XTreeGoldApp) renderError(err error) string {
 	errorMsg := fmt.Sprintf("❌ Error: %v", err)
 	instructions := "Press Escape to continue"
@@ -848,6 +1679,9 @@ func (app *XTreeGoldApp) renderError(err error) string {
 
 func (app *XTreeGoldApp) renderLoading() string {
 	loadingText := "🔄 Loading tree structure from database..."
+	if status := app.taskStatus(); status != "" {
+		loadingText += "\n\n" + status
+	}
 
 	return lipgloss.NewStyle().
 		Width(50).
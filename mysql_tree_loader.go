@@ -0,0 +1,542 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"sync"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+var mysqlSystemSchemas = map[string]bool{
+	"information_schema": true,
+	"mysql":              true,
+	"performance_schema": true,
+	"sys":                true,
+}
+
+var mysqlDialect = sqlDialect{
+	quoteIdent:    quoteMySQLIdentifier,
+	placeholder:   questionPlaceholder,
+	bytesLiteral:  hexBytesLiteral,
+	stringLiteral: quoteMySQLString,
+}
+
+type MySQLTreeLoader struct {
+	db           *sql.DB
+	connInfo     *ConnectionInfo
+	keyColumns   map[string][]string
+	tableColumns map[string][]string
+	// mu guards keyColumns and tableColumns, filled from background tasks.
+	mu sync.Mutex
+}
+
+func NewMySQLTreeLoader(db *sql.DB, connInfo *ConnectionInfo) *MySQLTreeLoader {
+	return &MySQLTreeLoader{
+		db:           db,
+		connInfo:     connInfo,
+		keyColumns:   make(map[string][]string),
+		tableColumns: make(map[string][]string),
+	}
+}
+
+func quoteMySQLIdentifier(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
+
+// MySQL treats backslash as an escape character inside string literals
+// unless NO_BACKSLASH_ESCAPES is set.
+func quoteMySQLString(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
+
+func (mtl *MySQLTreeLoader) LoadTree(ctx context.Context, serverName string) (*TreeNode, error) {
+	root := &TreeNode{
+		ID:       "root",
+		Name:     "MySQL Servers",
+		Type:     NodeServer,
+		Level:    -1,
+		Children: make([]*TreeNode, 0),
+	}
+
+	serverNode := &TreeNode{
+		ID:       serverName,
+		Name:     serverName,
+		Type:     NodeServer,
+		Path:     serverName,
+		Level:    0,
+		Children: make([]*TreeNode, 0),
+	}
+
+	databases, err := mtl.loadDatabases(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load databases: %w", err)
+	}
+
+	for _, db := range databases {
+		db.Parent = serverNode
+		serverNode.Children = append(serverNode.Children, db)
+	}
+
+	root.Children = append(root.Children, serverNode)
+	serverNode.Parent = root
+
+	return root, nil
+}
+
+func (mtl *MySQLTreeLoader) LoadTreeAsync(ctx context.Context, serverName string) tea.Cmd {
+	return func() tea.Msg {
+		tree, err := mtl.LoadTree(ctx, serverName)
+		if err != nil {
+			return ErrMsg{err}
+		}
+		return TreeLoadedMsg{tree: tree}
+	}
+}
+
+func (mtl *MySQLTreeLoader) loadDatabases(ctx context.Context) ([]*TreeNode, error) {
+	query := `
+		SELECT
+			s.schema_name,
+			COALESCE(SUM(t.data_length + t.index_length), 0) AS total_size,
+			COUNT(t.table_name) AS table_count
+		FROM information_schema.schemata s
+		LEFT JOIN information_schema.tables t ON t.table_schema = s.schema_name
+		GROUP BY s.schema_name
+		ORDER BY s.schema_name
+	`
+
+	rows, err := mtl.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("query failed: %w", err)
+	}
+	defer rows.Close()
+
+	var databases []*TreeNode
+	for rows.Next() {
+		var dbName string
+		var totalSize int64
+		var tableCount int
+
+		if err := rows.Scan(&dbName, &totalSize, &tableCount); err != nil {
+			return nil, fmt.Errorf("scan failed: %w", err)
+		}
+		if mysqlSystemSchemas[strings.ToLower(dbName)] {
+			continue
+		}
+
+		databases = append(databases, &TreeNode{
+			ID:    fmt.Sprintf("mysql_db_%s", dbName),
+			Name:  dbName,
+			Type:  NodeDatabase,
+			Path:  dbName,
+			Level: 1,
+			Metadata: NodeMetadata{
+				Size:        formatByteSize(totalSize),
+				Count:       tableCount,
+				ContextType: "mysql",
+			},
+			Children: make([]*TreeNode, 0),
+		})
+	}
+
+	return databases, nil
+}
+
+// MySQL has no schema level below the database, so each database exposes a
+// single schema with its own name, like SQLite exposes "main".
+func (mtl *MySQLTreeLoader) loadSchemas(databaseName string) []*TreeNode {
+	schema := &TreeNode{
+		ID:    fmt.Sprintf("mysql_schema_%s", databaseName),
+		Name:  databaseName,
+		Type:  NodeSchema,
+		Path:  fmt.Sprintf("%s.%s", databaseName, databaseName),
+		Level: 2,
+		Metadata: NodeMetadata{
+			ContextType: "mysql",
+		},
+		Children: make([]*TreeNode, 0),
+	}
+	return []*TreeNode{schema}
+}
+
+func (mtl *MySQLTreeLoader) loadTables(ctx context.Context, databaseName, schemaName string) ([]*TreeNode, error) {
+	query := `
+		SELECT
+			table_name,
+			COALESCE(data_length + index_length, 0) AS table_size,
+			COALESCE(table_rows, 0) AS row_count
+		FROM information_schema.tables
+		WHERE table_schema = ?
+			AND table_type = 'BASE TABLE'
+		ORDER BY table_name
+	`
+
+	rows, err := mtl.db.QueryContext(ctx, query, schemaName)
+	if err != nil {
+		return nil, fmt.Errorf("query failed: %w", err)
+	}
+	defer rows.Close()
+
+	var tables []*TreeNode
+	for rows.Next() {
+		var tableName string
+		var tableSize, rowCount int64
+
+		if err := rows.Scan(&tableName, &tableSize, &rowCount); err != nil {
+			return nil, fmt.Errorf("scan failed: %w", err)
+		}
+
+		tables = append(tables, &TreeNode{
+			ID:    fmt.Sprintf("mysql_table_%s_%s", databaseName, tableName),
+			Name:  tableName,
+			Type:  NodeTable,
+			Path:  fmt.Sprintf("%s.%s.%s", databaseName, schemaName, tableName),
+			Level: 3,
+			Metadata: NodeMetadata{
+				Size:        formatByteSize(tableSize),
+				RowCount:    rowCount,
+				ContextType: "mysql",
+			},
+			Children: make([]*TreeNode, 0),
+		})
+	}
+
+	return tables, nil
+}
+
+func (mtl *MySQLTreeLoader) loadColumns(ctx context.Context, databaseName, schemaName, tableName string) ([]*TreeNode, error) {
+	query := `
+		SELECT
+			column_name,
+			column_type,
+			is_nullable,
+			COALESCE(column_default, ''),
+			column_key
+		FROM information_schema.columns
+		WHERE table_schema = ? AND table_name = ?
+		ORDER BY ordinal_position
+	`
+
+	rows, err := mtl.db.QueryContext(ctx, query, schemaName, tableName)
+	if err != nil {
+		return nil, fmt.Errorf("query failed: %w", err)
+	}
+	defer rows.Close()
+
+	var columns []*TreeNode
+	for rows.Next() {
+		var columnName, dataType, isNullable, defaultValue, columnKey string
+
+		if err := rows.Scan(&columnName, &dataType, &isNullable, &defaultValue, &columnKey); err != nil {
+			return nil, fmt.Errorf("scan failed: %w", err)
+		}
+
+		columns = append(columns, &TreeNode{
+			ID:    fmt.Sprintf("mysql_col_%s_%s_%s", databaseName, tableName, columnName),
+			Name:  columnName,
+			Type:  NodeColumn,
+			Path:  fmt.Sprintf("%s.%s.%s.%s", databaseName, schemaName, tableName, columnName),
+			Level: 4,
+			Metadata: NodeMetadata{
+				DataType:     dataType,
+				IsNullable:   strings.EqualFold(isNullable, "YES"),
+				DefaultValue: defaultValue,
+				PrimaryKey:   columnKey == "PRI",
+			},
+			Children: make([]*TreeNode, 0),
+		})
+	}
+
+	return columns, nil
+}
+
+func (mtl *MySQLTreeLoader) LoadChildren(ctx context.Context, node *TreeNode) error {
+	if node == nil || len(node.Children) > 0 {
+		return nil
+	}
+
+	parts := strings.Split(node.Path, ".")
+
+	switch node.Type {
+	case NodeDatabase:
+		for _, schema := range mtl.loadSchemas(parts[0]) {
+			schema.Parent = node
+			node.Children = append(node.Children, schema)
+		}
+	case NodeSchema:
+		if len(parts) >= 2 {
+			tables, err := mtl.loadTables(ctx, parts[0], parts[1])
+			if err != nil {
+				return err
+			}
+			for _, table := range tables {
+				table.Parent = node
+				node.Children = append(node.Children, table)
+			}
+		}
+	case NodeTable:
+		if len(parts) >= 3 {
+			columns, err := mtl.loadColumns(ctx, parts[0], parts[1], parts[2])
+			if err != nil {
+				return err
+			}
+			for _, column := range columns {
+				column.Parent = node
+				node.Children = append(node.Children, column)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (mtl *MySQLTreeLoader) GetRowKeyColumns(ctx context.Context, database, schema, table string) ([]string, error) {
+	cacheKey := tableCacheKey(database, schema, table)
+	mtl.mu.Lock()
+	cols, ok := mtl.keyColumns[cacheKey]
+	mtl.mu.Unlock()
+	if ok {
+		return cols, nil
+	}
+
+	// Prefix and functional index parts can't identify a row on their own.
+	query := `
+		SELECT index_name, column_name, nullable, sub_part
+		FROM information_schema.statistics
+		WHERE table_schema = ? AND table_name = ? AND non_unique = 0
+		ORDER BY index_name = 'PRIMARY' DESC, index_name, seq_in_index
+	`
+
+	rows, err := mtl.db.QueryContext(ctx, query, schema, table)
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover row key: %w", err)
+	}
+	defer rows.Close()
+
+	var (
+		order   []string
+		columns = make(map[string][]string)
+		usable  = make(map[string]bool)
+	)
+	for rows.Next() {
+		var indexName, nullable string
+		var column sql.NullString
+		var subPart sql.NullInt64
+		if err := rows.Scan(&indexName, &column, &nullable, &subPart); err != nil {
+			return nil, fmt.Errorf("scan failed: %w", err)
+		}
+		if _, seen := usable[indexName]; !seen {
+			order = append(order, indexName)
+			usable[indexName] = true
+		}
+		if !column.Valid || subPart.Valid || nullable == "YES" {
+			usable[indexName] = false
+		}
+		columns[indexName] = append(columns[indexName], column.String)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to discover row key: %w", err)
+	}
+
+	var keyCols []string
+	for _, indexName := range order {
+		if !usable[indexName] {
+			continue
+		}
+		if indexName == "PRIMARY" {
+			keyCols = columns[indexName]
+			break
+		}
+		if keyCols == nil || len(columns[indexName]) < len(keyCols) {
+			keyCols = columns[indexName]
+		}
+	}
+
+	mtl.mu.Lock()
+	mtl.keyColumns[cacheKey] = keyCols
+	mtl.mu.Unlock()
+	return keyCols, nil
+}
+
+// columnNames lists a table's columns in their defined order. MySQL has no
+// row locator, so a table without a key is paged in the order of all of them.
+func (mtl *MySQLTreeLoader) columnNames(ctx context.Context, database, schema, table string) ([]string, error) {
+	cacheKey := tableCacheKey(database, schema, table)
+	mtl.mu.Lock()
+	cols, ok := mtl.tableColumns[cacheKey]
+	mtl.mu.Unlock()
+	if ok {
+		return cols, nil
+	}
+
+	query := `
+		SELECT column_name
+		FROM information_schema.columns
+		WHERE table_schema = ? AND table_name = ?
+		ORDER BY ordinal_position
+	`
+
+	rows, err := mtl.db.QueryContext(ctx, query, schema, table)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list columns: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var col string
+		if err := rows.Scan(&col); err != nil {
+			return nil, fmt.Errorf("scan failed: %w", err)
+		}
+		cols = append(cols, col)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list columns: %w", err)
+	}
+
+	mtl.mu.Lock()
+	mtl.tableColumns[cacheKey] = cols
+	mtl.mu.Unlock()
+	return cols, nil
+}
+
+func (mtl *MySQLTreeLoader) tableTarget(schema, table string) string {
+	return fmt.Sprintf("%s.%s", quoteMySQLIdentifier(schema), quoteMySQLIdentifier(table))
+}
+
+func (mtl *MySQLTreeLoader) GetTableData(ctx context.Context, database, schema, table string, opts TableQueryOptions) (*ResultSet, error) {
+	keyCols, err := mtl.GetRowKeyColumns(ctx, database, schema, table)
+	if err != nil {
+		return nil, err
+	}
+
+	var columns []string
+	if len(keyCols) == 0 {
+		if columns, err = mtl.columnNames(ctx, database, schema, table); err != nil {
+			return nil, err
+		}
+	}
+
+	where, orderBy, args, offset := mysqlDialect.tableQueryClauses(keyCols, columns, opts)
+	query := fmt.Sprintf(`
+		SELECT *
+		FROM %s
+		%s
+		%s
+		LIMIT %d OFFSET %d
+	`, mtl.tableTarget(schema, table), where, orderBy, opts.Limit, offset)
+
+	rows, err := mtl.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query failed: %w", err)
+	}
+	defer rows.Close()
+
+	return scanResultSet(rows, isMySQLBinaryType)
+}
+
+func (mtl *MySQLTreeLoader) GetTableRowCount(ctx context.Context, database, schema, table string, view TableView) (int64, error) {
+	conditions, args := mysqlDialect.filterConditions(view, 1)
+	query := fmt.Sprintf(`SELECT COUNT(*) FROM %s %s`, mtl.tableTarget(schema, table), whereClause(conditions))
+
+	var count int64
+	if err := mtl.db.QueryRowContext(ctx, query, args...).Scan(&count); err != nil {
+		return 0, fmt.Errorf("query failed: %w", err)
+	}
+
+	return count, nil
+}
+
+func (mtl *MySQLTreeLoader) BuildChangeSQL(schema, table string, change PendingChange) (string, []interface{}, error) {
+	return mysqlDialect.changeSQL(mtl.tableTarget(schema, table), change)
+}
+
+func (mtl *MySQLTreeLoader) TableExportSource(ctx context.Context, database, schema, table string, columns []string, view TableView) ExportSource {
+	keyColumns := func() ([]string, error) {
+		return mtl.GetRowKeyColumns(ctx, database, schema, table)
+	}
+	source := tableExportSource(columns, view, mysqlDialect, keyColumns, func(opts TableQueryOptions) (*ResultSet, error) {
+		return mtl.GetTableData(ctx, database, schema, table, opts)
+	})
+	source.Table = mtl.tableTarget(schema, table)
+	return source
+}
+
+func (mtl *MySQLTreeLoader) QueryExportSource(results *ResultSet) ExportSource {
+	source := staticExportSource(results)
+	source.Table = quoteMySQLIdentifier(queryExportTable)
+	source.Dialect = mysqlDialect
+	return source
+}
+
+func (mtl *MySQLTreeLoader) ImportRows(ctx context.Context, database, schema, table string, rows []map[string]interface{}) (ImportResult, error) {
+	return importRowsInTx(ctx, mtl.db, rows, func(change PendingChange) (string, []interface{}, error) {
+		return mtl.BuildChangeSQL(schema, table, change)
+	})
+}
+
+func (mtl *MySQLTreeLoader) ApplyChanges(ctx context.Context, database, schema, table string, changes []PendingChange) error {
+	return applyChangesInTx(ctx, mtl.db, changes, func(change PendingChange) (string, []interface{}, error) {
+		return mtl.BuildChangeSQL(schema, table, change)
+	})
+}
+
+func (mtl *MySQLTreeLoader) BeginTransaction(ctx context.Context) (*QueryTransaction, error) {
+	return beginQueryTransaction(ctx, mtl.db)
+}
+
+func (mtl *MySQLTreeLoader) OpenSession(ctx context.Context, tx *QueryTransaction) (*QuerySession, error) {
+	return openQuerySession(ctx, mtl.db, tx)
+}
+
+// ExecuteStatement reads the statement's warnings in the same session, as
+// MySQL keeps them only until the next statement.
+func (mtl *MySQLTreeLoader) ExecuteStatement(ctx context.Context, session *QuerySession, statement string, args ...interface{}) StatementResult {
+	result := executeStatement(ctx, session.queryer, ConnectionMySQL, statement, isMySQLBinaryType, args...)
+	if result.Err == nil {
+		result.Notices = mysqlWarnings(ctx, session.queryer)
+	}
+	return result
+}
+
+func mysqlWarnings(ctx context.Context, queryer sqlQueryer) []string {
+	rows, err := queryer.QueryContext(ctx, "SHOW WARNINGS")
+	if err != nil {
+		return nil
+	}
+	defer rows.Close()
+
+	var warnings []string
+	for rows.Next() {
+		var level, message string
+		var code int
+		if err := rows.Scan(&level, &code, &message); err != nil {
+			return warnings
+		}
+		warnings = append(warnings, fmt.Sprintf("%s %d: %s", level, code, message))
+	}
+	return warnings
+}
+
+// The MySQL text protocol hands every value back as []byte; only genuinely
+// binary columns are kept that way so the grid shows readable text.
+func isMySQLBinaryType(typeName string) bool {
+	typeName = strings.ToUpper(typeName)
+	return strings.Contains(typeName, "BLOB") || strings.Contains(typeName, "BINARY") || typeName == "BIT" || typeName == "GEOMETRY"
+}
+
+func formatByteSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d bytes", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.0f %cB", float64(size)/float64(div), "kMGTPE"[exp])
+}
@@ -2,14 +2,13 @@ package main
 
 import (
 	"fmt"
-	"sort"
 	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
 )
 
 var hiddenDataColumns = map[string]bool{
-	"__rowid": true,
+	rowIDColumn: true,
 }
 
 type PaneType int
@@ -34,8 +33,8 @@ type PaneState struct {
 type PaneModel struct {
 	panes             [4]*PaneState
 	focus             PaneType
-	data              []map[string]interface{}
-	dataColumns       []string
+	data              *ResultSet
+	dataColumns       []int
 	dataRowOffset     int
 	dataColOffset     int
 	dataViewportRows  int
@@ -45,6 +44,9 @@ type PaneModel struct {
 	dataDatabase      string
 	dataSchema        string
 	dataTable         string
+	dataKeyColumns    []string
+	changes           *ChangeBuffer
+	pager             dataPager
 }
 
 func NewPaneModel() *PaneModel {
@@ -56,10 +58,10 @@ func NewPaneModel() *PaneModel {
 			{PaneType: PaneData, SelectedIdx: 0, Offset: 0, Expanded: false},
 		},
 		focus:             PaneDatabases,
-		data:              make([]map[string]interface{}, 0),
-		dataColumns:       make([]string, 0),
+		data:              &ResultSet{},
 		dataViewportRows:  10,
 		dataViewportWidth: 80,
+		changes:           NewChangeBuffer(),
 	}
 }
 
@@ -128,39 +130,124 @@ func (pm *PaneModel) GetPane(paneType PaneType) *PaneState {
 	return pm.panes[paneType]
 }
 
-func (pm *PaneModel) SetData(data []map[string]interface{}) {
+func (pm *PaneModel) SetData(data *ResultSet) {
+	if data == nil {
+		data = &ResultSet{}
+	}
 	pm.data = data
 	pm.dataRowOffset = 0
 	pm.dataColOffset = 0
 	pm.dataColumns = pm.buildDataColumns()
 	pm.dataSelectedRow = 0
 	pm.dataSelectedCol = 0
+	pm.changes.Clear()
+	pm.pager.reset(data, nil, TableView{}, true, -1)
 }
 
-func (pm *PaneModel) GetData() []map[string]interface{} {
-	return pm.data
+// SetDataPaging enables lazy fetching for the rows passed to SetData, which
+// were read with the given sort and filter. Total is the number of matching
+// rows, or -1 when it is unknown.
+func (pm *PaneModel) SetDataPaging(keyColumns []string, view TableView, complete bool, total int64) {
+	pm.pager.reset(pm.data, keyColumns, view, complete, total)
+}
+
+func (pm *PaneModel) GetDataView() TableView {
+	return pm.pager.view
 }
 
-func (pm *PaneModel) buildDataColumns() []string {
-	if len(pm.data) == 0 {
-		return nil
+// NextDataPageRequest reports the page that must be fetched so the rows on
+// screen are loaded and the selection does not run into the end of the data.
+func (pm *PaneModel) NextDataPageRequest() (DataPageRequest, bool) {
+	if pm.data.Len() == 0 {
+		return DataPageRequest{}, false
+	}
+	first := pm.GetDataRowOffset()
+	last := first + pm.GetDataViewportRows() - 1
+	if pm.dataSelectedRow > last {
+		last = pm.dataSelectedRow
+	}
+	if last >= pm.data.Len() {
+		last = pm.data.Len() - 1
 	}
+	return pm.pager.request(first, last, pm.data.Len(), pm.GetDataViewportRows())
+}
+
+func (pm *PaneModel) ApplyDataPage(req DataPageRequest, rows *ResultSet) bool {
+	if !pm.pager.apply(pm.data, req, rows) {
+		return false
+	}
+	pm.pager.release(pm.data, pm.dataSelectedRow, pm.changes.Touches)
+	return true
+}
+
+// FailDataPage lets a page whose fetch failed be requested again.
+func (pm *PaneModel) FailDataPage(req DataPageRequest) {
+	pm.pager.fail(req)
+}
+
+func (pm *PaneModel) IsDataRowLoaded(rowIdx int) bool {
+	return rowIdx >= 0 && rowIdx < pm.data.Len() && pm.data.Rows[rowIdx] != nil
+}
+
+// GetDataTotalRows returns the table's row count and whether it is exact;
+// without a count it falls back to the rows fetched so far.
+func (pm *PaneModel) GetDataTotalRows() (int64, bool) {
+	if pm.pager.total >= 0 {
+		return pm.pager.total, true
+	}
+	return int64(pm.data.Len()), pm.pager.complete
+}
+
+func (pm *PaneModel) GetDataLoadedRows() int {
+	return pm.pager.loadedRows()
+}
+
+func (pm *PaneModel) HasReleasedDataPages() bool {
+	return pm.pager.released()
+}
 
-	columns := make([]string, 0, len(pm.data[0]))
-	for col := range pm.data[0] {
-		if hiddenDataColumns[strings.ToLower(col)] || hiddenDataColumns[col] {
+func (pm *PaneModel) GetData() *ResultSet {
+	return pm.data
+}
+
+// buildDataColumns lists the result set columns shown in the grid, in the
+// order the database returned them.
+func (pm *PaneModel) buildDataColumns() []int {
+	columns := make([]int, 0, pm.data.ColumnCount())
+	for idx, col := range pm.data.Columns {
+		if hiddenDataColumns[strings.ToLower(col.Name)] || hiddenDataColumns[col.Name] {
 			continue
 		}
-		columns = append(columns, col)
+		columns = append(columns, idx)
 	}
-	sort.Strings(columns)
 	return columns
 }
 
 func (pm *PaneModel) GetDataColumns() []string {
+	names := make([]string, len(pm.dataColumns))
+	for idx, colIdx := range pm.dataColumns {
+		names[idx] = pm.data.Columns[colIdx].Name
+	}
+	return names
+}
+
+// GetDataColumnIndexes returns the result set index of each visible column.
+func (pm *PaneModel) GetDataColumnIndexes() []int {
 	return pm.dataColumns
 }
 
+func (pm *PaneModel) GetDataColumn(col int) (ResultColumn, bool) {
+	if col < 0 || col >= len(pm.dataColumns) {
+		return ResultColumn{}, false
+	}
+	return pm.data.Columns[pm.dataColumns[col]], true
+}
+
+func (pm *PaneModel) GetDataValue(rowIdx int, column string) interface{} {
+	value, _ := pm.data.Lookup(rowIdx, column)
+	return value
+}
+
 func (pm *PaneModel) SetPaneViewport(paneType PaneType, height int) {
 	if height < 1 {
 		height = 1
@@ -212,11 +299,11 @@ func (pm *PaneModel) GetDataColOffset() int {
 }
 
 func (pm *PaneModel) ScrollDataRows(delta int) {
-	if len(pm.data) == 0 {
+	if pm.data.Len() == 0 {
 		return
 	}
 	pm.dataRowOffset += delta
-	maxOffset := len(pm.data) - pm.GetDataViewportRows()
+	maxOffset := pm.data.Len() - pm.GetDataViewportRows()
 	if maxOffset < 0 {
 		maxOffset = 0
 	}
@@ -243,21 +330,23 @@ func (pm *PaneModel) ScrollDataCols(delta int) {
 	}
 }
 
+// visibleColumnCount is the number of columns from the current offset that
+// fit in the viewport, sized like PaneRenderer sizes them for the rows on
+// screen.
 func (pm *PaneModel) visibleColumnCount() int {
 	maxWidth := pm.GetDataViewportWidth()
 	if maxWidth < 20 {
 		maxWidth = 20
 	}
+	startRow := pm.GetDataRowOffset()
+	endRow := startRow + pm.GetDataViewportRows()
+	if endRow > pm.data.Len() {
+		endRow = pm.data.Len()
+	}
 	widthRemaining := maxWidth
 	count := 0
-	for _, col := range pm.dataColumns {
-		colWidth := len(col)
-		if colWidth < 8 {
-			colWidth = 8
-		}
-		if colWidth > 30 {
-			colWidth = 30
-		}
+	for _, colIdx := range pm.dataColumns[pm.GetDataColOffset():] {
+		colWidth := dataColumnWidth(pm.data, colIdx, startRow, endRow)
 		space := 1
 		if count == 0 {
 			space = 0
@@ -274,16 +363,34 @@ func (pm *PaneModel) visibleColumnCount() int {
 	return count
 }
 
+// dataColumnWidth is the grid width of a column: its widest value among
+// rows [startRow, endRow) or its name, between 8 and 30 characters.
+func dataColumnWidth(data *ResultSet, column, startRow, endRow int) int {
+	width := len(data.Columns[column].Name)
+	for rowIdx := startRow; rowIdx < endRow; rowIdx++ {
+		if valWidth := len(fmt.Sprintf("%v", data.Value(rowIdx, column))); valWidth > width {
+			width = valWidth
+		}
+	}
+	if width > 30 {
+		width = 30
+	}
+	if width < 8 {
+		width = 8
+	}
+	return width
+}
+
 func (pm *PaneModel) MoveDataSelection(rowDelta, colDelta int) {
-	if len(pm.data) == 0 || len(pm.dataColumns) == 0 {
+	if pm.data.Len() == 0 || len(pm.dataColumns) == 0 {
 		return
 	}
 
 	pm.dataSelectedRow += rowDelta
 	if pm.dataSelectedRow < 0 {
 		pm.dataSelectedRow = 0
-	} else if pm.dataSelectedRow >= len(pm.data) {
-		pm.dataSelectedRow = len(pm.data) - 1
+	} else if pm.dataSelectedRow >= pm.data.Len() {
+		pm.dataSelectedRow = pm.data.Len() - 1
 	}
 
 	pm.dataSelectedCol += colDelta
@@ -296,6 +403,37 @@ func (pm *PaneModel) MoveDataSelection(rowDelta, colDelta int) {
 	pm.ensureDataSelectionVisible()
 }
 
+// HandleDataKey moves the grid selection for the navigation keys shared by
+// the Data pane and the query results grid, and reports whether msg was one
+// of them.
+func (pm *PaneModel) HandleDataKey(msg tea.KeyMsg) bool {
+	switch msg.Type {
+	case tea.KeyUp:
+		pm.MoveDataSelection(-1, 0)
+	case tea.KeyDown:
+		pm.MoveDataSelection(1, 0)
+	case tea.KeyLeft:
+		pm.MoveDataSelection(0, -1)
+	case tea.KeyRight:
+		pm.MoveDataSelection(0, 1)
+	case tea.KeyPgUp:
+		pm.MoveDataSelection(-pm.GetDataViewportRows(), 0)
+	case tea.KeyPgDown:
+		pm.MoveDataSelection(pm.GetDataViewportRows(), 0)
+	case tea.KeyHome:
+		pm.SetDataSelection(pm.dataSelectedRow, 0)
+	case tea.KeyEnd:
+		pm.SetDataSelection(pm.dataSelectedRow, len(pm.dataColumns)-1)
+	case tea.KeyCtrlHome:
+		pm.SetDataSelection(0, pm.dataSelectedCol)
+	case tea.KeyCtrlEnd:
+		pm.SetDataSelection(pm.data.Len()-1, pm.dataSelectedCol)
+	default:
+		return false
+	}
+	return true
+}
+
 func (pm *PaneModel) ensureDataSelectionVisible() {
 	visibleRows := pm.GetDataViewportRows()
 	if pm.dataSelectedRow < pm.dataRowOffset {
@@ -304,14 +442,12 @@ func (pm *PaneModel) ensureDataSelectionVisible() {
 		pm.dataRowOffset = pm.dataSelectedRow - visibleRows + 1
 	}
 
-	visibleCols := pm.visibleColumnCount()
-	if visibleCols < 1 {
-		visibleCols = 1
-	}
 	if pm.dataSelectedCol < pm.dataColOffset {
 		pm.dataColOffset = pm.dataSelectedCol
-	} else if pm.dataSelectedCol >= pm.dataColOffset+visibleCols {
-		pm.dataColOffset = pm.dataSelectedCol - visibleCols + 1
+	}
+	// Columns differ in width, so step right until the selection fits.
+	for pm.dataColOffset < pm.dataSelectedCol && pm.dataSelectedCol >= pm.dataColOffset+pm.visibleColumnCount() {
+		pm.dataColOffset++
 	}
 
 	if pm.dataRowOffset < 0 {
@@ -326,8 +462,8 @@ func (pm *PaneModel) SetDataSelection(row, col int) {
 	if row < 0 {
 		row = 0
 	}
-	if row >= len(pm.data) {
-		row = len(pm.data) - 1
+	if row >= pm.data.Len() {
+		row = pm.data.Len() - 1
 		if row < 0 {
 			row = 0
 		}
@@ -346,21 +482,22 @@ func (pm *PaneModel) SetDataSelection(row, col int) {
 	pm.ensureDataSelectionVisible()
 }
 
-func (pm *PaneModel) GetSelectedDataCell() (row map[string]interface{}, column string, value interface{}) {
-	if len(pm.data) == 0 || len(pm.dataColumns) == 0 {
-		return nil, "", nil
+func (pm *PaneModel) GetSelectedDataCell() (row int, column string, value interface{}) {
+	if pm.data.Len() == 0 || len(pm.dataColumns) == 0 {
+		return -1, "", nil
 	}
 
-	if pm.dataSelectedRow < 0 || pm.dataSelectedRow >= len(pm.data) {
-		return nil, "", nil
+	if pm.dataSelectedRow < 0 || pm.dataSelectedRow >= pm.data.Len() {
+		return -1, "", nil
 	}
 	if pm.dataSelectedCol < 0 || pm.dataSelectedCol >= len(pm.dataColumns) {
-		return nil, "", nil
+		return -1, "", nil
 	}
 
-	row = pm.data[pm.dataSelectedRow]
-	column = pm.dataColumns[pm.dataSelectedCol]
-	value = row[column]
+	colIdx := pm.dataColumns[pm.dataSelectedCol]
+	row = pm.dataSelectedRow
+	column = pm.data.Columns[colIdx].Name
+	value = pm.data.Value(row, colIdx)
 	return
 }
 
@@ -387,14 +524,14 @@ func (pm *PaneModel) GetSelectedDataColIndex() int {
 }
 
 func (pm *PaneModel) GetSelectedDataColumnName() string {
-	if pm.dataSelectedCol >= 0 && pm.dataSelectedCol < len(pm.dataColumns) {
-		return pm.dataColumns[pm.dataSelectedCol]
+	if col, ok := pm.GetDataColumn(pm.dataSelectedCol); ok {
+		return col.Name
 	}
 	return ""
 }
 
 func (pm *PaneModel) GetDataRowCount() int {
-	return len(pm.data)
+	return pm.data.Len()
 }
 
 func (pm *PaneModel) GetDataColCount() int {
@@ -402,31 +539,113 @@ func (pm *PaneModel) GetDataColCount() int {
 }
 
 func (pm *PaneModel) GetColumnIndexByName(name string) int {
-	for idx, col := range pm.dataColumns {
-		if col == name {
+	for idx, colIdx := range pm.dataColumns {
+		if pm.data.Columns[colIdx].Name == name {
 			return idx
 		}
 	}
 	return -1
 }
 
-func (pm *PaneModel) GetRowID(rowIdx int) string {
-	if rowIdx < 0 || rowIdx >= len(pm.data) {
-		return ""
+func (pm *PaneModel) SetDataKeyColumns(columns []string) {
+	pm.dataKeyColumns = columns
+}
+
+func (pm *PaneModel) GetDataKeyColumns() []string {
+	return pm.dataKeyColumns
+}
+
+func (pm *PaneModel) UsesPhysicalRowKey() bool {
+	return len(pm.dataKeyColumns) == 0
+}
+
+func (pm *PaneModel) HasPhysicalRowLocator() bool {
+	return pm.data.ColumnIndex(rowIDColumn) >= 0
+}
+
+func (pm *PaneModel) GetRowID(rowIdx int) RowKey {
+	if rowIdx < 0 || rowIdx >= pm.data.Len() {
+		return RowKey{}
 	}
-	row := pm.data[rowIdx]
-	if val, ok := row["__rowid"]; ok {
-		if b, ok := val.([]byte); ok {
-			return string(b)
-		}
-		if s, ok := val.(string); ok {
-			return s
+	if len(pm.dataKeyColumns) > 0 {
+		values := make([]interface{}, 0, len(pm.dataKeyColumns))
+		for _, col := range pm.dataKeyColumns {
+			val, ok := pm.data.Lookup(rowIdx, col)
+			if !ok {
+				return RowKey{}
+			}
+			values = append(values, val)
 		}
-		return fmt.Sprint(val)
+		return RowKey{Columns: pm.dataKeyColumns, Values: values}
 	}
-	return ""
+	if val, ok := pm.data.Lookup(rowIdx, rowIDColumn); ok {
+		return PhysicalRowKey(val)
+	}
+	return RowKey{}
 }
 
-func (pm *PaneModel) GetSelectedRowID() string {
+func (pm *PaneModel) GetSelectedRowID() RowKey {
 	return pm.GetRowID(pm.dataSelectedRow)
 }
+
+func (pm *PaneModel) StageCellUpdate(rowIdx int, column string, value interface{}) bool {
+	if rowIdx < 0 || rowIdx >= pm.data.Len() || column == "" {
+		return false
+	}
+	colIdx := pm.data.ColumnIndex(column)
+	if colIdx < 0 {
+		return false
+	}
+	if current, ok := pm.data.Lookup(rowIdx, column); ok && sameCellValue(current, value) {
+		return true
+	}
+	if !pm.changes.StageUpdate(rowIdx, pm.GetRowID(rowIdx), column, value) {
+		return false
+	}
+	pm.data.SetValue(rowIdx, colIdx, value)
+	return true
+}
+
+func (pm *PaneModel) StageInsertRow(values map[string]interface{}) int {
+	rowIdx := pm.data.AppendRow(values)
+	pm.changes.StageInsert(rowIdx, values)
+	for col := range values {
+		pm.changes.markDirty(rowIdx, col)
+	}
+	return rowIdx
+}
+
+func (pm *PaneModel) ToggleRowDelete(rowIdx int) bool {
+	if rowIdx < 0 || rowIdx >= pm.data.Len() {
+		return false
+	}
+	return pm.changes.ToggleDelete(rowIdx, pm.GetRowID(rowIdx))
+}
+
+func (pm *PaneModel) HasPendingChanges() bool {
+	return pm.changes.Len() > 0
+}
+
+func (pm *PaneModel) PendingChangeCount() int {
+	return pm.changes.Len()
+}
+
+func (pm *PaneModel) GetPendingChanges() []PendingChange {
+	return pm.changes.Changes()
+}
+
+func (pm *PaneModel) ClearPendingChanges() {
+	pm.changes.Clear()
+}
+
+func (pm *PaneModel) IsDataCellDirty(rowIdx int, column string) bool {
+	return pm.changes.IsDirty(rowIdx, column)
+}
+
+func (pm *PaneModel) IsDataRowInserted(rowIdx int) bool {
+	return pm.changes.IsInserted(rowIdx)
+}
+
+func (pm *PaneModel) IsDataRowDeleted(rowIdx int) bool {
+	return pm.changes.IsDeleted(rowIdx)
+}
@@ -1,6 +1,8 @@
 package main
 
 import (
+	"context"
+	"fmt"
 	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
@@ -41,6 +43,8 @@ func (pn *PaneNavigator) HandleKeyMsg(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return pn.paneModel, nil
 	case tea.KeyCtrlQ:
 		return pn.openQueryEditor()
+	case tea.KeyCtrlO:
+		return pn.openImportWizard()
 	case tea.KeyCtrlX:
 		return pn.paneModel, tea.Quit
 	case tea.KeyEscape:
@@ -85,21 +89,26 @@ func (pn *PaneNavigator) navigateRight() (tea.Model, tea.Cmd) {
 		return pn.loadTableData(selectedNode)
 	}
 
-	// Try to load children if not already loaded
+	// Load children in the background if not already loaded
 	if len(selectedNode.Children) == 0 && pn.dbLoader != nil {
-		if err := pn.dbLoader.LoadChildren(selectedNode); err != nil {
-			return pn.paneModel, nil
-		}
+		loader, probe := pn.dbLoader, selectedNode.detached()
+		return pn.paneModel, runTask("Carregando "+selectedNode.Name, func(ctx context.Context) tea.Msg {
+			err := loader.LoadChildren(ctx, probe)
+			return PaneChildrenLoadedMsg{node: selectedNode, children: probe.Children, pane: currentFocus, err: err}
+		})
 	}
 
-	// If node has children, move to next pane
-	if len(selectedNode.Children) > 0 && currentFocus < PaneData {
-		nextPane := currentFocus + 1
-		pn.paneModel.SetPaneNodes(nextPane, selectedNode.Children, selectedNode)
+	pn.showChildren(selectedNode, currentFocus)
+	return pn.paneModel, nil
+}
+
+// showChildren moves to the pane after from, listing node's children.
+func (pn *PaneNavigator) showChildren(node *TreeNode, from PaneType) {
+	if len(node.Children) > 0 && from < PaneData {
+		nextPane := from + 1
+		pn.paneModel.SetPaneNodes(nextPane, node.Children, node)
 		pn.paneModel.SetFocus(nextPane)
 	}
-
-	return pn.paneModel, nil
 }
 
 func (pn *PaneNavigator) loadTableData(tableNode *TreeNode) (tea.Model, tea.Cmd) {
@@ -125,6 +134,43 @@ func (pn *PaneNavigator) loadTableData(tableNode *TreeNode) (tea.Model, tea.Cmd)
 	}
 }
 
+func (pn *PaneNavigator) openImportWizard() (tea.Model, tea.Cmd) {
+	if pn.paneModel.GetFocus() != PaneTables || pn.dbLoader == nil {
+		return pn.paneModel, nil
+	}
+	tableNode := pn.paneModel.GetSelectedNode(PaneTables)
+	if tableNode == nil || tableNode.Type != NodeTable {
+		return pn.paneModel, nil
+	}
+
+	parts := splitPath(pn.buildPath(tableNode))
+	if len(parts) < 3 {
+		return pn.paneModel, nil
+	}
+	parts = parts[len(parts)-3:]
+
+	loader, columns := pn.dbLoader, tableNode.Children
+	database, schema, table := parts[0], parts[1], parts[2]
+	return pn.paneModel, runTask("Carregando colunas de "+table, func(ctx context.Context) tea.Msg {
+		var loadErr error
+		if len(columns) == 0 {
+			probe := tableNode.detached()
+			if err := loader.LoadChildren(ctx, probe); err != nil {
+				loadErr = fmt.Errorf("failed to load columns: %w", err)
+			}
+			columns = probe.Children
+		}
+		return OpenImportMsg{
+			node:     tableNode,
+			database: database,
+			schema:   schema,
+			table:    table,
+			columns:  columns,
+			err:      loadErr,
+		}
+	})
+}
+
 func (pn *PaneNavigator) cycleFocus() {
 	currentFocus := pn.paneModel.GetFocus()
 	nextFocus := currentFocus + 1
@@ -188,6 +188,9 @@ func (pr *PaneRenderer) renderDataPane(paneModel *PaneModel, title string, width
 	paneModel.SetDataViewport(bodyHeight)
 	paneModel.SetDataViewportWidth(width - 4)
 
+	if view := paneModel.GetDataView().String(); view != "" {
+		title += " | " + view
+	}
 	header := pr.renderPaneHeader(title, isFocused)
 	body := pr.renderDataBody(paneModel, width, height-2, isFocused)
 	footer := pr.renderDataFooter(paneModel)
@@ -204,34 +207,38 @@ func (pr *PaneRenderer) renderDataPane(paneModel *PaneModel, title string, width
 
 func (pr *PaneRenderer) renderDataBody(paneModel *PaneModel, width, height int, isFocused bool) string {
 	data := paneModel.GetData()
-	columns := paneModel.GetDataColumns()
-	if len(data) == 0 || len(columns) == 0 {
+	columns := paneModel.GetDataColumnIndexes()
+	if len(columns) == 0 {
 		return pr.styles.Body.Render("  (no data)")
 	}
 
 	rowOffset := paneModel.GetDataRowOffset()
 	visibleRows := paneModel.GetDataViewportRows()
 	endRow := rowOffset + visibleRows
-	if endRow > len(data) {
-		endRow = len(data)
+	if endRow > data.Len() {
+		endRow = data.Len()
 	}
 
 	colOffset := paneModel.GetDataColOffset()
-	visibleColumns := pr.visibleColumns(columns[colOffset:], data, width-4)
+	visibleColumns := pr.visibleColumns(columns[colOffset:], data, rowOffset, endRow, width-4)
 
-	columnWidths := pr.calculateColumnWidths(visibleColumns, data[rowOffset:endRow])
+	columnWidths := pr.calculateColumnWidths(visibleColumns, data, rowOffset, endRow)
 	selectedRow := paneModel.GetSelectedDataRowIndex()
-	selectedCol := paneModel.GetSelectedDataColIndex()
-	colIndexLookup := make(map[string]int, len(columns))
-	for idx, col := range columns {
-		colIndexLookup[col] = idx
+	selectedCol := -1
+	if sel := paneModel.GetSelectedDataColIndex(); sel >= 0 && sel < len(columns) {
+		selectedCol = columns[sel]
 	}
 
 	var lines []string
 	var headerParts []string
+	view := paneModel.GetDataView()
 	for _, col := range visibleColumns {
 		style := lipgloss.NewStyle().Foreground(lipgloss.Color("#FFD700")).Bold(true).Width(columnWidths[col])
-		headerParts = append(headerParts, style.Render(col))
+		name := data.Columns[col].Name
+		if sort, ok := view.SortOf(name); ok {
+			name = sortArrow(sort) + name
+		}
+		headerParts = append(headerParts, style.Render(name))
 	}
 	lines = append(lines, strings.Join(headerParts, " "))
 	lines = append(lines, strings.Repeat("-", width-4))
@@ -240,11 +247,16 @@ func (pr *PaneRenderer) renderDataBody(paneModel *PaneModel, width, height int,
 	if maxRows < 1 {
 		maxRows = 1
 	}
-	for rowIdx := rowOffset; rowIdx < len(data) && rowIdx < rowOffset+maxRows; rowIdx++ {
-		row := data[rowIdx]
+	for rowIdx := rowOffset; rowIdx < data.Len() && rowIdx < rowOffset+maxRows; rowIdx++ {
 		var rowParts []string
 		rowSelected := rowIdx == selectedRow
 		rowStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#FFFFFF"))
+		switch {
+		case paneModel.IsDataRowDeleted(rowIdx):
+			rowStyle = rowStyle.Foreground(lipgloss.Color("#FF6B6B")).Strikethrough(true)
+		case paneModel.IsDataRowInserted(rowIdx):
+			rowStyle = rowStyle.Foreground(lipgloss.Color("#00FF7F"))
+		}
 		if rowSelected {
 			if isFocused {
 				rowStyle = rowStyle.Background(lipgloss.Color("#083863"))
@@ -252,9 +264,13 @@ func (pr *PaneRenderer) renderDataBody(paneModel *PaneModel, width, height int,
 				rowStyle = rowStyle.Background(lipgloss.Color("#2b2b2b"))
 			}
 		}
+		loaded := paneModel.IsDataRowLoaded(rowIdx)
 		for _, col := range visibleColumns {
-			val := row[col]
+			val := data.Value(rowIdx, col)
 			valStr := fmt.Sprintf("%v", val)
+			if !loaded {
+				valStr = "…"
+			}
 			if len(valStr) > columnWidths[col] {
 				truncateWidth := columnWidths[col]
 				if truncateWidth > 3 {
@@ -264,7 +280,10 @@ func (pr *PaneRenderer) renderDataBody(paneModel *PaneModel, width, height int,
 				}
 			}
 			cellStyle := rowStyle.Copy().Width(columnWidths[col])
-			if rowSelected && colIndexLookup[col] == selectedCol {
+			if paneModel.IsDataCellDirty(rowIdx, data.Columns[col].Name) {
+				cellStyle = cellStyle.Foreground(lipgloss.Color("#FFA500")).Bold(true)
+			}
+			if rowSelected && col == selectedCol {
 				if isFocused {
 					cellStyle = cellStyle.Foreground(lipgloss.Color("#000000")).Background(lipgloss.Color("#FFD700")).Bold(true)
 				} else {
@@ -281,44 +300,82 @@ func (pr *PaneRenderer) renderDataBody(paneModel *PaneModel, width, height int,
 
 func (pr *PaneRenderer) renderDataFooter(paneModel *PaneModel) string {
 	data := paneModel.GetData()
-	cols := paneModel.GetDataColumns()
-	if len(data) == 0 || len(cols) == 0 {
+	cols := paneModel.GetDataColumnIndexes()
+	if len(cols) == 0 {
 		return ""
 	}
 	rowOffset := paneModel.GetDataRowOffset()
 	visibleRows := paneModel.GetDataViewportRows()
 	rowEnd := rowOffset + visibleRows
-	if rowEnd > len(data) {
-		rowEnd = len(data)
+	if rowEnd > data.Len() {
+		rowEnd = data.Len()
+	}
+	rowStart := rowOffset + 1
+	if data.Len() == 0 {
+		rowStart = 0
 	}
 
 	colOffset := paneModel.GetDataColOffset()
-	colEnd := colOffset + len(pr.visibleColumns(cols[colOffset:], data, paneModel.GetDataViewportWidth()))
+	colEnd := colOffset + len(pr.visibleColumns(cols[colOffset:], data, rowOffset, rowEnd, paneModel.GetDataViewportWidth()))
 	if colEnd > len(cols) {
 		colEnd = len(cols)
 	}
 
-	info := fmt.Sprintf("rows %d-%d of %d | cols %d-%d of %d",
-		rowOffset+1, rowEnd, len(data), colOffset+1, colEnd, len(cols))
+	total, exact := paneModel.GetDataTotalRows()
+	totalText := fmt.Sprintf("%d", total)
+	if !exact {
+		totalText += "+"
+	}
+	info := fmt.Sprintf("rows %d-%d of %s | cols %d-%d of %d",
+		rowStart, rowEnd, totalText, colOffset+1, colEnd, len(cols))
+	if paneModel.HasReleasedDataPages() {
+		info += fmt.Sprintf(" | %d em memória", paneModel.GetDataLoadedRows())
+	}
+	if col, ok := paneModel.GetDataColumn(paneModel.GetSelectedDataColIndex()); ok {
+		info += " | " + describeResultColumn(col)
+	}
+	if paneModel.HasPendingChanges() {
+		info += fmt.Sprintf(" | ● %d pendente(s)", paneModel.PendingChangeCount())
+	}
+	if paneModel.HasDataContext() && paneModel.UsesPhysicalRowKey() {
+		if paneModel.HasPhysicalRowLocator() {
+			info += " | ⚠ sem chave primária: edições usam ctid/rowid"
+		} else {
+			info += " | ⚠ sem chave primária: somente leitura"
+		}
+	}
 	return pr.styles.Status.Render(info)
 }
 
-func (pr *PaneRenderer) visibleColumns(columns []string, data []map[string]interface{}, maxWidth int) []string {
+func describeResultColumn(col ResultColumn) string {
+	desc := col.Name
+	if col.TypeName != "" {
+		desc += " " + strings.ToLower(col.TypeName)
+	}
+	if col.NullableKnown && !col.Nullable {
+		desc += " not null"
+	}
+	return desc
+}
+
+func (pr *PaneRenderer) renderChangePreview(statements []string, width int) string {
+	lines := []string{pr.styles.Header.Render(fmt.Sprintf("SQL pendente (%d)", len(statements)))}
+	for _, stmt := range statements {
+		lines = append(lines, pr.styles.Normal.Render(stmt))
+	}
+	return pr.styles.Unfocused.Width(max(width-2, 20)).Render(strings.Join(lines, "\n"))
+}
+
+func (pr *PaneRenderer) visibleColumns(columns []int, data *ResultSet, startRow, endRow, maxWidth int) []int {
 	if maxWidth < 20 {
 		maxWidth = 20
 	}
 
-	var selected []string
+	var selected []int
 	currentWidth := 0
 
 	for _, col := range columns {
-		width := pr.computeColumnWidth(col, data)
-		if width < 8 {
-			width = 8
-		}
-		if width > 30 {
-			width = 30
-		}
+		width := dataColumnWidth(data, col, startRow, endRow)
 
 		space := 1
 		if len(selected) == 0 {
@@ -340,29 +397,10 @@ func (pr *PaneRenderer) visibleColumns(columns []string, data []map[string]inter
 	return selected
 }
 
-func (pr *PaneRenderer) computeColumnWidth(column string, data []map[string]interface{}) int {
-	maxWidth := len(column)
-	for _, row := range data {
-		val := row[column]
-		valStr := fmt.Sprintf("%v", val)
-		if len(valStr) > maxWidth {
-			maxWidth = len(valStr)
-		}
-	}
-	return maxWidth
-}
-
-func (pr *PaneRenderer) calculateColumnWidths(columns []string, data []map[string]interface{}) map[string]int {
-	widths := make(map[string]int, len(columns))
+func (pr *PaneRenderer) calculateColumnWidths(columns []int, data *ResultSet, startRow, endRow int) map[int]int {
+	widths := make(map[int]int, len(columns))
 	for _, col := range columns {
-		width := pr.computeColumnWidth(col, data)
-		if width > 30 {
-			width = 30
-		}
-		if width < 8 {
-			width = 8
-		}
-		widths[col] = width
+		widths[col] = dataColumnWidth(data, col, startRow, endRow)
 	}
 	return widths
 }
@@ -387,6 +425,9 @@ func (pr *PaneRenderer) renderStatus(paneModel *PaneModel) string {
 
 	status := strings.Join(parts, " | ")
 	status += " | Tab: Switch Pane | ←/→: Navigate | Enter: Drill Down | Ctrl+Q: Query | ESC: Back/Quit | Ctrl+X: Quit"
+	if currentFocus == PaneTables {
+		status += " | Ctrl+O: Import"
+	}
 
 	return pr.styles.Status.Render(status)
 }
@@ -0,0 +1,296 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+type ChangeKind int
+
+const (
+	ChangeUpdate ChangeKind = iota
+	ChangeInsert
+	ChangeDelete
+)
+
+func (ck ChangeKind) String() string {
+	switch ck {
+	case ChangeUpdate:
+		return "UPDATE"
+	case ChangeInsert:
+		return "INSERT"
+	case ChangeDelete:
+		return "DELETE"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// Updates are coalesced per row so that edits to key columns and other
+// columns of the same row land in a single statement.
+type PendingChange struct {
+	Kind   ChangeKind
+	Row    int
+	Key    RowKey
+	Values map[string]interface{}
+}
+
+type ChangeBuffer struct {
+	changes []*PendingChange
+	rowKeys map[int]RowKey
+	inserts map[int]*PendingChange
+	deletes map[int]bool
+	dirty   map[int]map[string]bool
+}
+
+func NewChangeBuffer() *ChangeBuffer {
+	return &ChangeBuffer{
+		rowKeys: make(map[int]RowKey),
+		inserts: make(map[int]*PendingChange),
+		deletes: make(map[int]bool),
+		dirty:   make(map[int]map[string]bool),
+	}
+}
+
+func (cb *ChangeBuffer) Len() int {
+	return len(cb.changes)
+}
+
+func (cb *ChangeBuffer) Clear() {
+	cb.changes = nil
+	cb.rowKeys = make(map[int]RowKey)
+	cb.inserts = make(map[int]*PendingChange)
+	cb.deletes = make(map[int]bool)
+	cb.dirty = make(map[int]map[string]bool)
+}
+
+func (cb *ChangeBuffer) Changes() []PendingChange {
+	changes := make([]PendingChange, 0, len(cb.changes))
+	for _, change := range cb.changes {
+		copied := *change
+		copied.Values = make(map[string]interface{}, len(change.Values))
+		for col, val := range change.Values {
+			copied.Values[col] = val
+		}
+		changes = append(changes, copied)
+	}
+	return changes
+}
+
+// The key of a row is captured the first time it is touched, so edits to key
+// columns still target the row as it exists in the database.
+func (cb *ChangeBuffer) rememberKey(row int, key RowKey) RowKey {
+	if existing, ok := cb.rowKeys[row]; ok {
+		return existing
+	}
+	if !key.IsEmpty() {
+		cb.rowKeys[row] = key
+	}
+	return key
+}
+
+func (cb *ChangeBuffer) StageUpdate(row int, key RowKey, column string, value interface{}) bool {
+	if cb.deletes[row] {
+		return false
+	}
+
+	if insert, ok := cb.inserts[row]; ok {
+		insert.Values[column] = value
+		cb.markDirty(row, column)
+		return true
+	}
+
+	key = cb.rememberKey(row, key)
+	if key.IsEmpty() {
+		return false
+	}
+
+	cb.markDirty(row, column)
+	for _, change := range cb.changes {
+		if change.Kind == ChangeUpdate && change.Row == row {
+			change.Values[column] = value
+			return true
+		}
+	}
+
+	cb.changes = append(cb.changes, &PendingChange{
+		Kind:   ChangeUpdate,
+		Row:    row,
+		Key:    key,
+		Values: map[string]interface{}{column: value},
+	})
+	return true
+}
+
+func (cb *ChangeBuffer) StageInsert(row int, values map[string]interface{}) {
+	change := &PendingChange{
+		Kind:   ChangeInsert,
+		Row:    row,
+		Values: values,
+	}
+	cb.changes = append(cb.changes, change)
+	cb.inserts[row] = change
+}
+
+// ToggleDelete marks a row for deletion, or restores it when it was already
+// marked. A staged insert is withdrawn from the changes but kept, so that
+// toggling again stages it anew instead of leaving a row that looks saved.
+func (cb *ChangeBuffer) ToggleDelete(row int, key RowKey) bool {
+	if insert, ok := cb.inserts[row]; ok {
+		if cb.deletes[row] {
+			cb.changes = append(cb.changes, insert)
+			delete(cb.deletes, row)
+		} else {
+			cb.removeChange(insert)
+			cb.deletes[row] = true
+		}
+		return true
+	}
+
+	if cb.deletes[row] {
+		for _, change := range cb.changes {
+			if change.Kind == ChangeDelete && change.Row == row {
+				cb.removeChange(change)
+				break
+			}
+		}
+		delete(cb.deletes, row)
+		return true
+	}
+
+	key = cb.rememberKey(row, key)
+	if key.IsEmpty() {
+		return false
+	}
+
+	cb.changes = append(cb.changes, &PendingChange{
+		Kind: ChangeDelete,
+		Row:  row,
+		Key:  key,
+	})
+	cb.deletes[row] = true
+	return true
+}
+
+func (cb *ChangeBuffer) removeChange(target *PendingChange) {
+	for idx, change := range cb.changes {
+		if change == target {
+			cb.changes = append(cb.changes[:idx], cb.changes[idx+1:]...)
+			return
+		}
+	}
+}
+
+func (cb *ChangeBuffer) markDirty(row int, column string) {
+	cols, ok := cb.dirty[row]
+	if !ok {
+		cols = make(map[string]bool)
+		cb.dirty[row] = cols
+	}
+	cols[column] = true
+}
+
+func (cb *ChangeBuffer) IsDirty(row int, column string) bool {
+	return cb.dirty[row][column]
+}
+
+func (cb *ChangeBuffer) IsInserted(row int) bool {
+	_, ok := cb.inserts[row]
+	return ok
+}
+
+func (cb *ChangeBuffer) IsDeleted(row int) bool {
+	return cb.deletes[row]
+}
+
+// Touches reports whether any staged change refers to the row.
+func (cb *ChangeBuffer) Touches(row int) bool {
+	return len(cb.dirty[row]) > 0 || cb.deletes[row] || cb.IsInserted(row)
+}
+
+// sameCellValue reports whether an edit leaves a cell as it was. Values are
+// compared as the editor shows them, since the driver and the typed input
+// may use different types for the same value.
+func sameCellValue(current, value interface{}) bool {
+	if current == nil || value == nil {
+		return current == nil && value == nil
+	}
+	if b, ok := current.([]byte); ok {
+		current = string(b)
+	}
+	return fmt.Sprintf("%v", current) == fmt.Sprintf("%v", value)
+}
+
+func sortedValueColumns(values map[string]interface{}) []string {
+	columns := make([]string, 0, len(values))
+	for col := range values {
+		columns = append(columns, col)
+	}
+	sort.Strings(columns)
+	return columns
+}
+
+func applyChangesInTx(ctx context.Context, db *sql.DB, changes []PendingChange, build func(PendingChange) (string, []interface{}, error)) error {
+	if len(changes) == 0 {
+		return nil
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	for idx, change := range changes {
+		query, args, err := build(change)
+		if err != nil {
+			tx.Rollback()
+			return fmt.Errorf("change %d (%s): %w", idx+1, change.Kind, err)
+		}
+		result, err := tx.ExecContext(ctx, query, args...)
+		if err != nil {
+			tx.Rollback()
+			return fmt.Errorf("change %d (%s): %w", idx+1, change.Kind, err)
+		}
+		if change.Kind != ChangeInsert {
+			if err := expectSingleRow(result, change.Key); err != nil {
+				tx.Rollback()
+				return fmt.Errorf("change %d (%s): %w", idx+1, change.Kind, err)
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit changes: %w", err)
+	}
+	return nil
+}
+
+func formatSQLPreview(query string, args []interface{}) string {
+	lines := strings.Fields(query)
+	preview := strings.Join(lines, " ")
+	if len(args) == 0 {
+		return preview + ";"
+	}
+
+	rendered := make([]string, 0, len(args))
+	for _, arg := range args {
+		rendered = append(rendered, formatPreviewArg(arg))
+	}
+	return fmt.Sprintf("%s; -- [%s]", preview, strings.Join(rendered, ", "))
+}
+
+func formatPreviewArg(arg interface{}) string {
+	switch v := arg.(type) {
+	case nil:
+		return "NULL"
+	case string:
+		return "'" + strings.ReplaceAll(v, "'", "''") + "'"
+	case []byte:
+		return "'" + strings.ReplaceAll(string(v), "'", "''") + "'"
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
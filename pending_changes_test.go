@@ -0,0 +1,232 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestChangeBuffer(t *testing.T) {
+	key := func(id int64) RowKey {
+		return RowKey{Columns: []string{"id"}, Values: []interface{}{id}}
+	}
+
+	tests := []struct {
+		name  string
+		stage func(cb *ChangeBuffer)
+		want  []PendingChange
+	}{
+		{
+			name: "updates to one row coalesce",
+			stage: func(cb *ChangeBuffer) {
+				cb.StageUpdate(0, key(1), "name", "a")
+				cb.StageUpdate(0, key(1), "age", int64(3))
+				cb.StageUpdate(0, key(1), "name", "b")
+			},
+			want: []PendingChange{
+				{Kind: ChangeUpdate, Row: 0, Key: key(1), Values: map[string]interface{}{"name": "b", "age": int64(3)}},
+			},
+		},
+		{
+			name: "first key wins after a key column edit",
+			stage: func(cb *ChangeBuffer) {
+				cb.StageUpdate(0, key(1), "id", int64(9))
+				cb.StageUpdate(0, key(9), "name", "a")
+			},
+			want: []PendingChange{
+				{Kind: ChangeUpdate, Row: 0, Key: key(1), Values: map[string]interface{}{"id": int64(9), "name": "a"}},
+			},
+		},
+		{
+			name: "update without key is refused",
+			stage: func(cb *ChangeBuffer) {
+				cb.StageUpdate(0, RowKey{}, "name", "a")
+			},
+			want: []PendingChange{},
+		},
+		{
+			name: "update of a staged insert edits the insert",
+			stage: func(cb *ChangeBuffer) {
+				cb.StageInsert(5, map[string]interface{}{"name": "a"})
+				cb.StageUpdate(5, RowKey{}, "name", "b")
+			},
+			want: []PendingChange{
+				{Kind: ChangeInsert, Row: 5, Values: map[string]interface{}{"name": "b"}},
+			},
+		},
+		{
+			name: "deleted row refuses updates",
+			stage: func(cb *ChangeBuffer) {
+				cb.ToggleDelete(0, key(1))
+				cb.StageUpdate(0, key(1), "name", "a")
+			},
+			want: []PendingChange{
+				{Kind: ChangeDelete, Row: 0, Key: key(1)},
+			},
+		},
+		{
+			name: "toggling delete twice restores the row",
+			stage: func(cb *ChangeBuffer) {
+				cb.ToggleDelete(0, key(1))
+				cb.ToggleDelete(0, key(1))
+			},
+			want: []PendingChange{},
+		},
+		{
+			name: "deleting a staged insert withdraws it",
+			stage: func(cb *ChangeBuffer) {
+				cb.StageInsert(5, map[string]interface{}{"name": "a"})
+				cb.ToggleDelete(5, RowKey{})
+			},
+			want: []PendingChange{},
+		},
+		{
+			name: "undeleting a staged insert stages it again",
+			stage: func(cb *ChangeBuffer) {
+				cb.StageInsert(5, map[string]interface{}{"name": "a"})
+				cb.ToggleDelete(5, RowKey{})
+				cb.ToggleDelete(5, RowKey{})
+			},
+			want: []PendingChange{
+				{Kind: ChangeInsert, Row: 5, Values: map[string]interface{}{"name": "a"}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cb := NewChangeBuffer()
+			tt.stage(cb)
+			got := cb.Changes()
+			for idx := range got {
+				if len(got[idx].Values) == 0 {
+					got[idx].Values = nil
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Changes() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChangeBufferMarks(t *testing.T) {
+	cb := NewChangeBuffer()
+	cb.StageUpdate(0, RowKey{Columns: []string{"id"}, Values: []interface{}{int64(1)}}, "name", "a")
+	cb.StageInsert(1, map[string]interface{}{"name": "b"})
+	cb.ToggleDelete(2, PhysicalRowKey("(0,3)"))
+
+	if !cb.IsDirty(0, "name") || cb.IsDirty(0, "age") {
+		t.Errorf("IsDirty: want only row 0 name dirty")
+	}
+	if !cb.IsInserted(1) || cb.IsInserted(0) {
+		t.Errorf("IsInserted: want only row 1 inserted")
+	}
+	if !cb.IsDeleted(2) || cb.IsDeleted(0) {
+		t.Errorf("IsDeleted: want only row 2 deleted")
+	}
+	for row, want := range []bool{true, true, true, false} {
+		if got := cb.Touches(row); got != want {
+			t.Errorf("Touches(%d) = %v, want %v", row, got, want)
+		}
+	}
+
+	cb.Clear()
+	if cb.Len() != 0 || cb.Touches(0) || cb.Touches(1) || cb.Touches(2) {
+		t.Errorf("Clear left staged changes behind")
+	}
+}
+
+func TestChangeSQL(t *testing.T) {
+	id := RowKey{Columns: []string{"id"}, Values: []interface{}{int64(7)}}
+
+	tests := []struct {
+		name     string
+		dialect  sqlDialect
+		change   PendingChange
+		wantSQL  string
+		wantArgs []interface{}
+		wantErr  bool
+	}{
+		{
+			name:     "postgres update numbers placeholders after the values",
+			dialect:  postgresDialect,
+			change:   PendingChange{Kind: ChangeUpdate, Key: RowKey{Columns: []string{"a", "b"}, Values: []interface{}{int64(1), []byte("x")}}, Values: map[string]interface{}{"name": "n", "age": nil}},
+			wantSQL:  `UPDATE t SET "age" = $1, "name" = $2 WHERE "a" = $3 AND "b" = $4`,
+			wantArgs: []interface{}{nil, "n", int64(1), "x"},
+		},
+		{
+			name:     "postgres physical key uses ctid",
+			dialect:  postgresDialect,
+			change:   PendingChange{Kind: ChangeDelete, Key: PhysicalRowKey([]byte("(0,3)"))},
+			wantSQL:  `DELETE FROM t WHERE ctid = $1`,
+			wantArgs: []interface{}{"(0,3)"},
+		},
+		{
+			name:     "sqlite physical key binds the rowid as an integer",
+			dialect:  sqliteDialect,
+			change:   PendingChange{Kind: ChangeUpdate, Key: PhysicalRowKey("42"), Values: map[string]interface{}{"name": "n"}},
+			wantSQL:  `UPDATE t SET "name" = ? WHERE rowid = ?`,
+			wantArgs: []interface{}{"n", int64(42)},
+		},
+		{
+			name:     "mysql insert sorts columns",
+			dialect:  mysqlDialect,
+			change:   PendingChange{Kind: ChangeInsert, Values: map[string]interface{}{"b": "2", "a": int64(1)}},
+			wantSQL:  "INSERT INTO t (`a`, `b`) VALUES (?, ?)",
+			wantArgs: []interface{}{int64(1), "2"},
+		},
+		{
+			name:     "mysql delete by key",
+			dialect:  mysqlDialect,
+			change:   PendingChange{Kind: ChangeDelete, Key: id},
+			wantSQL:  "DELETE FROM t WHERE `id` = ?",
+			wantArgs: []interface{}{int64(7)},
+		},
+		{
+			name:    "mysql has no physical row locator",
+			dialect: mysqlDialect,
+			change:  PendingChange{Kind: ChangeDelete, Key: PhysicalRowKey("1")},
+			wantErr: true,
+		},
+		{
+			name:    "delete without key",
+			dialect: postgresDialect,
+			change:  PendingChange{Kind: ChangeDelete},
+			wantErr: true,
+		},
+		{
+			name:    "update without values",
+			dialect: postgresDialect,
+			change:  PendingChange{Kind: ChangeUpdate, Key: id},
+			wantErr: true,
+		},
+		{
+			name:    "insert without values",
+			dialect: sqliteDialect,
+			change:  PendingChange{Kind: ChangeInsert},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, args, err := tt.dialect.changeSQL("t", tt.change)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("changeSQL() = %q, want an error", query)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("changeSQL() error = %v", err)
+			}
+			if got := strings.Join(strings.Fields(query), " "); got != tt.wantSQL {
+				t.Errorf("changeSQL() query = %q, want %q", got, tt.wantSQL)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("changeSQL() args = %#v, want %#v", args, tt.wantArgs)
+			}
+		})
+	}
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const planViewerRows = 15
+
+// PlanViewer shows a QueryPlan as a collapsible tree below the editor.
+type PlanViewer struct {
+	plan     *QueryPlan
+	selected int
+	offset   int
+	styles   TreeStyles
+}
+
+func NewPlanViewer(plan *QueryPlan) *PlanViewer {
+	return &PlanViewer{plan: plan, styles: NewTreeRenderer().styles}
+}
+
+func (pv *PlanViewer) Plan() *QueryPlan {
+	return pv.plan
+}
+
+// visibleNodes flattens the tree, skipping children of collapsed nodes.
+func (pv *PlanViewer) visibleNodes() []*PlanNode {
+	var nodes []*PlanNode
+	var walk func(node *PlanNode)
+	walk = func(node *PlanNode) {
+		nodes = append(nodes, node)
+		if !node.Expanded {
+			return
+		}
+		for _, child := range node.Children {
+			walk(child)
+		}
+	}
+	walk(pv.plan.Root)
+	return nodes
+}
+
+func (pv *PlanViewer) selectedNode(nodes []*PlanNode) *PlanNode {
+	if pv.selected >= len(nodes) {
+		pv.selected = len(nodes) - 1
+	}
+	return nodes[pv.selected]
+}
+
+// Update moves through the tree: ←/→ collapse and expand (← on a leaf or
+// collapsed node jumps to its parent), Enter/Space toggles.
+func (pv *PlanViewer) Update(msg tea.KeyMsg) {
+	nodes := pv.visibleNodes()
+	node := pv.selectedNode(nodes)
+
+	switch msg.Type {
+	case tea.KeyUp:
+		pv.selected--
+	case tea.KeyDown:
+		pv.selected++
+	case tea.KeyPgUp:
+		pv.selected -= planViewerRows
+	case tea.KeyPgDown:
+		pv.selected += planViewerRows
+	case tea.KeyHome:
+		pv.selected = 0
+	case tea.KeyEnd:
+		pv.selected = len(nodes) - 1
+	case tea.KeyRight:
+		node.Expanded = true
+	case tea.KeyLeft:
+		if node.Expanded && len(node.Children) > 0 {
+			node.Expanded = false
+		} else if node.Parent != nil {
+			for idx, candidate := range nodes {
+				if candidate == node.Parent {
+					pv.selected = idx
+				}
+			}
+		}
+	case tea.KeyEnter, tea.KeySpace:
+		node.Expanded = !node.Expanded
+	}
+
+	if count := len(pv.visibleNodes()); pv.selected >= count {
+		pv.selected = count - 1
+	}
+	if pv.selected < 0 {
+		pv.selected = 0
+	}
+}
+
+func (pv *PlanViewer) View(width int) string {
+	nodes := pv.visibleNodes()
+	selected := pv.selectedNode(nodes)
+	if pv.selected < pv.offset {
+		pv.offset = pv.selected
+	}
+	if pv.selected >= pv.offset+planViewerRows {
+		pv.offset = pv.selected - planViewerRows + 1
+	}
+	end := pv.offset + planViewerRows
+	if end > len(nodes) {
+		end = len(nodes)
+	}
+
+	title := "Plano de execução (estimado)"
+	if pv.plan.Analyzed {
+		title = fmt.Sprintf("Plano de execução (ANALYZE) · planejamento %.3f ms · execução %.3f ms",
+			pv.plan.PlanningTime, pv.plan.ExecutionTime)
+	}
+	lines := []string{pv.styles.Header.Render(title)}
+	for idx := pv.offset; idx < end; idx++ {
+		lines = append(lines, pv.renderNode(nodes[idx], idx == pv.selected))
+	}
+	keys := "←/→ recolhe/expande | e: estimado | Esc: fecha"
+	if pv.plan.CanAnalyze {
+		keys = "←/→ recolhe/expande | a: ANALYZE | e: estimado | Esc: fecha"
+	}
+	lines = append(lines, pv.styles.Status.Render(fmt.Sprintf("%d/%d | %s", pv.selected+1, len(nodes), keys)))
+	for _, detail := range selected.Details {
+		lines = append(lines, pv.styles.Normal.Render("  "+detail))
+	}
+
+	return lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("#FF8C00")).
+		Width(max(width-4, 40)).
+		Render(strings.Join(lines, "\n"))
+}
+
+// renderNode indents like TreeRenderer and appends cost, estimated versus
+// actual rows, time and the node's share of the plan.
+func (pv *PlanViewer) renderNode(node *PlanNode, isSelected bool) string {
+	indent := strings.Repeat("  ", node.Level+1)
+	connector := "└─ "
+	marker := "  "
+	if len(node.Children) > 0 {
+		connector = "├─ "
+		marker = "▸ "
+		if node.Expanded {
+			marker = "▾ "
+		}
+	}
+
+	style := pv.styles.Normal
+	var flags []string
+	if node.Hot() {
+		style = lipgloss.NewStyle().Foreground(lipgloss.Color("#FF6B6B")).Bold(true)
+		flags = append(flags, "🔥")
+	}
+	if node.LargeSeqScan() {
+		if !node.Hot() {
+			style = pv.styles.Collapsed
+		}
+		flags = append(flags, "⚠ seq scan")
+	}
+	if isSelected {
+		style = pv.styles.Selected
+	}
+
+	var metrics []string
+	if node.HasCost {
+		metrics = append(metrics, fmt.Sprintf("custo %.2f..%.2f", node.StartupCost, node.TotalCost))
+		rows := fmt.Sprintf("linhas %.0f", node.PlanRows)
+		if node.HasActual {
+			rows += fmt.Sprintf(" → %.0f", node.ActualRows)
+			if node.Loops > 1 {
+				rows += fmt.Sprintf(" ×%.0f", node.Loops)
+			}
+		}
+		metrics = append(metrics, rows)
+	}
+	if node.HasActual {
+		metrics = append(metrics, fmt.Sprintf("%.3f ms", node.ActualTime*node.Loops))
+	}
+	if node.share > 0 {
+		metrics = append(metrics, fmt.Sprintf("%.0f%%", node.share*100))
+	}
+
+	line := style.Render(indent + connector + marker + node.Title)
+	if len(flags) > 0 {
+		line += " " + style.Render(strings.Join(flags, " "))
+	}
+	if len(metrics) > 0 {
+		line += " " + pv.styles.Status.Render("("+strings.Join(metrics, " · ")+")")
+	}
+	return line
+}
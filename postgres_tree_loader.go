@@ -1,101 +1,172 @@
 package main
 
 import (
+	"context"
 	"database/sql"
+	"database/sql/driver"
 	"fmt"
-	"sort"
 	"strings"
+	"sync"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/lib/pq"
 )
 
 type PostgresTreeLoader struct {
 	db          *sql.DB
 	connInfo    *ConnectionInfo
 	connections map[string]*sql.DB
+	keyColumns  map[string][]string
+	// mu guards the caches, filled from background tasks that may overlap
+	// when one is cancelled and the next started.
+	mu sync.Mutex
 }
 
-func (ptl *PostgresTreeLoader) UpdateCell(databaseName, schemaName, tableName, column, rowID string, value interface{}) error {
-	dbConn, err := ptl.getDatabaseConnection(databaseName)
+func (ptl *PostgresTreeLoader) ApplyChanges(ctx context.Context, databaseName, schemaName, tableName string, changes []PendingChange) error {
+	dbConn, err := ptl.getDatabaseConnection(ctx, databaseName)
 	if err != nil {
 		return err
 	}
 
-	query := fmt.Sprintf(`
-		UPDATE %s.%s
-		SET %s = $1
-		WHERE ctid = $2
-	`, quoteIdentifier(schemaName), quoteIdentifier(tableName), quoteIdentifier(column))
+	return applyChangesInTx(ctx, dbConn, changes, func(change PendingChange) (string, []interface{}, error) {
+		return ptl.BuildChangeSQL(schemaName, tableName, change)
+	})
+}
 
-	_, err = dbConn.Exec(query, value, rowID)
+func (ptl *PostgresTreeLoader) ImportRows(ctx context.Context, databaseName, schemaName, tableName string, rows []map[string]interface{}) (ImportResult, error) {
+	dbConn, err := ptl.getDatabaseConnection(ctx, databaseName)
 	if err != nil {
-		return fmt.Errorf("failed to update %s.%s.%s: %w", schemaName, tableName, column, err)
+		return ImportResult{}, err
 	}
-	return nil
+
+	return importRowsInTx(ctx, dbConn, rows, func(change PendingChange) (string, []interface{}, error) {
+		return ptl.BuildChangeSQL(schemaName, tableName, change)
+	})
 }
 
-func (ptl *PostgresTreeLoader) InsertRow(databaseName, schemaName, tableName string, values map[string]interface{}) error {
-	if len(values) == 0 {
-		return fmt.Errorf("no values provided for insert")
-	}
+func (ptl *PostgresTreeLoader) BuildChangeSQL(schemaName, tableName string, change PendingChange) (string, []interface{}, error) {
+	target := fmt.Sprintf("%s.%s", quoteIdentifier(schemaName), quoteIdentifier(tableName))
+	return postgresDialect.changeSQL(target, change)
+}
 
-	dbConn, err := ptl.getDatabaseConnection(databaseName)
-	if err != nil {
-		return err
+func (ptl *PostgresTreeLoader) TableExportSource(ctx context.Context, databaseName, schemaName, tableName string, columns []string, view TableView) ExportSource {
+	keyColumns := func() ([]string, error) {
+		return ptl.GetRowKeyColumns(ctx, databaseName, schemaName, tableName)
 	}
+	source := tableExportSource(columns, view, postgresDialect, keyColumns, func(opts TableQueryOptions) (*ResultSet, error) {
+		return ptl.GetTableData(ctx, databaseName, schemaName, tableName, opts)
+	})
+	source.Table = fmt.Sprintf("%s.%s", quoteIdentifier(schemaName), quoteIdentifier(tableName))
+	return source
+}
 
-	columns := make([]string, 0, len(values))
-	for col := range values {
-		columns = append(columns, col)
-	}
-	sort.Strings(columns)
+func (ptl *PostgresTreeLoader) QueryExportSource(results *ResultSet) ExportSource {
+	source := staticExportSource(results)
+	source.Table = quoteIdentifier(queryExportTable)
+	source.Dialect = postgresDialect
+	return source
+}
 
-	var placeholders []string
-	var args []interface{}
-	for idx, col := range columns {
-		placeholders = append(placeholders, fmt.Sprintf("$%d", idx+1))
-		args = append(args, values[col])
+func (ptl *PostgresTreeLoader) GetRowKeyColumns(ctx context.Context, databaseName, schemaName, tableName string) ([]string, error) {
+	cacheKey := tableCacheKey(databaseName, schemaName, tableName)
+	ptl.mu.Lock()
+	cols, ok := ptl.keyColumns[cacheKey]
+	ptl.mu.Unlock()
+	if ok {
+		return cols, nil
 	}
 
-	var quotedCols []string
-	for _, col := range columns {
-		quotedCols = append(quotedCols, quoteIdentifier(col))
+	dbConn, err := ptl.getDatabaseConnection(ctx, databaseName)
+	if err != nil {
+		return nil, err
 	}
 
-	query := fmt.Sprintf(`
-		INSERT INTO %s.%s (%s)
-		VALUES (%s)
-	`, quoteIdentifier(schemaName), quoteIdentifier(tableName), strings.Join(quotedCols, ", "), strings.Join(placeholders, ", "))
+	// Primary key first, then the narrowest unique index whose columns are
+	// all NOT NULL. Partial and expression indexes can't identify a row.
+	query := `
+		SELECT i.indexrelid, a.attname, a.attnotnull
+		FROM pg_index i
+		JOIN pg_class c ON c.oid = i.indrelid
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		JOIN LATERAL unnest(i.indkey::int2[]) WITH ORDINALITY AS k(attnum, ord) ON true
+		JOIN pg_attribute a ON a.attrelid = c.oid AND a.attnum = k.attnum
+		WHERE n.nspname = $1
+			AND c.relname = $2
+			AND (i.indisprimary OR i.indisunique)
+			AND i.indpred IS NULL
+			AND i.indexprs IS NULL
+			AND k.ord <= i.indnkeyatts
+		ORDER BY i.indisprimary DESC, i.indnkeyatts, i.indexrelid, k.ord
+	`
 
-	if _, err := dbConn.Exec(query, args...); err != nil {
-		return fmt.Errorf("failed to insert row: %w", err)
+	rows, err := dbConn.QueryContext(ctx, query, schemaName, tableName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover row key: %w", err)
 	}
-	return nil
-}
+	defer rows.Close()
 
-func (ptl *PostgresTreeLoader) DeleteRow(databaseName, schemaName, tableName, rowID string) error {
-	dbConn, err := ptl.getDatabaseConnection(databaseName)
-	if err != nil {
-		return err
+	var (
+		candidates [][]string
+		current    []string
+		currentOID int64 = -1
+		usable     bool
+	)
+	for rows.Next() {
+		var indexOID int64
+		var column string
+		var notNull bool
+		if err := rows.Scan(&indexOID, &column, &notNull); err != nil {
+			return nil, fmt.Errorf("scan failed: %w", err)
+		}
+		if indexOID != currentOID {
+			if usable && len(current) > 0 {
+				candidates = append(candidates, current)
+			}
+			current = nil
+			currentOID = indexOID
+			usable = true
+		}
+		current = append(current, column)
+		usable = usable && notNull
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to discover row key: %w", err)
+	}
+	if usable && len(current) > 0 {
+		candidates = append(candidates, current)
 	}
 
-	query := fmt.Sprintf(`
-		DELETE FROM %s.%s
-		WHERE ctid = $1
-	`, quoteIdentifier(schemaName), quoteIdentifier(tableName))
+	var keyCols []string
+	if len(candidates) > 0 {
+		keyCols = candidates[0]
+	}
+	ptl.mu.Lock()
+	ptl.keyColumns[cacheKey] = keyCols
+	ptl.mu.Unlock()
+	return keyCols, nil
+}
 
-	if _, err := dbConn.Exec(query, rowID); err != nil {
-		return fmt.Errorf("failed to delete row: %w", err)
+func normalizePostgresKeyArgs(key RowKey, args []interface{}) []interface{} {
+	normalized := make([]interface{}, len(args))
+	for idx, arg := range args {
+		if b, ok := arg.([]byte); ok {
+			normalized[idx] = string(b)
+			continue
+		}
+		normalized[idx] = arg
 	}
-	return nil
+	return normalized
 }
 
-func (ptl *PostgresTreeLoader) getDatabaseConnection(databaseName string) (*sql.DB, error) {
+func (ptl *PostgresTreeLoader) getDatabaseConnection(ctx context.Context, databaseName string) (*sql.DB, error) {
 	if databaseName == "" || ptl.connInfo == nil || databaseName == ptl.connInfo.Database {
 		return ptl.db, nil
 	}
 
-	if db, ok := ptl.connections[databaseName]; ok {
+	ptl.mu.Lock()
+	db, ok := ptl.connections[databaseName]
+	ptl.mu.Unlock()
+	if ok {
 		return db, nil
 	}
 
@@ -117,11 +188,18 @@ func (ptl *PostgresTreeLoader) getDatabaseConnection(databaseName string) (*sql.
 		return nil, fmt.Errorf("failed to open connection for database %s: %w", databaseName, err)
 	}
 
-	if err := db.Ping(); err != nil {
+	if err := db.PingContext(ctx); err != nil {
 		db.Close()
 		return nil, fmt.Errorf("failed to ping database %s: %w", databaseName, err)
 	}
 
+	ptl.mu.Lock()
+	defer ptl.mu.Unlock()
+	if existing, ok := ptl.connections[databaseName]; ok {
+		// Another task opened it first.
+		db.Close()
+		return existing, nil
+	}
 	ptl.connections[databaseName] = db
 	return db, nil
 }
@@ -135,10 +213,11 @@ func NewPostgresTreeLoader(db *sql.DB, connInfo *ConnectionInfo) *PostgresTreeLo
 		db:          db,
 		connInfo:    connInfo,
 		connections: make(map[string]*sql.DB),
+		keyColumns:  make(map[string][]string),
 	}
 }
 
-func (ptl *PostgresTreeLoader) LoadTree(serverName string) (*TreeNode, error) {
+func (ptl *PostgresTreeLoader) LoadTree(ctx context.Context, serverName string) (*TreeNode, error) {
 	root := &TreeNode{
 		ID:       "root",
 		Name:     "PostgreSQL Servers",
@@ -156,7 +235,7 @@ func (ptl *PostgresTreeLoader) LoadTree(serverName string) (*TreeNode, error) {
 		Children: make([]*TreeNode, 0),
 	}
 
-	databases, err := ptl.loadDatabases()
+	databases, err := ptl.loadDatabases(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load databases: %w", err)
 	}
@@ -172,7 +251,7 @@ func (ptl *PostgresTreeLoader) LoadTree(serverName string) (*TreeNode, error) {
 	return root, nil
 }
 
-func (ptl *PostgresTreeLoader) loadDatabases() ([]*TreeNode, error) {
+func (ptl *PostgresTreeLoader) loadDatabases(ctx context.Context) ([]*TreeNode, error) {
 	query := `
 		SELECT 
 			datname as database_name,
@@ -183,7 +262,7 @@ func (ptl *PostgresTreeLoader) loadDatabases() ([]*TreeNode, error) {
 		ORDER BY datname
 	`
 
-	rows, err := ptl.db.Query(query)
+	rows, err := ptl.db.QueryContext(ctx, query)
 	if err != nil {
 		return nil, fmt.Errorf("query failed: %w", err)
 	}
@@ -212,7 +291,7 @@ func (ptl *PostgresTreeLoader) loadDatabases() ([]*TreeNode, error) {
 		}
 
 		// Don't load schemas initially - will be loaded on demand
-		// schemas, err := ptl.loadSchemas(dbName)
+		// schemas, err := ptl.loadSchemas(ctx, dbName)
 		// if err != nil {
 		// 	return nil, fmt.Errorf("failed to load schemas for %s: %w", dbName, err)
 		// }
@@ -228,8 +307,8 @@ func (ptl *PostgresTreeLoader) loadDatabases() ([]*TreeNode, error) {
 	return databases, nil
 }
 
-func (ptl *PostgresTreeLoader) loadSchemas(databaseName string) ([]*TreeNode, error) {
-	dbConn, err := ptl.getDatabaseConnection(databaseName)
+func (ptl *PostgresTreeLoader) loadSchemas(ctx context.Context, databaseName string) ([]*TreeNode, error) {
+	dbConn, err := ptl.getDatabaseConnection(ctx, databaseName)
 	if err != nil {
 		return nil, err
 	}
@@ -248,7 +327,7 @@ func (ptl *PostgresTreeLoader) loadSchemas(databaseName string) ([]*TreeNode, er
 		ORDER BY s.schema_name
 	`
 
-	rows, err := dbConn.Query(query)
+	rows, err := dbConn.QueryContext(ctx, query)
 	if err != nil {
 		return nil, fmt.Errorf("query failed: %w", err)
 	}
@@ -276,7 +355,7 @@ func (ptl *PostgresTreeLoader) loadSchemas(databaseName string) ([]*TreeNode, er
 		}
 
 		// Don't load tables initially - will be loaded on demand
-		// tables, err := ptl.loadTables(databaseName, schemaName)
+		// tables, err := ptl.loadTables(ctx, databaseName, schemaName)
 		// if err != nil {
 		// 	return nil, fmt.Errorf("failed to load tables for %s: %w", schemaName, err)
 		// }
@@ -292,8 +371,8 @@ func (ptl *PostgresTreeLoader) loadSchemas(databaseName string) ([]*TreeNode, er
 	return schemas, nil
 }
 
-func (ptl *PostgresTreeLoader) loadTables(databaseName, schemaName string) ([]*TreeNode, error) {
-	dbConn, err := ptl.getDatabaseConnection(databaseName)
+func (ptl *PostgresTreeLoader) loadTables(ctx context.Context, databaseName, schemaName string) ([]*TreeNode, error) {
+	dbConn, err := ptl.getDatabaseConnection(ctx, databaseName)
 	if err != nil {
 		return nil, err
 	}
@@ -308,7 +387,7 @@ func (ptl *PostgresTreeLoader) loadTables(databaseName, schemaName string) ([]*T
 		ORDER BY tablename
 	`
 
-	rows, err := dbConn.Query(query, schemaName)
+	rows, err := dbConn.QueryContext(ctx, query, schemaName)
 	if err != nil {
 		return nil, fmt.Errorf("query failed: %w", err)
 	}
@@ -337,7 +416,7 @@ func (ptl *PostgresTreeLoader) loadTables(databaseName, schemaName string) ([]*T
 		}
 
 		// Don't load columns initially - will be loaded on demand
-		// columns, err := ptl.loadColumns(databaseName, schemaName, tableName)
+		// columns, err := ptl.loadColumns(ctx, databaseName, schemaName, tableName)
 		// if err != nil {
 		// 	return nil, fmt.Errorf("failed to load columns for %s: %w", tableName, err)
 		// }
@@ -353,8 +432,8 @@ func (ptl *PostgresTreeLoader) loadTables(databaseName, schemaName string) ([]*T
 	return tables, nil
 }
 
-func (ptl *PostgresTreeLoader) loadColumns(databaseName, schemaName, tableName string) ([]*TreeNode, error) {
-	dbConn, err := ptl.getDatabaseConnection(databaseName)
+func (ptl *PostgresTreeLoader) loadColumns(ctx context.Context, databaseName, schemaName, tableName string) ([]*TreeNode, error) {
+	dbConn, err := ptl.getDatabaseConnection(ctx, databaseName)
 	if err != nil {
 		return nil, err
 	}
@@ -382,7 +461,7 @@ func (ptl *PostgresTreeLoader) loadColumns(databaseName, schemaName, tableName s
 		ORDER BY ordinal_position
 	`
 
-	rows, err := dbConn.Query(query, schemaName, tableName)
+	rows, err := dbConn.QueryContext(ctx, query, schemaName, tableName)
 	if err != nil {
 		return nil, fmt.Errorf("query failed: %w", err)
 	}
@@ -390,8 +469,8 @@ func (ptl *PostgresTreeLoader) loadColumns(databaseName, schemaName, tableName s
 
 	var columns []*TreeNode
 	for rows.Next() {
-		var columnName, dataType, defaultValue string
-		var isNullable bool
+		var columnName, dataType, isNullable string
+		var defaultValue sql.NullString
 		var isPrimaryKey int
 
 		if err := rows.Scan(&columnName, &dataType, &isNullable, &defaultValue, &isPrimaryKey); err != nil {
@@ -406,8 +485,8 @@ func (ptl *PostgresTreeLoader) loadColumns(databaseName, schemaName, tableName s
 			Level: 4,
 			Metadata: NodeMetadata{
 				DataType:     dataType,
-				IsNullable:   isNullable,
-				DefaultValue: defaultValue,
+				IsNullable:   strings.EqualFold(isNullable, "YES"),
+				DefaultValue: defaultValue.String,
 				PrimaryKey:   isPrimaryKey > 0,
 			},
 			Children: make([]*TreeNode, 0),
@@ -419,9 +498,9 @@ func (ptl *PostgresTreeLoader) loadColumns(databaseName, schemaName, tableName s
 	return columns, nil
 }
 
-func (ptl *PostgresTreeLoader) LoadTreeAsync(serverName string) tea.Cmd {
+func (ptl *PostgresTreeLoader) LoadTreeAsync(ctx context.Context, serverName string) tea.Cmd {
 	return func() tea.Msg {
-		tree, err := ptl.LoadTree(serverName)
+		tree, err := ptl.LoadTree(ctx, serverName)
 		if err != nil {
 			return ErrMsg{err}
 		}
@@ -429,104 +508,92 @@ func (ptl *PostgresTreeLoader) LoadTreeAsync(serverName string) tea.Cmd {
 	}
 }
 
-func (ptl *PostgresTreeLoader) GetTableData(databaseName, schemaName, tableName string, limit, offset int) ([]map[string]interface{}, error) {
-	dbConn, err := ptl.getDatabaseConnection(databaseName)
+func (ptl *PostgresTreeLoader) GetTableData(ctx context.Context, databaseName, schemaName, tableName string, opts TableQueryOptions) (*ResultSet, error) {
+	dbConn, err := ptl.getDatabaseConnection(ctx, databaseName)
 	if err != nil {
 		return nil, err
 	}
 
-	query := fmt.Sprintf(`
-		SELECT ctid AS "__rowid", *
-		FROM %s.%s
-		ORDER BY ctid
-		LIMIT %d OFFSET %d
-	`, quoteIdentifier(schemaName), quoteIdentifier(tableName), limit, offset)
-
-	rows, err := dbConn.Query(query)
+	keyCols, err := ptl.GetRowKeyColumns(ctx, databaseName, schemaName, tableName)
 	if err != nil {
-		return nil, fmt.Errorf("query failed: %w", err)
+		return nil, err
 	}
-	defer rows.Close()
 
-	columns, err := rows.Columns()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get columns: %w", err)
+	selectList := fmt.Sprintf(`ctid AS %s, *`, quoteIdentifier(rowIDColumn))
+	if len(keyCols) > 0 {
+		selectList = "*"
 	}
 
-	var results []map[string]interface{}
-	for rows.Next() {
-		values := make([]interface{}, len(columns))
-		valuePtrs := make([]interface{}, len(columns))
-		for i := range values {
-			valuePtrs[i] = &values[i]
-		}
-
-		if err := rows.Scan(valuePtrs...); err != nil {
-			return nil, fmt.Errorf("scan failed: %w", err)
-		}
-
-		row := make(map[string]interface{})
-		for i, col := range columns {
-			row[col] = values[i]
-		}
-		results = append(results, row)
-	}
-
-	return results, nil
-}
-
-func (ptl *PostgresTreeLoader) ExecuteQuery(queryStr string) ([]map[string]interface{}, error) {
-	queryStr = strings.TrimSpace(queryStr)
-	if queryStr == "" {
-		return nil, nil
-	}
+	where, orderBy, args, offset := postgresDialect.tableQueryClauses(keyCols, nil, opts)
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM %s.%s
+		%s
+		%s
+		LIMIT %d OFFSET %d
+	`, selectList, quoteIdentifier(schemaName), quoteIdentifier(tableName), where, orderBy, opts.Limit, offset)
 
-	rows, err := ptl.db.Query(queryStr)
+	rows, err := dbConn.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("query failed: %w", err)
 	}
 	defer rows.Close()
 
-	columns, err := rows.Columns()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get columns: %w", err)
-	}
+	return scanResultSet(rows, isPostgresBinaryType)
+}
 
-	var results []map[string]interface{}
-	for rows.Next() {
-		values := make([]interface{}, len(columns))
-		valuePtrs := make([]interface{}, len(columns))
-		for i := range values {
-			valuePtrs[i] = &values[i]
-		}
+func (ptl *PostgresTreeLoader) BeginTransaction(ctx context.Context) (*QueryTransaction, error) {
+	return beginQueryTransaction(ctx, ptl.db)
+}
 
-		if err := rows.Scan(valuePtrs...); err != nil {
-			return nil, fmt.Errorf("scan failed: %w", err)
-		}
+func (ptl *PostgresTreeLoader) OpenSession(ctx context.Context, tx *QueryTransaction) (*QuerySession, error) {
+	return openQuerySession(ctx, ptl.db, tx)
+}
 
-		row := make(map[string]interface{})
-		for i, col := range columns {
-			row[col] = values[i]
+// ExecuteStatement collects the notices the server raises while the statement
+// runs (RAISE NOTICE, "table does not exist, skipping", ...) by installing a
+// handler on the session's connection for the duration of the call.
+func (ptl *PostgresTreeLoader) ExecuteStatement(ctx context.Context, session *QuerySession, statement string, args ...interface{}) StatementResult {
+	var notices []string
+	setNoticeHandler(session.conn, func(notice *pq.Error) {
+		notices = append(notices, fmt.Sprintf("%s: %s", notice.Severity, notice.Message))
+	})
+	defer setNoticeHandler(session.conn, nil)
+
+	result := executeStatement(ctx, session.queryer, ConnectionPostgres, statement, isPostgresBinaryType, args...)
+	result.Notices = notices
+	return result
+}
+
+func setNoticeHandler(conn *sql.Conn, handler func(*pq.Error)) {
+	conn.Raw(func(driverConn interface{}) error {
+		if dc, ok := driverConn.(driver.Conn); ok {
+			pq.SetNoticeHandler(dc, handler)
 		}
-		results = append(results, row)
-	}
+		return nil
+	})
+}
 
-	return results, nil
+// lib/pq returns the text form of most types as []byte; only bytea is binary.
+func isPostgresBinaryType(typeName string) bool {
+	return typeName == "BYTEA"
 }
 
-func (ptl *PostgresTreeLoader) GetTableRowCount(databaseName, schemaName, tableName string) (int64, error) {
-	dbConn, err := ptl.getDatabaseConnection(databaseName)
+func (ptl *PostgresTreeLoader) GetTableRowCount(ctx context.Context, databaseName, schemaName, tableName string, view TableView) (int64, error) {
+	dbConn, err := ptl.getDatabaseConnection(ctx, databaseName)
 	if err != nil {
 		return 0, err
 	}
 
+	conditions, args := postgresDialect.filterConditions(view, 1)
 	query := fmt.Sprintf(`
 		SELECT COUNT(*) 
 		FROM %s.%s
-	`, quoteIdentifier(schemaName), quoteIdentifier(tableName))
+		%s
+	`, quoteIdentifier(schemaName), quoteIdentifier(tableName), whereClause(conditions))
 
 	var count int64
-	if err := dbConn.QueryRow(query).Scan(&count); err != nil {
+	if err := dbConn.QueryRowContext(ctx, query, args...).Scan(&count); err != nil {
 		return 0, fmt.Errorf("query failed: %w", err)
 	}
 
@@ -549,7 +616,7 @@ func (ptl *PostgresTreeLoader) buildPathFromParent(node *TreeNode) string {
 	return strings.Join(parts, ".")
 }
 
-func (ptl *PostgresTreeLoader) LoadChildren(node *TreeNode) error {
+func (ptl *PostgresTreeLoader) LoadChildren(ctx context.Context, node *TreeNode) error {
 	if node == nil || len(node.Children) > 0 {
 		return nil
 	}
@@ -559,7 +626,7 @@ func (ptl *PostgresTreeLoader) LoadChildren(node *TreeNode) error {
 	switch node.Type {
 	case NodeDatabase:
 		if len(parts) >= 1 {
-			schemas, err := ptl.loadSchemas(parts[0])
+			schemas, err := ptl.loadSchemas(ctx, parts[0])
 			if err != nil {
 				return err
 			}
@@ -570,7 +637,7 @@ func (ptl *PostgresTreeLoader) LoadChildren(node *TreeNode) error {
 		}
 	case NodeSchema:
 		if len(parts) >= 2 {
-			tables, err := ptl.loadTables(parts[0], parts[1])
+			tables, err := ptl.loadTables(ctx, parts[0], parts[1])
 			if err != nil {
 				return err
 			}
@@ -581,7 +648,7 @@ func (ptl *PostgresTreeLoader) LoadChildren(node *TreeNode) error {
 		}
 	case NodeTable:
 		if len(parts) >= 3 {
-			columns, err := ptl.loadColumns(parts[0], parts[1], parts[2])
+			columns, err := ptl.loadColumns(ctx, parts[0], parts[1], parts[2])
 			if err != nil {
 				return err
 			}
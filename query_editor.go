@@ -1,37 +1,133 @@
 package main
 
 import (
+	"fmt"
+	"strconv"
 	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+
+	"windsurf-tui/sqlformat"
+	"windsurf-tui/sqllex"
 )
 
+var editorDialects = []ConnectionType{ConnectionPostgres, ConnectionMySQL, ConnectionSQLite}
+
 type QueryEditor struct {
-	value  string
-	cursor int
-	width  int
-	height int
+	buffer     *TextBuffer
+	width      int
+	height     int
+	top        int
+	dialect    ConnectionType
+	catalog    *SchemaCatalog
+	completion *completionPopup
 }
 
 func NewQueryEditor() *QueryEditor {
 	return &QueryEditor{
-		value:  "",
-		cursor: 0,
-		width:  80,
-		height: 20,
+		buffer:  NewTextBuffer(true),
+		width:   80,
+		height:  20,
+		dialect: ConnectionPostgres,
 	}
 }
 
-func (qe *QueryEditor) SetValue(value string) {
-	qe.value = value
-	if qe.cursor > len(value) {
-		qe.cursor = len(value)
+// SetDialect selects the keywords and placeholder syntax used to highlight
+// the query; it follows the connection but can be switched with Ctrl+L.
+func (qe *QueryEditor) SetDialect(dialect ConnectionType) {
+	qe.dialect = dialect
+}
+
+// SetHeight limits the number of query lines shown at once; the query view
+// shrinks the editor to make room for results.
+func (qe *QueryEditor) SetHeight(height int) {
+	if height < 3 {
+		height = 3
+	}
+	qe.height = height
+}
+
+func (qe *QueryEditor) cycleDialect() {
+	idx := 0
+	for i, dialect := range editorDialects {
+		if dialect == qe.dialect {
+			idx = i
+			break
+		}
 	}
+	qe.dialect = editorDialects[(idx+1)%len(editorDialects)]
+}
+
+// SetValue replaces the query as one undoable edit, so a recalled or
+// externally edited query can be taken back with Ctrl+Z.
+func (qe *QueryEditor) SetValue(value string) {
+	qe.buffer.SetText(value)
 }
 
 func (qe *QueryEditor) GetValue() string {
-	return qe.value
+	return qe.buffer.Text()
+}
+
+func (qe *QueryEditor) SelectedText() string {
+	return qe.buffer.SelectedText()
+}
+
+// CursorByte is the cursor as a byte offset into GetValue().
+func (qe *QueryEditor) CursorByte() int {
+	return qe.buffer.CursorByte()
+}
+
+func (qe *QueryEditor) CursorToEnd() {
+	qe.buffer.SetCursor(qe.buffer.Len())
+}
+
+// InsertText types text at the cursor, replacing the selection.
+func (qe *QueryEditor) InsertText(text string) {
+	qe.buffer.Insert(text)
+	qe.completion = nil
+}
+
+// CurrentStatement is the selected text or, without a selection, the
+// statement under the cursor.
+func (qe *QueryEditor) CurrentStatement() string {
+	if selected := qe.buffer.SelectedText(); strings.TrimSpace(selected) != "" {
+		return selected
+	}
+	statement, _ := qe.StatementAtCursor()
+	return statement.Text
+}
+
+// StatementAtCursor is the statement around the cursor, delimited by
+// semicolons or blank lines.
+func (qe *QueryEditor) StatementAtCursor() (sqlStatement, bool) {
+	return statementAt(qe.buffer.Text(), qe.buffer.CursorByte(), qe.dialect)
+}
+
+// FormatStatement reflows the selection, or else the statement under the
+// cursor, as one undoable edit. It reports false when there is nothing to
+// format.
+func (qe *QueryEditor) FormatStatement() bool {
+	start, end, ok := qe.buffer.Selection()
+	if !ok {
+		statement, found := qe.StatementAtCursor()
+		if !found {
+			return false
+		}
+		start, end = qe.buffer.RuneOffset(statement.Start), qe.buffer.RuneOffset(statement.End)
+	}
+	text := string([]rune(qe.buffer.Text())[start:end])
+	qe.buffer.ReplaceRange(start, end, sqlformat.Format(text, sqllex.Dialect(qe.dialect)))
+	qe.completion = nil
+	return true
+}
+
+func (qe *QueryEditor) OnFirstLine() bool {
+	return qe.buffer.OnFirstLine()
+}
+
+func (qe *QueryEditor) OnLastLine() bool {
+	return qe.buffer.OnLastLine()
 }
 
 func (qe *QueryEditor) Init() tea.Cmd {
@@ -41,60 +137,45 @@ func (qe *QueryEditor) Init() tea.Cmd {
 func (qe *QueryEditor) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		switch msg.Type {
-		case tea.KeyLeft:
-			if qe.cursor > 0 {
-				qe.cursor--
-			}
-		case tea.KeyRight:
-			if qe.cursor < len(qe.value) {
-				qe.cursor++
-			}
-		case tea.KeyUp:
-			qe.moveCursorUp()
-		case tea.KeyDown:
-			qe.moveCursorDown()
-		case tea.KeyBackspace:
-			if qe.cursor > 0 {
-				qe.value = qe.value[:qe.cursor-1] + qe.value[qe.cursor:]
-				qe.cursor--
-			}
-		case tea.KeyDelete:
-			if qe.cursor < len(qe.value) {
-				qe.value = qe.value[:qe.cursor] + qe.value[qe.cursor+1:]
+		if msg.Paste {
+			// A bracketed paste arrives as one message, so its newlines and
+			// tabs are typed as text instead of running the query or
+			// opening completion.
+			qe.buffer.Insert(string(msg.Runes))
+			qe.completion = nil
+			return qe, nil
+		}
+		if qe.completion != nil {
+			if handled, cmd := qe.updateCompletion(msg); handled {
+				return qe, cmd
 			}
+		}
+
+		switch msg.Type {
+		case tea.KeyTab:
+			return qe, qe.openCompletion()
 		case tea.KeyEnter:
 			// Enter executes the query
 			return qe, func() tea.Msg {
-				return ExecuteQueryMsg{query: qe.value}
+				return ExecuteQueryMsg{query: qe.buffer.Text()}
 			}
 		case tea.KeyCtrlJ:
-			// Ctrl+J adds a newline
-			qe.value += "\n"
-			qe.cursor++
-		case tea.KeyHome:
-			qe.moveToLineStart()
-		case tea.KeyEnd:
-			qe.moveToLineEnd()
-		case tea.KeyCtrlV:
-			return qe, qe.paste()
+			// Ctrl+J breaks the line at the cursor
+			qe.buffer.InsertNewline()
+		case tea.KeyCtrlL:
+			qe.cycleDialect()
 		default:
-			if len(msg.Runes) > 0 {
-				qe.value = qe.value[:qe.cursor] + string(msg.Runes) + qe.value[qe.cursor:]
-				qe.cursor += len(msg.Runes)
-			}
+			qe.buffer.HandleKey(msg)
+		}
+
+		if qe.completion != nil {
+			return qe, qe.refreshCompletion(true)
 		}
 	}
 
 	return qe, nil
 }
 
-func (qe *QueryEditor) paste() tea.Cmd {
-	return func() tea.Msg {
-		return nil
-	}
-}
-
 func (qe *QueryEditor) View() string {
 	border := lipgloss.NewStyle().
 		Border(lipgloss.RoundedBorder()).
@@ -103,85 +184,39 @@ func (qe *QueryEditor) View() string {
 
 	helpText := lipgloss.NewStyle().
 		Foreground(lipgloss.Color("#808080")).
-		Render("Type your SQL query here. Press Enter to execute, Ctrl+J for newline, Esc to cancel.")
-
-	editor := border.Render(qe.value)
-
-	if qe.value == "" {
-		editor = border.Render("")
+		Render(fmt.Sprintf("Type your SQL query here. Press Enter to execute, Ctrl+S to run the current statement, Ctrl+J for newline, Tab to complete, Ctrl+Z/Ctrl+Y to undo/redo, Esc to cancel. Dialect: %s (Ctrl+L)",
+			driverLabels[qe.dialect]))
+
+	selStart, selEnd, _ := qe.buffer.Selection()
+	lines := highlightSQL(qe.buffer.Text(), qe.dialect, qe.buffer.CursorByte(),
+		qe.buffer.ByteOffset(selStart), qe.buffer.ByteOffset(selEnd))
+	current := qe.buffer.CursorLine()
+	if current < qe.top {
+		qe.top = current
 	}
-
-	return helpText + "\n\n" + editor
-}
-
-func (qe *QueryEditor) moveCursorUp() {
-	lines := strings.Split(qe.value, "\n")
-	if len(lines) > 1 {
-		currentLine := qe.getCurrentLine()
-		posInLine := qe.getPosInCurrentLine()
-
-		if currentLine > 0 {
-			previousLineLen := len(lines[currentLine-1])
-			if posInLine > previousLineLen {
-				qe.cursor -= posInLine - previousLineLen
-			}
-			qe.cursor -= len(lines[currentLine]) + 1
-		}
+	if current >= qe.top+qe.height {
+		qe.top = current - qe.height + 1
 	}
-}
-
-func (qe *QueryEditor) moveCursorDown() {
-	lines := strings.Split(qe.value, "\n")
-	if len(lines) > 1 {
-		currentLine := qe.getCurrentLine()
-		posInLine := qe.getPosInCurrentLine()
-
-		if currentLine < len(lines)-1 {
-			qe.cursor += len(lines[currentLine]) + 1
-			nextLineLen := len(lines[currentLine+1])
-			if posInLine > nextLineLen {
-				qe.cursor -= posInLine - nextLineLen
-			}
-		}
+	end := qe.top + qe.height
+	if end > len(lines) {
+		end = len(lines)
 	}
-}
 
-func (qe *QueryEditor) moveToLineStart() {
-	lines := strings.Split(qe.value, "\n")
-	currentLine := qe.getCurrentLine()
-
-	if currentLine == 0 {
-		qe.cursor = 0
-	} else {
-		for i := 0; i < currentLine; i++ {
-			qe.cursor -= len(lines[i]) + 1
+	gutterWidth := len(strconv.Itoa(len(lines)))
+	gutter := lipgloss.NewStyle().Foreground(lipgloss.Color("#5A5A5A"))
+	activeGutter := lipgloss.NewStyle().Foreground(lipgloss.Color("#C6C6C6"))
+	numbered := make([]string, 0, end-qe.top)
+	for idx := qe.top; idx < end; idx++ {
+		style := gutter
+		if idx == current {
+			style = activeGutter
 		}
+		numbered = append(numbered, style.Render(fmt.Sprintf("%*d │ ", gutterWidth, idx+1))+lines[idx])
 	}
-}
 
-func (qe *QueryEditor) moveToLineEnd() {
-	lines := strings.Split(qe.value, "\n")
-	currentLine := qe.getCurrentLine()
-
-	if currentLine < len(lines)-1 {
-		for i := currentLine; i < len(lines)-1; i++ {
-			qe.cursor += len(lines[i]) + 1
-		}
-	} else {
-		qe.cursor = len(qe.value)
-	}
-}
-
-func (qe *QueryEditor) getCurrentLine() int {
-	lines := strings.Split(qe.value[:qe.cursor], "\n")
-	return len(lines) - 1
-}
-
-func (qe *QueryEditor) getPosInCurrentLine() int {
-	if qe.cursor == 0 {
-		return 0
+	view := helpText + "\n\n" + border.Render(strings.Join(numbered, "\n"))
+	if qe.completion != nil {
+		view += "\n" + qe.renderCompletion()
 	}
-
-	lines := strings.Split(qe.value[:qe.cursor], "\n")
-	return len(lines[len(lines)-1])
+	return view
 }
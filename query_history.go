@@ -0,0 +1,183 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	// historyMemoryLimit caps the entries kept per connection in memory.
+	historyMemoryLimit = 1000
+	// historyFileLimit is the number of lines after which the file is
+	// rewritten with only the most recent entries.
+	historyFileLimit = 10000
+)
+
+type HistoryEntry struct {
+	Connection string            `json:"connection"`
+	Query      string            `json:"query"`
+	ExecutedAt time.Time         `json:"executed_at"`
+	Duration   time.Duration     `json:"duration_ns"`
+	Rows       int               `json:"rows"`
+	Error      string            `json:"error,omitempty"`
+	Params     map[string]string `json:"params,omitempty"`
+}
+
+// QueryHistory keeps every executed statement in an append-only NDJSON file
+// shared by all connections; only the current connection's entries are
+// loaded. Entries are ordered oldest first.
+type QueryHistory struct {
+	path       string
+	connection string
+	entries    []HistoryEntry
+}
+
+func defaultHistoryPath() string {
+	return filepath.Join(os.Getenv("HOME"), ".windsurf-tui", "history.jsonl")
+}
+
+func NewQueryHistory(path string) *QueryHistory {
+	return &QueryHistory{path: path}
+}
+
+// Load reads the entries of a connection. A missing file is an empty history.
+func (qh *QueryHistory) Load(connection string) error {
+	qh.connection = connection
+	qh.entries = nil
+
+	file, err := os.Open(qh.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open history: %w", err)
+	}
+	defer file.Close()
+
+	var all []HistoryEntry
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var entry HistoryEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			continue
+		}
+		all = append(all, entry)
+		if entry.Connection == connection {
+			qh.entries = append(qh.entries, entry)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read history: %w", err)
+	}
+
+	if len(qh.entries) > historyMemoryLimit {
+		qh.entries = qh.entries[len(qh.entries)-historyMemoryLimit:]
+	}
+	if len(all) > historyFileLimit {
+		return qh.rewrite(all[len(all)-historyFileLimit/2:])
+	}
+	return nil
+}
+
+func (qh *QueryHistory) rewrite(entries []HistoryEntry) error {
+	tmp := qh.path + ".tmp"
+	file, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to compact history: %w", err)
+	}
+	encoder := json.NewEncoder(file)
+	for _, entry := range entries {
+		if err := encoder.Encode(entry); err != nil {
+			file.Close()
+			return fmt.Errorf("failed to compact history: %w", err)
+		}
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to compact history: %w", err)
+	}
+	return os.Rename(tmp, qh.path)
+}
+
+// Add records an executed statement for the current connection and appends
+// it to the history file.
+func (qh *QueryHistory) Add(entry HistoryEntry) error {
+	entry.Query = strings.TrimSpace(entry.Query)
+	if entry.Query == "" {
+		return nil
+	}
+	entry.Connection = qh.connection
+
+	qh.entries = append(qh.entries, entry)
+	if len(qh.entries) > historyMemoryLimit {
+		qh.entries = qh.entries[1:]
+	}
+
+	if err := os.MkdirAll(filepath.Dir(qh.path), 0755); err != nil {
+		return fmt.Errorf("failed to create history directory: %w", err)
+	}
+	file, err := os.OpenFile(qh.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open history: %w", err)
+	}
+	if err := json.NewEncoder(file).Encode(entry); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write history: %w", err)
+	}
+	return file.Close()
+}
+
+func (qh *QueryHistory) Len() int {
+	return len(qh.entries)
+}
+
+// Entry returns the n-th most recent entry, 0 being the newest.
+func (qh *QueryHistory) Entry(n int) (HistoryEntry, bool) {
+	if n < 0 || n >= len(qh.entries) {
+		return HistoryEntry{}, false
+	}
+	return qh.entries[len(qh.entries)-1-n], true
+}
+
+// Search returns entries whose query contains every word of term, newest
+// first, skipping repeats of the same query.
+func (qh *QueryHistory) Search(term string) []HistoryEntry {
+	words := strings.Fields(strings.ToLower(term))
+	seen := make(map[string]bool)
+	var matches []HistoryEntry
+	for idx := len(qh.entries) - 1; idx >= 0; idx-- {
+		entry := qh.entries[idx]
+		if seen[entry.Query] {
+			continue
+		}
+		query := strings.ToLower(entry.Query)
+		matched := true
+		for _, word := range words {
+			if !strings.Contains(query, word) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			seen[entry.Query] = true
+			matches = append(matches, entry)
+		}
+	}
+	return matches
+}
+
+// LastParams returns the parameter values of the most recent run of query.
+func (qh *QueryHistory) LastParams(query string) map[string]string {
+	query = strings.TrimSpace(query)
+	for idx := len(qh.entries) - 1; idx >= 0; idx-- {
+		if qh.entries[idx].Query == query && qh.entries[idx].Params != nil {
+			return qh.entries[idx].Params
+		}
+	}
+	return nil
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writeHistory writes one NDJSON line per entry, plus any raw lines given.
+func writeHistory(t *testing.T, path string, entries []HistoryEntry, raw ...string) {
+	t.Helper()
+	var b strings.Builder
+	for _, entry := range entries {
+		line, err := json.Marshal(entry)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b.Write(line)
+		b.WriteByte('\n')
+	}
+	for _, line := range raw {
+		b.WriteString(line + "\n")
+	}
+	if err := os.WriteFile(path, []byte(b.String()), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func countLines(t *testing.T, path string) int {
+	t.Helper()
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	lines := 0
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		lines++
+	}
+	return lines
+}
+
+func historyEntries(connection string, n int) []HistoryEntry {
+	entries := make([]HistoryEntry, n)
+	for idx := range entries {
+		entries[idx] = HistoryEntry{Connection: connection, Query: fmt.Sprintf("select %d", idx)}
+	}
+	return entries
+}
+
+func TestQueryHistoryLoad(t *testing.T) {
+	tests := []struct {
+		name       string
+		entries    []HistoryEntry
+		raw        []string
+		missing    bool
+		wantLen    int
+		wantNewest string
+		wantOldest string
+		wantLines  int
+	}{
+		{
+			name:    "missing file is an empty history",
+			missing: true,
+		},
+		{
+			name:       "only the connection's entries are loaded",
+			entries:    append(historyEntries("a", 2), HistoryEntry{Connection: "b", Query: "other"}),
+			wantLen:    2,
+			wantNewest: "select 1",
+			wantOldest: "select 0",
+			wantLines:  3,
+		},
+		{
+			name:       "corrupt lines are skipped",
+			entries:    historyEntries("a", 1),
+			raw:        []string{"{not json", `{"connection":"a","query":"select 9"}`},
+			wantLen:    2,
+			wantNewest: "select 9",
+			wantOldest: "select 0",
+			wantLines:  3,
+		},
+		{
+			name:       "memory keeps the most recent entries",
+			entries:    historyEntries("a", historyMemoryLimit+5),
+			wantLen:    historyMemoryLimit,
+			wantNewest: fmt.Sprintf("select %d", historyMemoryLimit+4),
+			wantOldest: "select 5",
+			wantLines:  historyMemoryLimit + 5,
+		},
+		{
+			name:       "a file over the limit is compacted to its newest half",
+			entries:    append(historyEntries("b", historyFileLimit), historyEntries("a", 3)...),
+			wantLen:    3,
+			wantNewest: "select 2",
+			wantOldest: "select 0",
+			wantLines:  historyFileLimit / 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "history.jsonl")
+			if !tt.missing {
+				writeHistory(t, path, tt.entries, tt.raw...)
+			}
+
+			qh := NewQueryHistory(path)
+			if err := qh.Load("a"); err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			if qh.Len() != tt.wantLen {
+				t.Fatalf("Len() = %d, want %d", qh.Len(), tt.wantLen)
+			}
+			if tt.wantLen > 0 {
+				newest, _ := qh.Entry(0)
+				oldest, _ := qh.Entry(qh.Len() - 1)
+				if newest.Query != tt.wantNewest || oldest.Query != tt.wantOldest {
+					t.Errorf("entries run %q..%q, want %q..%q", oldest.Query, newest.Query, tt.wantOldest, tt.wantNewest)
+				}
+			}
+			if !tt.missing {
+				if lines := countLines(t, path); lines != tt.wantLines {
+					t.Errorf("file has %d lines, want %d", lines, tt.wantLines)
+				}
+			}
+		})
+	}
+}
+
+func TestQueryHistoryAddAndSearch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "history.jsonl")
+	qh := NewQueryHistory(path)
+	if err := qh.Load("a"); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	for _, query := range []string{"SELECT * FROM users", "  ", "select id from orders", "SELECT * FROM users"} {
+		if err := qh.Add(HistoryEntry{Query: query}); err != nil {
+			t.Fatalf("Add(%q) error = %v", query, err)
+		}
+	}
+
+	reloaded := NewQueryHistory(path)
+	if err := reloaded.Load("a"); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if reloaded.Len() != 3 {
+		t.Fatalf("reloaded Len() = %d, want 3", reloaded.Len())
+	}
+
+	tests := []struct {
+		term string
+		want []string
+	}{
+		{term: "", want: []string{"SELECT * FROM users", "select id from orders"}},
+		{term: "FROM select", want: []string{"SELECT * FROM users", "select id from orders"}},
+		{term: "orders id", want: []string{"select id from orders"}},
+		{term: "missing", want: nil},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, entry := range reloaded.Search(tt.term) {
+			got = append(got, entry.Query)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Search(%q) = %q, want %q", tt.term, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,262 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"windsurf-tui/sqllex"
+)
+
+// sqlPlaceholder is a bind variable in the query text. Key is how the prompt
+// names it: $1, :name, or a bare ? (numbered ?1, ?2... across the script).
+type sqlPlaceholder struct {
+	Key   string
+	Start int
+	End   int
+}
+
+// QueryParams holds the values typed for a query's placeholders. inputs
+// keeps the text as typed, for the history; values the converted values.
+type QueryParams struct {
+	dialect ConnectionType
+	inputs  map[string]string
+	values  map[string]interface{}
+}
+
+// ParamsEnteredMsg carries the values confirmed in the parameter prompt.
+type ParamsEnteredMsg struct {
+	query  string
+	inputs map[string]string
+}
+
+// sqlPlaceholders finds $1, ? and :name placeholders outside strings and
+// comments. PostgreSQL uses ? as a jsonb operator, so it is not a
+// placeholder there; :: casts and array slices are not named parameters.
+func sqlPlaceholders(text string, dialect ConnectionType) []sqlPlaceholder {
+	tokens := tokenizeSQL(text, dialect)
+	var found []sqlPlaceholder
+	for idx, tok := range tokens {
+		switch {
+		case tok.Kind == sqllex.Param:
+			found = append(found, sqlPlaceholder{Key: tok.Text, Start: tok.Start, End: tok.End})
+		case tok.Kind == sqllex.Punct && tok.Text == "?" && dialect != ConnectionPostgres:
+			found = append(found, sqlPlaceholder{Key: "?", Start: tok.Start, End: tok.End})
+		case tok.Kind == sqllex.Punct && tok.Text == ":" && isNamedParam(tokens, idx):
+			name := tokens[idx+1]
+			found = append(found, sqlPlaceholder{Key: ":" + name.Text, Start: tok.Start, End: name.End})
+		}
+	}
+	return found
+}
+
+func isNamedParam(tokens []sqllex.Token, idx int) bool {
+	if idx+1 >= len(tokens) || tokens[idx+1].Kind != sqllex.Word || tokens[idx+1].Start != tokens[idx].End {
+		return false
+	}
+	if idx == 0 {
+		return true
+	}
+	prev := tokens[idx-1]
+	switch prev.Kind {
+	case sqllex.Space, sqllex.Comment:
+		return true
+	case sqllex.Punct:
+		return prev.Text != ":" && prev.Text != "]" && prev.Text != ")"
+	}
+	return false
+}
+
+// queryParamKeys lists the distinct placeholders of a script in order of
+// first use.
+func queryParamKeys(script string, dialect ConnectionType) []string {
+	var keys []string
+	seen := make(map[string]bool)
+	marks := 0
+	for _, ph := range sqlPlaceholders(script, dialect) {
+		key := ph.Key
+		if key == "?" {
+			marks++
+			key = fmt.Sprintf("?%d", marks)
+		}
+		if !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// bind rewrites the placeholders of one statement to the driver's positional
+// form ($n for PostgreSQL, ? elsewhere) and returns the values in order.
+// marks counts the question marks of the earlier statements of the script.
+// A nil receiver leaves the statement untouched.
+func (qp *QueryParams) bind(statement string, marks *int) (string, []interface{}) {
+	if qp == nil {
+		return statement, nil
+	}
+	placeholders := sqlPlaceholders(statement, qp.dialect)
+	if len(placeholders) == 0 {
+		return statement, nil
+	}
+
+	var sb strings.Builder
+	var args []interface{}
+	positions := make(map[string]int)
+	last := 0
+	for _, ph := range placeholders {
+		key := ph.Key
+		if key == "?" {
+			*marks++
+			key = fmt.Sprintf("?%d", *marks)
+		}
+		sb.WriteString(statement[last:ph.Start])
+		last = ph.End
+
+		if qp.dialect != ConnectionPostgres {
+			sb.WriteString("?")
+			args = append(args, qp.values[key])
+			continue
+		}
+		pos, ok := positions[key]
+		if !ok {
+			args = append(args, qp.values[key])
+			pos = len(args)
+			positions[key] = pos
+		}
+		fmt.Fprintf(&sb, "$%d", pos)
+	}
+	sb.WriteString(statement[last:])
+	return sb.String(), args
+}
+
+// ParamPrompt asks for the value of each placeholder, of a query before it
+// runs or of a snippet before it is inserted; submit turns the values into
+// the message sent on confirmation.
+type ParamPrompt struct {
+	title  string
+	keys   []string
+	inputs []*TextInput
+	focus  int
+	closed bool
+	submit func(inputs map[string]string) tea.Msg
+}
+
+// NewParamPrompt pre-fills the fields with values, such as the ones last
+// used for the same query.
+func NewParamPrompt(title string, keys []string, values map[string]string, width int, submit func(inputs map[string]string) tea.Msg) *ParamPrompt {
+	pp := &ParamPrompt{title: title, keys: keys, submit: submit}
+	for _, key := range keys {
+		input := NewTextInput()
+		input.SetWidth(min(width, 60))
+		input.SetPlaceholder("vazio = NULL")
+		input.SetValue(values[key])
+		pp.inputs = append(pp.inputs, input)
+	}
+	return pp
+}
+
+// SetHint replaces the text shown in empty fields.
+func (pp *ParamPrompt) SetHint(hint string) {
+	for _, input := range pp.inputs {
+		input.SetPlaceholder(hint)
+	}
+}
+
+func (pp *ParamPrompt) IsClosed() bool {
+	return pp.closed
+}
+
+func (pp *ParamPrompt) Init() tea.Cmd { return nil }
+
+// Update moves between fields with Tab/↑/↓; Enter advances and, on the last
+// field, confirms.
+func (pp *ParamPrompt) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return pp, nil
+	}
+
+	switch keyMsg.Type {
+	case tea.KeyEscape:
+		pp.closed = true
+		return pp, nil
+	case tea.KeyUp, tea.KeyShiftTab:
+		if pp.focus > 0 {
+			pp.focus--
+		}
+		return pp, nil
+	case tea.KeyDown, tea.KeyTab:
+		if pp.focus < len(pp.inputs)-1 {
+			pp.focus++
+		}
+		return pp, nil
+	case tea.KeyEnter:
+		if pp.focus < len(pp.inputs)-1 {
+			pp.focus++
+			return pp, nil
+		}
+		pp.closed = true
+		inputs := make(map[string]string, len(pp.keys))
+		for idx, key := range pp.keys {
+			inputs[key] = pp.inputs[idx].Value()
+		}
+		return pp, func() tea.Msg { return pp.submit(inputs) }
+	}
+
+	pp.inputs[pp.focus].HandleKey(keyMsg)
+	return pp, nil
+}
+
+func (pp *ParamPrompt) View() string {
+	lines := []string{lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#FFD700")).
+		Bold(true).
+		Render(fmt.Sprintf("%s (%d)", pp.title, len(pp.keys)))}
+	for idx, key := range pp.keys {
+		prompt := "  " + key
+		if idx == pp.focus {
+			prompt = "▶ " + key
+		}
+		lines = append(lines, pp.inputs[idx].View(prompt))
+	}
+	lines = append(lines, lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#888888")).
+		Italic(true).
+		Render("Tab/↑/↓ Navega | Enter Próximo/Confirma | Esc Cancela"))
+	return strings.Join(lines, "\n")
+}
+
+// queryParams converts the values typed in the prompt the same way cell
+// edits are converted.
+func (app *XTreeGoldApp) queryParams(inputs map[string]string) *QueryParams {
+	params := &QueryParams{
+		dialect: app.currentConnection.Type,
+		inputs:  inputs,
+		values:  make(map[string]interface{}, len(inputs)),
+	}
+	for key, input := range inputs {
+		params.values[key] = app.convertInputValue(input, nil)
+	}
+	return params
+}
+
+// promptParams opens the parameter prompt when query has placeholders and
+// reports whether it did.
+func (app *XTreeGoldApp) promptParams(query string) bool {
+	if app.currentConnection == nil {
+		return false
+	}
+	keys := queryParamKeys(query, app.currentConnection.Type)
+	if len(keys) == 0 {
+		return false
+	}
+	app.paramPrompt = NewParamPrompt("🔣 Parâmetros da consulta", keys, app.history.LastParams(query), max(app.width-8, 30),
+		func(inputs map[string]string) tea.Msg {
+			return ParamsEnteredMsg{query: query, inputs: inputs}
+		})
+	app.focusMode = FocusQuery
+	return true
+}
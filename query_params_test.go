@@ -0,0 +1,131 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestQueryParamKeys(t *testing.T) {
+	tests := []struct {
+		name    string
+		script  string
+		dialect ConnectionType
+		want    []string
+	}{
+		{
+			name:    "numbered and named placeholders in order of first use",
+			script:  "SELECT * FROM t WHERE a = :name AND b = $1 OR c = :name",
+			dialect: ConnectionPostgres,
+			want:    []string{":name", "$1"},
+		},
+		{
+			name:    "question marks are numbered across statements",
+			script:  "SELECT ? ; UPDATE t SET a = ? WHERE b = ?",
+			dialect: ConnectionMySQL,
+			want:    []string{"?1", "?2", "?3"},
+		},
+		{
+			name:    "question mark is a jsonb operator in postgres",
+			script:  "SELECT doc ? 'key' FROM t",
+			dialect: ConnectionPostgres,
+		},
+		{
+			name:    "strings, comments, casts and slices are skipped",
+			script:  "SELECT ':x', a::text, arr[1:2] -- :y ?\nFROM t /* ? */ WHERE id = :id",
+			dialect: ConnectionSQLite,
+			want:    []string{":id"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := queryParamKeys(tt.script, tt.dialect); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("queryParamKeys() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueryParamsBind(t *testing.T) {
+	values := map[string]interface{}{
+		":name": "ana",
+		":id":   int64(7),
+		"$1":    int64(1),
+		"?1":    "first",
+		"?2":    "second",
+		"?3":    "third",
+	}
+
+	tests := []struct {
+		name      string
+		dialect   ConnectionType
+		statement string
+		marks     int
+		want      string
+		wantArgs  []interface{}
+		wantMarks int
+	}{
+		{
+			name:      "postgres reuses the position of a repeated name",
+			dialect:   ConnectionPostgres,
+			statement: "SELECT * FROM t WHERE a = :name OR b = :name AND c = $1",
+			want:      "SELECT * FROM t WHERE a = $1 OR b = $1 AND c = $2",
+			wantArgs:  []interface{}{"ana", int64(1)},
+		},
+		{
+			name:      "mysql binds a repeated name once per use",
+			dialect:   ConnectionMySQL,
+			statement: "SELECT * FROM t WHERE a = :name OR b = :name AND id = :id",
+			want:      "SELECT * FROM t WHERE a = ? OR b = ? AND id = ?",
+			wantArgs:  []interface{}{"ana", "ana", int64(7)},
+		},
+		{
+			name:      "question marks continue from earlier statements",
+			dialect:   ConnectionSQLite,
+			statement: "UPDATE t SET a = ? WHERE b = ?",
+			marks:     1,
+			want:      "UPDATE t SET a = ? WHERE b = ?",
+			wantArgs:  []interface{}{"second", "third"},
+			wantMarks: 3,
+		},
+		{
+			name:      "text inside strings is left alone",
+			dialect:   ConnectionSQLite,
+			statement: "SELECT ':name', :id",
+			want:      "SELECT ':name', ?",
+			wantArgs:  []interface{}{int64(7)},
+		},
+		{
+			name:      "no placeholders",
+			dialect:   ConnectionPostgres,
+			statement: "SELECT 1",
+			want:      "SELECT 1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			qp := &QueryParams{dialect: tt.dialect, values: values}
+			marks := tt.marks
+			got, args := qp.bind(tt.statement, &marks)
+			if got != tt.want {
+				t.Errorf("bind() = %q, want %q", got, tt.want)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("bind() args = %#v, want %#v", args, tt.wantArgs)
+			}
+			if tt.wantMarks != 0 && marks != tt.wantMarks {
+				t.Errorf("marks = %d, want %d", marks, tt.wantMarks)
+			}
+		})
+	}
+}
+
+func TestQueryParamsBindNil(t *testing.T) {
+	var qp *QueryParams
+	marks := 0
+	got, args := qp.bind("SELECT :id", &marks)
+	if got != "SELECT :id" || args != nil {
+		t.Errorf("nil bind() = %q, %v, want the statement untouched", got, args)
+	}
+}
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// QueryTab is one query editor with its results. A tab belongs to the
+// connection and database it was opened on and only runs queries there.
+type QueryTab struct {
+	Name           string
+	Connection     string
+	Database       string
+	editor         *QueryEditor
+	results        *DataViewer
+	plan           *PlanViewer
+	resultsFocused bool
+}
+
+func NewQueryTab(name string) *QueryTab {
+	return &QueryTab{Name: name, editor: NewQueryEditor(), results: NewDataViewer()}
+}
+
+func (qt *QueryTab) bind(conn *ConnectionInfo) {
+	qt.Connection = conn.Name
+	qt.Database = conn.Database
+	qt.editor.SetDialect(conn.Type)
+}
+
+// label names the tab in the tab bar; tabs of another connection say which.
+func (qt *QueryTab) label(current string) string {
+	if qt.Connection != "" && qt.Connection != current {
+		return qt.Name + " @" + qt.Connection
+	}
+	return qt.Name
+}
+
+func (app *XTreeGoldApp) activeQueryTab() *QueryTab {
+	return app.tabs[app.activeTab]
+}
+
+// switchTab keeps the state of the current tab and shows tab idx; the app
+// fields for the editor, results and plan always belong to the active tab.
+func (app *XTreeGoldApp) switchTab(idx int) {
+	if idx < 0 || idx >= len(app.tabs) {
+		return
+	}
+	current := app.activeQueryTab()
+	current.plan = app.planViewer
+	current.resultsFocused = app.resultsFocused
+	app.showTab(idx)
+}
+
+func (app *XTreeGoldApp) showTab(idx int) {
+	app.activeTab = idx
+	tab := app.activeQueryTab()
+	app.queryEditor = tab.editor
+	app.dataViewer = tab.results
+	app.planViewer = tab.plan
+	app.resultsFocused = tab.resultsFocused
+	app.historyPos = -1
+}
+
+func (app *XTreeGoldApp) openTab() {
+	app.nextTabID++
+	tab := NewQueryTab(fmt.Sprintf("Consulta %d", app.nextTabID))
+	if app.currentConnection != nil {
+		tab.bind(app.currentConnection)
+		tab.editor.SetCatalog(app.catalog)
+	}
+	app.tabs = append(app.tabs, tab)
+	app.switchTab(len(app.tabs) - 1)
+}
+
+func (app *XTreeGoldApp) closeTab() {
+	if len(app.tabs) == 1 {
+		app.setStatus("⚠ A última aba não pode ser fechada")
+		return
+	}
+	closed := app.activeTab
+	app.tabs = append(app.tabs[:closed], app.tabs[closed+1:]...)
+	app.showTab(min(closed, len(app.tabs)-1))
+}
+
+func (app *XTreeGoldApp) moveTab(delta int) {
+	target := app.activeTab + delta
+	if target < 0 || target >= len(app.tabs) {
+		return
+	}
+	app.tabs[app.activeTab], app.tabs[target] = app.tabs[target], app.tabs[app.activeTab]
+	app.activeTab = target
+}
+
+// connectTabs binds the tabs to a new connection: the active tab when it
+// has none yet, otherwise the first tab of that connection is shown, or a
+// new one opened for it.
+func (app *XTreeGoldApp) connectTabs(conn *ConnectionInfo) {
+	app.catalog = nil
+	if tab := app.activeQueryTab(); tab.Connection == "" || tab.Connection == conn.Name {
+		tab.bind(conn)
+		return
+	}
+	for idx, tab := range app.tabs {
+		if tab.Connection == conn.Name {
+			tab.bind(conn)
+			app.switchTab(idx)
+			return
+		}
+	}
+	app.openTab()
+	app.activeQueryTab().bind(conn)
+}
+
+// setTabsCatalog gives the completion catalog of the current connection to
+// every tab bound to it, and to the tabs opened later.
+func (app *XTreeGoldApp) setTabsCatalog(catalog *SchemaCatalog) {
+	app.catalog = catalog
+	for _, tab := range app.tabs {
+		if tab.Connection == app.currentServer {
+			tab.editor.SetCatalog(catalog)
+		}
+	}
+}
+
+// tabConnected reports whether the active tab can run queries, telling the
+// user which connection it needs when it cannot.
+func (app *XTreeGoldApp) tabConnected() bool {
+	tab := app.activeQueryTab()
+	if tab.Connection == "" || tab.Connection == app.currentServer {
+		return true
+	}
+	app.setStatus(fmt.Sprintf("⚠ A aba %q usa a conexão %s; conecte-se a ela para executar", tab.Name, tab.Connection))
+	return false
+}
+
+// handleTabKey handles the tab keys of the query view: Alt+T opens, Alt+W
+// closes, Alt+R renames, Ctrl+PgUp/PgDn and Alt+1..9 switch and Alt+,/.
+// move the active tab.
+func (app *XTreeGoldApp) handleTabKey(msg tea.KeyMsg) bool {
+	switch msg.Type {
+	case tea.KeyCtrlPgUp:
+		app.switchTab((app.activeTab + len(app.tabs) - 1) % len(app.tabs))
+		return true
+	case tea.KeyCtrlPgDown:
+		app.switchTab((app.activeTab + 1) % len(app.tabs))
+		return true
+	}
+	if !msg.Alt || msg.Type != tea.KeyRunes || len(msg.Runes) != 1 {
+		return false
+	}
+
+	switch key := msg.Runes[0]; {
+	case key == 't':
+		app.openTab()
+	case key == 'w':
+		app.closeTab()
+	case key == 'r':
+		app.tabNameInput.SetWidth(max(app.width-8, 30))
+		app.tabNameInput.SetValue(app.activeQueryTab().Name)
+		app.renamingTab = true
+	case key == ',':
+		app.moveTab(-1)
+	case key == '.':
+		app.moveTab(1)
+	case key >= '1' && key <= '9':
+		app.switchTab(int(key - '1'))
+	default:
+		return false
+	}
+	return true
+}
+
+func (app *XTreeGoldApp) handleTabRename(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEscape:
+		app.renamingTab = false
+	case tea.KeyEnter:
+		if name := strings.TrimSpace(app.tabNameInput.Value()); name != "" {
+			app.activeQueryTab().Name = name
+		}
+		app.renamingTab = false
+	default:
+		app.tabNameInput.HandleKey(msg)
+	}
+	return app, nil
+}
+
+func (app *XTreeGoldApp) renderTabBar() string {
+	active := lipgloss.NewStyle().Foreground(lipgloss.Color("#000000")).Background(lipgloss.Color("#00FFFF")).Bold(true).Padding(0, 1)
+	inactive := lipgloss.NewStyle().Foreground(lipgloss.Color("#C6C6C6")).Background(lipgloss.Color("#2b2b2b")).Padding(0, 1)
+
+	parts := make([]string, 0, len(app.tabs)+1)
+	for idx, tab := range app.tabs {
+		label := fmt.Sprintf("%d %s", idx+1, tab.label(app.currentServer))
+		if idx == app.activeTab {
+			if tab.Database != "" {
+				label += " · " + tab.Database
+			}
+			parts = append(parts, active.Render(label))
+			continue
+		}
+		parts = append(parts, inactive.Render(label))
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync/atomic"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// QueryTransaction is an explicit transaction opened from the query editor.
+// It pins one connection of the pool so that every statement, session
+// settings included, runs in the same session until Commit or Rollback.
+type QueryTransaction struct {
+	conn       *sql.Conn
+	tx         *sql.Tx
+	statements int64
+}
+
+func beginQueryTransaction(ctx context.Context, db *sql.DB) (*QueryTransaction, error) {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get connection: %w", err)
+	}
+
+	// The transaction outlives the task that opens it, so it must not be
+	// rolled back when that task's context is cancelled.
+	tx, err := conn.BeginTx(context.Background(), nil)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	return &QueryTransaction{conn: conn, tx: tx}, nil
+}
+
+// countStatement is called from background tasks while the UI reads the
+// count, hence the atomic.
+func (qt *QueryTransaction) countStatement() {
+	atomic.AddInt64(&qt.statements, 1)
+}
+
+func (qt *QueryTransaction) StatementCount() int {
+	return int(atomic.LoadInt64(&qt.statements))
+}
+
+func (qt *QueryTransaction) Commit() error {
+	defer qt.conn.Close()
+	if err := qt.tx.Commit(); err != nil {
+		return fmt.Errorf("commit failed: %w", err)
+	}
+	return nil
+}
+
+func (qt *QueryTransaction) Rollback() error {
+	defer qt.conn.Close()
+	if err := qt.tx.Rollback(); err != nil {
+		return fmt.Errorf("rollback failed: %w", err)
+	}
+	return nil
+}
+
+// QuerySession is the connection the statements of one script share, so
+// that session state (SET, temporary tables, user variables) carries from
+// one statement to the next. Inside a transaction it is the transaction's
+// connection and Close leaves it open.
+type QuerySession struct {
+	conn    *sql.Conn
+	queryer sqlQueryer
+	release func()
+}
+
+func openQuerySession(ctx context.Context, db *sql.DB, tx *QueryTransaction) (*QuerySession, error) {
+	if tx != nil {
+		return &QuerySession{conn: tx.conn, queryer: tx.tx, release: func() {}}, nil
+	}
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get connection: %w", err)
+	}
+	return &QuerySession{conn: conn, queryer: conn, release: func() { conn.Close() }}, nil
+}
+
+// Close returns the connection to the pool.
+func (qs *QuerySession) Close() {
+	qs.release()
+}
+
+type TransactionStartedMsg struct {
+	tx  *QueryTransaction
+	err error
+}
+
+// TransactionEndedMsg reports a commit or rollback; after is the action that
+// was waiting for the transaction to end (quit, switch connection), if any.
+type TransactionEndedMsg struct {
+	commit     bool
+	statements int
+	err        error
+	after      tea.Cmd
+}
+
+func (app *XTreeGoldApp) beginTransaction() tea.Cmd {
+	loader := app.dbLoader
+	return app.startTask("Iniciando transação", func(ctx context.Context) tea.Msg {
+		tx, err := loader.BeginTransaction(ctx)
+		return TransactionStartedMsg{tx: tx, err: err}
+	})
+}
+
+// confirmTransaction asks whether to commit or roll back the open
+// transaction; after runs once it has ended.
+func (app *XTreeGoldApp) confirmTransaction(after tea.Cmd) {
+	app.txPrompt = true
+	app.txAfter = after
+}
+
+func (app *XTreeGoldApp) handleTransactionPrompt(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case msg.Type == tea.KeyEscape:
+		app.txPrompt = false
+		app.txAfter = nil
+	case msg.String() == "c" || msg.String() == "C":
+		return app, app.endTransaction(true)
+	case msg.String() == "r" || msg.String() == "R":
+		return app, app.endTransaction(false)
+	}
+	return app, nil
+}
+
+// endTransaction forgets the transaction right away: whatever the outcome,
+// its connection is closed and it cannot be used again.
+func (app *XTreeGoldApp) endTransaction(commit bool) tea.Cmd {
+	tx, after := app.transaction, app.txAfter
+	app.transaction = nil
+	app.txPrompt = false
+	app.txAfter = nil
+
+	label := "Desfazendo transação"
+	if commit {
+		label = "Confirmando transação"
+	}
+	return app.startTask(label, func(ctx context.Context) tea.Msg {
+		ended := TransactionEndedMsg{commit: commit, statements: tx.StatementCount(), after: after}
+		if commit {
+			ended.err = tx.Commit()
+		} else {
+			ended.err = tx.Rollback()
+		}
+		return ended
+	})
+}
+
+func (app *XTreeGoldApp) transactionPrompt() string {
+	return fmt.Sprintf("Transação aberta com %d instrução(ões): c confirma (COMMIT) | r desfaz (ROLLBACK) | Esc volta",
+		app.transaction.StatementCount())
+}
+
+func (app *XTreeGoldApp) transactionBadge() string {
+	if app.transaction == nil {
+		return ""
+	}
+	return lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#000000")).
+		Background(lipgloss.Color("#FFB86C")).
+		Bold(true).
+		Padding(0, 1).
+		Render(fmt.Sprintf("IN TRANSACTION · %d", app.transaction.StatementCount()))
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+type ResultColumn struct {
+	Name          string
+	TypeName      string
+	Nullable      bool
+	NullableKnown bool
+}
+
+// ResultSet keeps columns in the order the database returned them, so
+// duplicate names and SELECT list order survive; rows are positional.
+type ResultSet struct {
+	Columns []ResultColumn
+	Rows    [][]interface{}
+}
+
+// scanResultSet reads every row. Drivers hand back many text types as
+// []byte; those are converted to strings unless isBinary reports the
+// column's database type as genuinely binary.
+func scanResultSet(rows *sql.Rows, isBinary func(typeName string) bool) (*ResultSet, error) {
+	columnTypes, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get columns: %w", err)
+	}
+
+	rs := &ResultSet{Columns: make([]ResultColumn, len(columnTypes))}
+	binary := make([]bool, len(columnTypes))
+	for i, ct := range columnTypes {
+		nullable, known := ct.Nullable()
+		rs.Columns[i] = ResultColumn{
+			Name:          ct.Name(),
+			TypeName:      ct.DatabaseTypeName(),
+			Nullable:      nullable,
+			NullableKnown: known,
+		}
+		binary[i] = isBinary == nil || isBinary(ct.DatabaseTypeName())
+	}
+
+	for rows.Next() {
+		values := make([]interface{}, len(columnTypes))
+		valuePtrs := make([]interface{}, len(columnTypes))
+		for i := range values {
+			valuePtrs[i] = &values[i]
+		}
+
+		if err := rows.Scan(valuePtrs...); err != nil {
+			return nil, fmt.Errorf("scan failed: %w", err)
+		}
+
+		for i, val := range values {
+			if b, ok := val.([]byte); ok && !binary[i] {
+				values[i] = string(b)
+			}
+		}
+		rs.Rows = append(rs.Rows, values)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("scan failed: %w", err)
+	}
+
+	return rs, nil
+}
+
+func (rs *ResultSet) Len() int {
+	if rs == nil {
+		return 0
+	}
+	return len(rs.Rows)
+}
+
+func (rs *ResultSet) ColumnCount() int {
+	if rs == nil {
+		return 0
+	}
+	return len(rs.Columns)
+}
+
+func (rs *ResultSet) ColumnNames() []string {
+	if rs == nil {
+		return nil
+	}
+	names := make([]string, len(rs.Columns))
+	for i, col := range rs.Columns {
+		names[i] = col.Name
+	}
+	return names
+}
+
+// ColumnIndex returns the first column with the given name, or -1.
+func (rs *ResultSet) ColumnIndex(name string) int {
+	if rs == nil {
+		return -1
+	}
+	for i, col := range rs.Columns {
+		if col.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func (rs *ResultSet) Value(row, col int) interface{} {
+	if rs == nil || row < 0 || row >= len(rs.Rows) || col < 0 || col >= len(rs.Rows[row]) {
+		return nil
+	}
+	return rs.Rows[row][col]
+}
+
+func (rs *ResultSet) Lookup(row int, name string) (interface{}, bool) {
+	col := rs.ColumnIndex(name)
+	if col < 0 || row < 0 || row >= rs.Len() || col >= len(rs.Rows[row]) {
+		return nil, false
+	}
+	return rs.Rows[row][col], true
+}
+
+func (rs *ResultSet) SetValue(row, col int, value interface{}) bool {
+	if rs == nil || row < 0 || row >= len(rs.Rows) || col < 0 || col >= len(rs.Rows[row]) {
+		return false
+	}
+	rs.Rows[row][col] = value
+	return true
+}
+
+// AppendRow adds a row from named values; columns that are not part of the
+// result set are ignored and missing ones are left NULL.
+func (rs *ResultSet) AppendRow(values map[string]interface{}) int {
+	row := make([]interface{}, len(rs.Columns))
+	for i, col := range rs.Columns {
+		row[i] = values[col.Name]
+	}
+	rs.Rows = append(rs.Rows, row)
+	return len(rs.Rows) - 1
+}
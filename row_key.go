@@ -0,0 +1,68 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+const rowIDColumn = "__rowid"
+
+type RowKey struct {
+	Columns []string
+	Values  []interface{}
+}
+
+func PhysicalRowKey(value interface{}) RowKey {
+	switch v := value.(type) {
+	case nil:
+		return RowKey{}
+	case []byte:
+		value = string(v)
+	}
+	return RowKey{Columns: []string{rowIDColumn}, Values: []interface{}{value}}
+}
+
+func (rk RowKey) IsEmpty() bool {
+	return len(rk.Columns) == 0 || len(rk.Columns) != len(rk.Values)
+}
+
+func (rk RowKey) IsPhysical() bool {
+	return len(rk.Columns) == 1 && rk.Columns[0] == rowIDColumn
+}
+
+func (rk RowKey) String() string {
+	parts := make([]string, 0, len(rk.Columns))
+	for idx, col := range rk.Columns {
+		var val interface{}
+		if idx < len(rk.Values) {
+			val = rk.Values[idx]
+		}
+		if b, ok := val.([]byte); ok {
+			val = string(b)
+		}
+		parts = append(parts, fmt.Sprintf("%s=%v", col, val))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// expectSingleRow checks that a change keyed by key touched exactly one row.
+// A key that matches several rows (a rowid reused, a unique index dropped)
+// is an error too, so the surrounding transaction rolls back.
+func expectSingleRow(result sql.Result, key RowKey) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to count affected rows: %w", err)
+	}
+	if affected == 0 {
+		return fmt.Errorf("row %s no longer exists", key)
+	}
+	if affected > 1 {
+		return fmt.Errorf("row %s matched %d rows", key, affected)
+	}
+	return nil
+}
+
+func tableCacheKey(database, schema, table string) string {
+	return database + "\x00" + schema + "\x00" + table
+}
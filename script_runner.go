@@ -0,0 +1,134 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"windsurf-tui/sqllex"
+)
+
+// StatementResult is the outcome of one statement of a script run from the
+// query editor. RowsAffected is -1 when the statement returned rows or the
+// driver could not tell; Notices holds the server messages raised while it
+// ran, for drivers that report them.
+type StatementResult struct {
+	Statement    string
+	Results      *ResultSet
+	RowsAffected int64
+	Duration     time.Duration
+	Notices      []string
+	Err          error
+}
+
+// sqlQueryer is implemented by *sql.DB, *sql.Conn and *sql.Tx.
+type sqlQueryer interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+func (sr StatementResult) ReturnsRows() bool {
+	return sr.Results != nil && sr.Results.ColumnCount() > 0
+}
+
+// RowCount is the number of rows returned or, for commands, affected.
+func (sr StatementResult) RowCount() int64 {
+	if sr.ReturnsRows() {
+		return int64(sr.Results.Len())
+	}
+	if sr.RowsAffected > 0 {
+		return sr.RowsAffected
+	}
+	return 0
+}
+
+// executeStatement runs a single statement with its bind arguments, reading
+// its rows when it is a query and the affected row count otherwise.
+func executeStatement(ctx context.Context, db sqlQueryer, dialect ConnectionType, statement string, isBinary func(typeName string) bool, args ...interface{}) StatementResult {
+	result := StatementResult{Statement: statement, RowsAffected: -1}
+	started := time.Now()
+	defer func() {
+		result.Duration = time.Since(started)
+	}()
+
+	if !returnsRows(statement, dialect) {
+		res, err := db.ExecContext(ctx, statement, args...)
+		if err != nil {
+			result.Err = fmt.Errorf("statement failed: %w", err)
+			return result
+		}
+		if affected, err := res.RowsAffected(); err == nil {
+			result.RowsAffected = affected
+		}
+		return result
+	}
+
+	rows, err := db.QueryContext(ctx, statement, args...)
+	if err != nil {
+		result.Err = fmt.Errorf("query failed: %w", err)
+		return result
+	}
+	defer rows.Close()
+
+	result.Results, result.Err = scanResultSet(rows, isBinary)
+	return result
+}
+
+// returnsRows guesses from the leading keyword (or a RETURNING clause)
+// whether a statement produces a result set.
+func returnsRows(statement string, dialect ConnectionType) bool {
+	tokens := tokenizeSQL(statement, dialect)
+	first := -1
+	for idx, tok := range tokens {
+		if tok.IsTrivia() || tok.Kind == sqllex.Punct && tok.Text == "(" {
+			continue
+		}
+		if first < 0 {
+			first = idx
+		}
+		if tok.IsKeyword("RETURNING") {
+			return true
+		}
+	}
+	if first < 0 {
+		return false
+	}
+	return tokens[first].IsKeyword("SELECT", "WITH", "VALUES", "TABLE", "SHOW", "EXPLAIN",
+		"DESCRIBE", "DESC", "PRAGMA", "CALL")
+}
+
+// runScript executes the statements of script in order on one session,
+// inside tx when it is not nil, and stops at the first failure; skipped
+// counts the statements that were not run. Placeholders are bound to params
+// as driver arguments.
+func runScript(ctx context.Context, loader DatabaseLoader, tx *QueryTransaction, dialect ConnectionType, script string, params *QueryParams) (results []StatementResult, skipped int) {
+	statements := splitStatements(script, dialect)
+	if len(statements) == 0 {
+		return nil, 0
+	}
+	session, err := loader.OpenSession(ctx, tx)
+	if err != nil {
+		results = append(results, StatementResult{Statement: statements[0].Text, RowsAffected: -1, Err: err})
+		return results, len(statements) - 1
+	}
+	defer session.Close()
+
+	marks := 0
+	for idx, statement := range statements {
+		if ctx.Err() != nil {
+			return results, len(statements) - idx
+		}
+		if tx != nil {
+			tx.countStatement()
+		}
+		text, args := params.bind(statement.Text, &marks)
+		result := loader.ExecuteStatement(ctx, session, text, args...)
+		result.Statement = statement.Text
+		results = append(results, result)
+		if result.Err != nil {
+			return results, len(statements) - idx - 1
+		}
+	}
+	return results, 0
+}
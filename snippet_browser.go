@@ -0,0 +1,196 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	snippetBrowserRows  = 10
+	snippetPreviewLines = 6
+)
+
+// SnippetBrowser is the snippet search overlay of the query view.
+type SnippetBrowser struct {
+	library  *SnippetLibrary
+	input    *TextInput
+	matches  []Snippet
+	selected int
+	closed   bool
+}
+
+// SnippetPickMsg carries the snippet chosen to be inserted at the cursor.
+type SnippetPickMsg struct {
+	snippet Snippet
+}
+
+// SnippetSaveMsg asks for the current query to be saved under path
+// ("folder/name").
+type SnippetSaveMsg struct {
+	path string
+}
+
+// SnippetFilledMsg carries a snippet whose placeholders were filled in.
+type SnippetFilledMsg struct {
+	text string
+}
+
+func NewSnippetBrowser(library *SnippetLibrary, width int) *SnippetBrowser {
+	input := NewTextInput()
+	input.SetWidth(width)
+	input.SetPlaceholder("busca aproximada; Ctrl+S salva a consulta com este nome (pasta/nome)")
+	sb := &SnippetBrowser{library: library, input: input}
+	sb.refresh()
+	return sb
+}
+
+func (sb *SnippetBrowser) refresh() {
+	sb.matches = sb.library.Search(sb.input.Value())
+	if sb.selected >= len(sb.matches) {
+		sb.selected = len(sb.matches) - 1
+	}
+	if sb.selected < 0 {
+		sb.selected = 0
+	}
+}
+
+func (sb *SnippetBrowser) IsClosed() bool {
+	return sb.closed
+}
+
+func (sb *SnippetBrowser) Init() tea.Cmd { return nil }
+
+func (sb *SnippetBrowser) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return sb, nil
+	}
+
+	switch keyMsg.Type {
+	case tea.KeyEscape:
+		sb.closed = true
+		return sb, nil
+	case tea.KeyUp:
+		if sb.selected > 0 {
+			sb.selected--
+		}
+		return sb, nil
+	case tea.KeyDown:
+		if sb.selected < len(sb.matches)-1 {
+			sb.selected++
+		}
+		return sb, nil
+	case tea.KeyEnter:
+		if sb.selected >= len(sb.matches) {
+			return sb, nil
+		}
+		sb.closed = true
+		pick := SnippetPickMsg{snippet: sb.matches[sb.selected]}
+		return sb, func() tea.Msg { return pick }
+	case tea.KeyCtrlS:
+		path := strings.Trim(strings.TrimSpace(sb.input.Value()), "/")
+		if path == "" {
+			return sb, nil
+		}
+		sb.closed = true
+		return sb, func() tea.Msg { return SnippetSaveMsg{path: path} }
+	}
+
+	if sb.input.HandleKey(keyMsg) {
+		sb.selected = 0
+		sb.refresh()
+	}
+	return sb, nil
+}
+
+func (sb *SnippetBrowser) View() string {
+	lines := []string{sb.input.View(fmt.Sprintf("📚 Snippets (%d)", len(sb.matches)))}
+
+	start := 0
+	if sb.selected >= snippetBrowserRows {
+		start = sb.selected - snippetBrowserRows + 1
+	}
+	for idx := start; idx < len(sb.matches) && idx < start+snippetBrowserRows; idx++ {
+		snippet := sb.matches[idx]
+		line := snippet.Path()
+		if snippet.project {
+			line += " [projeto]"
+		}
+		if snippet.Description != "" {
+			line += " — " + snippet.Description
+		}
+		style := lipgloss.NewStyle().Foreground(lipgloss.Color("#FFFFFF"))
+		if idx == sb.selected {
+			style = style.Background(lipgloss.Color("#083863")).Bold(true)
+		}
+		lines = append(lines, style.Render(line))
+	}
+	if len(sb.matches) == 0 {
+		lines = append(lines, lipgloss.NewStyle().Foreground(lipgloss.Color("#808080")).Render("  (nenhum snippet encontrado)"))
+	} else {
+		preview := strings.Split(sb.matches[sb.selected].Body, "\n")
+		if len(preview) > snippetPreviewLines {
+			preview = append(preview[:snippetPreviewLines], "…")
+		}
+		lines = append(lines, lipgloss.NewStyle().
+			Border(lipgloss.NormalBorder()).
+			BorderForeground(lipgloss.Color("#5A5A5A")).
+			Foreground(lipgloss.Color("#C6C6C6")).
+			Render(strings.Join(preview, "\n")))
+	}
+
+	lines = append(lines, lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#888888")).
+		Italic(true).
+		Render("↑/↓ Navega | Enter Insere no cursor | Ctrl+S Salva a consulta | Esc Fecha"))
+	return strings.Join(lines, "\n")
+}
+
+func (app *XTreeGoldApp) openSnippets() {
+	if err := app.snippets.Load(); err != nil {
+		app.setStatus(fmt.Sprintf("⚠ Snippets: %v", err))
+	}
+	app.snippetBrowser = NewSnippetBrowser(app.snippets, max(app.width-8, 30))
+}
+
+// insertSnippet puts a snippet at the cursor, asking first for the values
+// of its placeholders.
+func (app *XTreeGoldApp) insertSnippet(snippet Snippet) tea.Cmd {
+	names, defaults := snippetPlaceholders(snippet.Body)
+	if len(names) == 0 {
+		app.queryEditor.InsertText(snippet.Body)
+		return nil
+	}
+	body := snippet.Body
+	app.paramPrompt = NewParamPrompt("📚 "+snippet.Path(), names, defaults, max(app.width-8, 30),
+		func(inputs map[string]string) tea.Msg {
+			return SnippetFilledMsg{text: expandSnippet(body, inputs)}
+		})
+	app.paramPrompt.SetHint("")
+	return nil
+}
+
+// saveSnippet stores the selection, or the whole query, under path.
+func (app *XTreeGoldApp) saveSnippet(path string) {
+	body := app.queryEditor.SelectedText()
+	if body == "" {
+		body = app.queryEditor.GetValue()
+	}
+	if strings.TrimSpace(body) == "" {
+		app.setStatus("⚠ Nada para salvar como snippet")
+		return
+	}
+
+	snippet := Snippet{Name: path, Body: body}
+	if idx := strings.LastIndex(path, "/"); idx >= 0 {
+		snippet.Folder, snippet.Name = path[:idx], path[idx+1:]
+	}
+	if err := app.snippets.Save(snippet); err != nil {
+		app.setStatus(fmt.Sprintf("✖ Falha ao salvar snippet: %v", err))
+		return
+	}
+	app.setStatus(fmt.Sprintf("✔ Snippet %s salvo", snippet.Path()))
+}
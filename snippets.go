@@ -0,0 +1,247 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Snippet is a saved piece of SQL. Body may hold ${name} or ${name:default}
+// placeholders that are filled in when the snippet is inserted.
+type Snippet struct {
+	Name        string `json:"name"`
+	Folder      string `json:"folder,omitempty"`
+	Description string `json:"description,omitempty"`
+	Body        string `json:"body"`
+	project     bool
+}
+
+// Path is the folder and name, as shown and searched in the browser.
+func (s Snippet) Path() string {
+	if s.Folder == "" {
+		return s.Name
+	}
+	return s.Folder + "/" + s.Name
+}
+
+// SnippetLibrary reads every *.json file under the user directory (each an
+// array of snippets, the subdirectory being the default folder) plus an
+// optional project file meant to be committed with the project.
+type SnippetLibrary struct {
+	userDir     string
+	projectPath string
+	snippets    []Snippet
+}
+
+func defaultSnippetsDir() string {
+	return filepath.Join(os.Getenv("HOME"), ".windsurf-tui", "snippets")
+}
+
+// projectSnippetsPath is snippets.json in the working directory, where a
+// project keeps its connections.json.
+func projectSnippetsPath() string {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(cwd, "snippets.json")
+}
+
+func NewSnippetLibrary(userDir, projectPath string) *SnippetLibrary {
+	return &SnippetLibrary{userDir: userDir, projectPath: projectPath}
+}
+
+// Load rereads all snippet files. A broken file does not hide the others;
+// the first error is returned after everything readable was loaded.
+func (sl *SnippetLibrary) Load() error {
+	sl.snippets = nil
+	var firstErr error
+	keep := func(err error) {
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	err := filepath.WalkDir(sl.userDir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if entry.IsDir() || filepath.Ext(path) != ".json" {
+			return nil
+		}
+		snippets, err := readSnippetFile(path)
+		if err != nil {
+			keep(err)
+			return nil
+		}
+		folder, _ := filepath.Rel(sl.userDir, filepath.Dir(path))
+		for _, snippet := range snippets {
+			if snippet.Folder == "" && folder != "." {
+				snippet.Folder = filepath.ToSlash(folder)
+			}
+			sl.snippets = append(sl.snippets, snippet)
+		}
+		return nil
+	})
+	if err != nil {
+		keep(fmt.Errorf("failed to read snippets: %w", err))
+	}
+
+	if sl.projectPath != "" {
+		snippets, err := readSnippetFile(sl.projectPath)
+		if err != nil && !os.IsNotExist(err) {
+			keep(err)
+		}
+		for _, snippet := range snippets {
+			snippet.project = true
+			sl.snippets = append(sl.snippets, snippet)
+		}
+	}
+
+	sort.SliceStable(sl.snippets, func(i, j int) bool {
+		return strings.ToLower(sl.snippets[i].Path()) < strings.ToLower(sl.snippets[j].Path())
+	})
+	return firstErr
+}
+
+func readSnippetFile(path string) ([]Snippet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var snippets []Snippet
+	if err := json.Unmarshal(data, &snippets); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return snippets, nil
+}
+
+// Save stores a snippet in snippets.json of the user directory, replacing
+// one with the same folder and name. It shows up on the next Load.
+func (sl *SnippetLibrary) Save(snippet Snippet) error {
+	path := filepath.Join(sl.userDir, "snippets.json")
+	snippets, err := readSnippetFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	replaced := false
+	for idx := range snippets {
+		if snippets[idx].Path() == snippet.Path() {
+			snippets[idx] = snippet
+			replaced = true
+		}
+	}
+	if !replaced {
+		snippets = append(snippets, snippet)
+	}
+
+	data, err := json.MarshalIndent(snippets, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal snippets: %w", err)
+	}
+	if err := os.MkdirAll(sl.userDir, 0755); err != nil {
+		return fmt.Errorf("failed to create snippets directory: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write snippets file: %w", err)
+	}
+	return nil
+}
+
+// Search ranks the snippets whose path or description fuzzily match every
+// word of term; an empty term lists them all.
+func (sl *SnippetLibrary) Search(term string) []Snippet {
+	words := strings.Fields(strings.ToLower(term))
+	if len(words) == 0 {
+		return sl.snippets
+	}
+
+	type scored struct {
+		snippet Snippet
+		score   int
+	}
+	var matches []scored
+	for _, snippet := range sl.snippets {
+		text := strings.ToLower(snippet.Path() + " " + snippet.Description)
+		total := 0
+		matched := true
+		for _, word := range words {
+			score, ok := fuzzyScore(word, text)
+			if !ok {
+				matched = false
+				break
+			}
+			total += score
+		}
+		if matched {
+			matches = append(matches, scored{snippet, total})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].score > matches[j].score
+	})
+
+	result := make([]Snippet, len(matches))
+	for idx, match := range matches {
+		result[idx] = match.snippet
+	}
+	return result
+}
+
+// fuzzyScore finds the runes of pattern in order within text. Runes that
+// follow the previous match or start a word score higher.
+func fuzzyScore(pattern, text string) (int, bool) {
+	target := []rune(text)
+	score, pos, last := 0, 0, -2
+	for _, r := range pattern {
+		for pos < len(target) && target[pos] != r {
+			pos++
+		}
+		if pos == len(target) {
+			return 0, false
+		}
+		score++
+		if pos == last+1 {
+			score += 5
+		}
+		if pos == 0 || !unicode.IsLetter(target[pos-1]) && !unicode.IsDigit(target[pos-1]) {
+			score += 3
+		}
+		last = pos
+		pos++
+	}
+	return score, true
+}
+
+var snippetPlaceholder = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)(?::([^}]*))?\}`)
+
+// snippetPlaceholders lists the placeholder names of body in order of first
+// use, with their defaults.
+func snippetPlaceholders(body string) ([]string, map[string]string) {
+	var names []string
+	defaults := make(map[string]string)
+	for _, match := range snippetPlaceholder.FindAllStringSubmatch(body, -1) {
+		if _, seen := defaults[match[1]]; seen {
+			continue
+		}
+		names = append(names, match[1])
+		defaults[match[1]] = match[2]
+	}
+	return names, defaults
+}
+
+func expandSnippet(body string, values map[string]string) string {
+	return snippetPlaceholder.ReplaceAllStringFunc(body, func(placeholder string) string {
+		return values[snippetPlaceholder.FindStringSubmatch(placeholder)[1]]
+	})
+}
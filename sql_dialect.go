@@ -0,0 +1,193 @@
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+type sqlDialect struct {
+	quoteIdent       func(string) string
+	placeholder      func(int) string
+	rowLocator       string
+	normalizeKeyArgs func(RowKey, []interface{}) []interface{}
+	bytesLiteral     func([]byte) string
+	stringLiteral    func(string) string
+}
+
+var postgresDialect = sqlDialect{
+	quoteIdent:       quoteIdentifier,
+	placeholder:      postgresPlaceholder,
+	rowLocator:       "ctid",
+	normalizeKeyArgs: normalizePostgresKeyArgs,
+	bytesLiteral:     postgresBytesLiteral,
+}
+
+var sqliteDialect = sqlDialect{
+	quoteIdent:       quoteIdentifier,
+	placeholder:      questionPlaceholder,
+	rowLocator:       "rowid",
+	normalizeKeyArgs: normalizeSQLiteKeyArgs,
+	bytesLiteral:     hexBytesLiteral,
+}
+
+func postgresPlaceholder(idx int) string {
+	return fmt.Sprintf("$%d", idx)
+}
+
+func questionPlaceholder(int) string {
+	return "?"
+}
+
+func postgresBytesLiteral(b []byte) string {
+	return `'\x` + hex.EncodeToString(b) + `'::bytea`
+}
+
+func hexBytesLiteral(b []byte) string {
+	return "X'" + hex.EncodeToString(b) + "'"
+}
+
+func quoteStringLiteral(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
+
+func (d sqlDialect) quoteString(value string) string {
+	if d.stringLiteral != nil {
+		return d.stringLiteral(value)
+	}
+	return quoteStringLiteral(value)
+}
+
+// literal renders a scanned value as an inline SQL literal, for scripts that
+// are run outside of this session (e.g. exported INSERT statements).
+func (d sqlDialect) literal(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "NULL"
+	case []byte:
+		if utf8.Valid(v) || d.bytesLiteral == nil {
+			return d.quoteString(string(v))
+		}
+		return d.bytesLiteral(v)
+	case string:
+		return d.quoteString(v)
+	case bool:
+		if v {
+			return "TRUE"
+		}
+		return "FALSE"
+	case time.Time:
+		return d.quoteString(v.Format("2006-01-02 15:04:05.999999-07:00"))
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	default:
+		return d.quoteString(fmt.Sprintf("%v", v))
+	}
+}
+
+func (d sqlDialect) quoteList(columns []string) string {
+	quoted := make([]string, 0, len(columns))
+	for _, col := range columns {
+		quoted = append(quoted, d.quoteIdent(col))
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// Physical keys map to the engine's own row locator (ctid, rowid).
+func (d sqlDialect) keyWhere(key RowKey, startArg int) (string, []interface{}, error) {
+	if key.IsEmpty() {
+		return "", nil, fmt.Errorf("row key is empty")
+	}
+	if key.IsPhysical() && d.rowLocator == "" {
+		return "", nil, fmt.Errorf("table has no primary key and no physical row locator")
+	}
+
+	var conditions []string
+	var args []interface{}
+	for idx, col := range key.Columns {
+		target := d.quoteIdent(col)
+		if key.IsPhysical() {
+			target = d.rowLocator
+		}
+		conditions = append(conditions, fmt.Sprintf("%s = %s", target, d.placeholder(startArg+idx)))
+		args = append(args, key.Values[idx])
+	}
+
+	if d.normalizeKeyArgs != nil {
+		args = d.normalizeKeyArgs(key, args)
+	}
+	return strings.Join(conditions, " AND "), args, nil
+}
+
+// keysetWhere seeks past the row whose key columns hold after, for tables
+// ordered by those columns.
+func (d sqlDialect) keysetWhere(keyCols []string, after []interface{}, startArg int) (string, []interface{}) {
+	placeholders := make([]string, len(after))
+	for idx := range after {
+		placeholders[idx] = d.placeholder(startArg + idx)
+	}
+	args := append([]interface{}(nil), after...)
+	if d.normalizeKeyArgs != nil {
+		args = d.normalizeKeyArgs(RowKey{Columns: keyCols, Values: args}, args)
+	}
+	return fmt.Sprintf("(%s) > (%s)", d.quoteList(keyCols), strings.Join(placeholders, ", ")), args
+}
+
+func (d sqlDialect) changeSQL(target string, change PendingChange) (string, []interface{}, error) {
+	switch change.Kind {
+	case ChangeUpdate:
+		if len(change.Values) == 0 {
+			return "", nil, fmt.Errorf("no values provided for update")
+		}
+		columns := sortedValueColumns(change.Values)
+		var assignments []string
+		var args []interface{}
+		for idx, col := range columns {
+			assignments = append(assignments, fmt.Sprintf("%s = %s", d.quoteIdent(col), d.placeholder(idx+1)))
+			args = append(args, change.Values[col])
+		}
+		where, keyArgs, err := d.keyWhere(change.Key, len(columns)+1)
+		if err != nil {
+			return "", nil, err
+		}
+		query := fmt.Sprintf(`
+			UPDATE %s
+			SET %s
+			WHERE %s
+		`, target, strings.Join(assignments, ", "), where)
+		return query, append(args, keyArgs...), nil
+	case ChangeInsert:
+		if len(change.Values) == 0 {
+			return "", nil, fmt.Errorf("no values provided for insert")
+		}
+		columns := sortedValueColumns(change.Values)
+		var placeholders []string
+		var args []interface{}
+		for idx, col := range columns {
+			placeholders = append(placeholders, d.placeholder(idx+1))
+			args = append(args, change.Values[col])
+		}
+		query := fmt.Sprintf(`
+			INSERT INTO %s (%s)
+			VALUES (%s)
+		`, target, d.quoteList(columns), strings.Join(placeholders, ", "))
+		return query, args, nil
+	case ChangeDelete:
+		where, args, err := d.keyWhere(change.Key, 1)
+		if err != nil {
+			return "", nil, err
+		}
+		query := fmt.Sprintf(`
+			DELETE FROM %s
+			WHERE %s
+		`, target, where)
+		return query, args, nil
+	default:
+		return "", nil, fmt.Errorf("unsupported change kind %d", change.Kind)
+	}
+}
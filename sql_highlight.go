@@ -0,0 +1,153 @@
+package main
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"windsurf-tui/sqllex"
+)
+
+type sqlHighlightStyles struct {
+	keyword     lipgloss.Style
+	identifier  lipgloss.Style
+	quotedIdent lipgloss.Style
+	str         lipgloss.Style
+	dollarBody  lipgloss.Style
+	number      lipgloss.Style
+	comment     lipgloss.Style
+	param       lipgloss.Style
+	punct       lipgloss.Style
+	cursor      lipgloss.Style
+}
+
+var sqlStyles = sqlHighlightStyles{
+	keyword:     lipgloss.NewStyle().Foreground(lipgloss.Color("#569CD6")).Bold(true),
+	identifier:  lipgloss.NewStyle().Foreground(lipgloss.Color("#FFFFFF")),
+	quotedIdent: lipgloss.NewStyle().Foreground(lipgloss.Color("#9CDCFE")),
+	str:         lipgloss.NewStyle().Foreground(lipgloss.Color("#CE9178")),
+	dollarBody:  lipgloss.NewStyle().Foreground(lipgloss.Color("#D7BA7D")),
+	number:      lipgloss.NewStyle().Foreground(lipgloss.Color("#B5CEA8")),
+	comment:     lipgloss.NewStyle().Foreground(lipgloss.Color("#6A9955")).Italic(true),
+	param:       lipgloss.NewStyle().Foreground(lipgloss.Color("#C586C0")).Bold(true),
+	punct:       lipgloss.NewStyle().Foreground(lipgloss.Color("#D4D4D4")),
+	cursor:      lipgloss.NewStyle().Reverse(true),
+}
+
+// tokenStyle picks the style of tokens[idx]. Placeholder syntax differs per
+// dialect: $1 in PostgreSQL, ? in SQLite and MySQL, plus :name, @name and
+// $name in SQLite (and @variables in MySQL).
+func tokenStyle(tokens []sqllex.Token, idx int, dialect ConnectionType) lipgloss.Style {
+	tok := tokens[idx]
+	switch tok.Kind {
+	case sqllex.Space:
+		return lipgloss.NewStyle()
+	case sqllex.Comment:
+		return sqlStyles.comment
+	case sqllex.String:
+		if strings.HasPrefix(tok.Text, "$") {
+			return sqlStyles.dollarBody
+		}
+		return sqlStyles.str
+	case sqllex.QuotedIdent:
+		return sqlStyles.quotedIdent
+	case sqllex.Number:
+		return sqlStyles.number
+	case sqllex.Param:
+		return sqlStyles.param
+	case sqllex.Word:
+		if idx > 0 && isNamedParamPrefix(tokens[idx-1], dialect) && tokens[idx-1].End == tok.Start {
+			return sqlStyles.param
+		}
+		if isSQLKeyword(dialect, tok.Text) {
+			return sqlStyles.keyword
+		}
+		return sqlStyles.identifier
+	}
+
+	if dialect != ConnectionPostgres && tok.Text == "?" {
+		return sqlStyles.param
+	}
+	if isNamedParamPrefix(tok, dialect) && idx+1 < len(tokens) &&
+		tokens[idx+1].Kind == sqllex.Word && tokens[idx+1].Start == tok.End {
+		return sqlStyles.param
+	}
+	return sqlStyles.punct
+}
+
+func isNamedParamPrefix(tok sqllex.Token, dialect ConnectionType) bool {
+	if tok.Kind != sqllex.Punct {
+		return false
+	}
+	switch dialect {
+	case ConnectionSQLite:
+		return tok.Text == ":" || tok.Text == "@" || tok.Text == "$"
+	case ConnectionMySQL:
+		return tok.Text == "@"
+	}
+	return false
+}
+
+// highlightSQL renders text one line per element, styled by token, with the
+// character at cursor (a byte offset) shown in reverse video and the bytes in
+// [selStart, selEnd) on the selection background. A cursor at the end of a
+// line is drawn as a reversed space; tabs are drawn as four spaces.
+func highlightSQL(text string, dialect ConnectionType, cursor, selStart, selEnd int) []string {
+	var lines []string
+	var line strings.Builder
+	emit := func(s string, style lipgloss.Style) {
+		if s != "" {
+			line.WriteString(style.Render(strings.ReplaceAll(s, "\t", "    ")))
+		}
+	}
+	newline := func() {
+		lines = append(lines, line.String())
+		line.Reset()
+	}
+
+	tokens := tokenizeSQL(text, dialect)
+	for idx, tok := range tokens {
+		style := tokenStyle(tokens, idx, dialect)
+		selected := style.Copy().Background(textSelectionColor)
+		pos := tok.Start
+		for pos < tok.End {
+			end := tok.End
+			if nl := strings.IndexByte(text[pos:end], '\n'); nl >= 0 {
+				end = pos + nl
+			}
+			for pos < end {
+				if pos == cursor {
+					_, size := utf8.DecodeRuneInString(text[cursor:])
+					emit(text[cursor:cursor+size], sqlStyles.cursor)
+					pos = cursor + size
+					continue
+				}
+				stop := end
+				for _, bound := range []int{cursor, selStart, selEnd} {
+					if bound > pos && bound < stop {
+						stop = bound
+					}
+				}
+				if pos >= selStart && pos < selEnd {
+					emit(text[pos:stop], selected)
+				} else {
+					emit(text[pos:stop], style)
+				}
+				pos = stop
+			}
+			if pos < tok.End {
+				if cursor == pos {
+					emit(" ", sqlStyles.cursor)
+				}
+				newline()
+				pos++
+			}
+		}
+	}
+	if cursor >= len(text) {
+		emit(" ", sqlStyles.cursor)
+	}
+	newline()
+	return lines
+}
@@ -0,0 +1,46 @@
+package main
+
+import "windsurf-tui/sqllex"
+
+var commonFunctions = []string{
+	"COUNT", "SUM", "AVG", "MIN", "MAX", "COALESCE", "NULLIF", "LOWER", "UPPER",
+	"LENGTH", "SUBSTR", "TRIM", "LTRIM", "RTRIM", "REPLACE", "ROUND", "ABS", "CAST",
+	"ROW_NUMBER", "RANK", "DENSE_RANK", "LAG", "LEAD", "FIRST_VALUE", "LAST_VALUE",
+}
+
+var dialectFunctions = map[ConnectionType][]string{
+	ConnectionPostgres: {
+		"NOW", "CURRENT_DATE", "DATE_TRUNC", "DATE_PART", "EXTRACT", "AGE", "TO_CHAR",
+		"TO_DATE", "TO_TIMESTAMP", "STRING_AGG", "ARRAY_AGG", "JSON_AGG", "JSONB_AGG",
+		"JSONB_BUILD_OBJECT", "JSON_BUILD_OBJECT", "UNNEST", "GENERATE_SERIES", "CONCAT",
+		"SPLIT_PART", "REGEXP_REPLACE", "GREATEST", "LEAST", "GEN_RANDOM_UUID", "PG_SIZE_PRETTY",
+	},
+	ConnectionSQLite: {
+		"DATE", "TIME", "DATETIME", "JULIANDAY", "STRFTIME", "IFNULL", "INSTR", "PRINTF",
+		"GROUP_CONCAT", "TYPEOF", "RANDOM", "HEX", "QUOTE", "JSON_EXTRACT", "JSON_OBJECT",
+		"LAST_INSERT_ROWID", "CHANGES", "TOTAL",
+	},
+	ConnectionMySQL: {
+		"NOW", "CURDATE", "CURTIME", "DATE_FORMAT", "DATE_ADD", "DATE_SUB", "DATEDIFF",
+		"IFNULL", "IF", "CONCAT", "CONCAT_WS", "GROUP_CONCAT", "SUBSTRING_INDEX", "LOCATE",
+		"JSON_EXTRACT", "JSON_OBJECT", "LAST_INSERT_ID", "UUID", "GREATEST", "LEAST",
+	},
+}
+
+// isSQLKeyword reports whether word is a keyword of the dialect; unknown
+// dialects fall back to PostgreSQL.
+func isSQLKeyword(dialect ConnectionType, word string) bool {
+	return sqllex.IsKeyword(sqllex.Dialect(dialect), word)
+}
+
+func sqlKeywordList(dialect ConnectionType) []string {
+	return sqllex.Keywords(sqllex.Dialect(dialect))
+}
+
+func sqlFunctionList(dialect ConnectionType) []string {
+	functions, ok := dialectFunctions[dialect]
+	if !ok {
+		functions = dialectFunctions[ConnectionPostgres]
+	}
+	return append(append([]string(nil), commonFunctions...), functions...)
+}
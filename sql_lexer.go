@@ -0,0 +1,8 @@
+package main
+
+import "windsurf-tui/sqllex"
+
+// tokenizeSQL splits text with the lexical rules of a connection's dialect.
+func tokenizeSQL(text string, dialect ConnectionType) []sqllex.Token {
+	return sqllex.Tokenize(text, sqllex.Dialect(dialect))
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"strings"
+	"unicode"
+
+	"windsurf-tui/sqllex"
+)
+
+// sqlStatement is one statement of a script; Start and End are byte offsets
+// of its text (without the terminating semicolon) in the script.
+type sqlStatement struct {
+	Text  string
+	Start int
+	End   int
+}
+
+// splitStatements breaks a script at top-level semicolons. Semicolons inside
+// quotes, dollar-quoted bodies, comments and BEGIN ... END blocks (trigger and
+// procedure bodies) do not end a statement. Comments before a statement are
+// not part of it, and statements made only of comments are dropped.
+func splitStatements(script string, dialect ConnectionType) []sqlStatement {
+	return splitScript(script, dialect, false)
+}
+
+// splitScript is splitStatements; with blankLines an empty line outside
+// parentheses and blocks also ends a statement, the way statements are
+// told apart in the editor.
+func splitScript(script string, dialect ConnectionType, blankLines bool) []sqlStatement {
+	tokens := tokenizeSQL(script, dialect)
+
+	var statements []sqlStatement
+	start, depth, parens := -1, 0, 0
+	// first and prev are the token indexes of the first and the previous
+	// significant token of the current statement.
+	first, prev := -1, -1
+	flush := func(end int) {
+		if start >= 0 {
+			text := strings.TrimRightFunc(script[start:end], unicode.IsSpace)
+			statements = append(statements, sqlStatement{Text: text, Start: start, End: start + len(text)})
+		}
+		start, first, prev = -1, -1, -1
+	}
+
+	for idx := 0; idx < len(tokens); idx++ {
+		tok := tokens[idx]
+		if blankLines && tok.Kind == sqllex.Space && depth == 0 && parens == 0 && strings.Count(tok.Text, "\n") > 1 {
+			flush(tok.Start)
+			continue
+		}
+		if tok.IsTrivia() {
+			continue
+		}
+		if tok.Kind == sqllex.Punct && tok.Text == ";" && depth == 0 {
+			flush(tok.Start)
+			parens = 0
+			continue
+		}
+		if start < 0 {
+			start, first = tok.Start, idx
+		}
+		before := prev
+		prev = idx
+
+		switch {
+		case tok.Kind == sqllex.Punct && tok.Text == "(":
+			parens++
+		case tok.Kind == sqllex.Punct && tok.Text == ")" && parens > 0:
+			parens--
+		case tok.IsKeyword("BEGIN"):
+			// A column may be called begin; a block only opens a statement,
+			// a CREATE body, an AS clause or another block.
+			opener := idx == first || depth > 0 || tokens[first].IsKeyword("CREATE") ||
+				before >= 0 && tokens[before].IsKeyword("AS")
+			if opener && opensBlock(tokens, idx) {
+				depth++
+			}
+		case tok.IsKeyword("CASE"):
+			depth++
+		case tok.IsKeyword("END") && depth > 0:
+			next := sqllex.NextSignificant(tokens, idx+1)
+			if next < len(tokens) && tokens[next].IsKeyword("IF", "LOOP", "WHILE", "REPEAT", "FOR") {
+				// MySQL END IF / END LOOP close openers that are not counted.
+				idx = next
+				continue
+			}
+			depth--
+			if next < len(tokens) && tokens[next].IsKeyword("CASE") {
+				idx = next
+			}
+		}
+	}
+	flush(len(script))
+	return statements
+}
+
+// statementAt returns the statement that contains offset (a byte offset in
+// script), statements being separated by semicolons or blank lines; between
+// two statements it picks the one that ends before offset.
+func statementAt(script string, offset int, dialect ConnectionType) (sqlStatement, bool) {
+	statements := splitScript(script, dialect, true)
+	if len(statements) == 0 {
+		return sqlStatement{}, false
+	}
+	found := statements[0]
+	for _, statement := range statements[1:] {
+		if statement.Start > offset {
+			break
+		}
+		found = statement
+	}
+	return found, true
+}
+
+// opensBlock tells a compound-statement BEGIN from one that starts a
+// transaction (BEGIN; BEGIN TRANSACTION; BEGIN IMMEDIATE ...).
+func opensBlock(tokens []sqllex.Token, idx int) bool {
+	next := sqllex.NextSignificant(tokens, idx+1)
+	if next >= len(tokens) {
+		return false
+	}
+	tok := tokens[next]
+	if tok.Kind == sqllex.Punct && tok.Text == ";" {
+		return false
+	}
+	return !tok.IsKeyword("TRANSACTION", "WORK", "TRAN", "DEFERRED", "IMMEDIATE", "EXCLUSIVE", "ISOLATION", "READ", "NOT")
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitStatements(t *testing.T) {
+	tests := []struct {
+		name    string
+		dialect ConnectionType
+		script  string
+		want    []string
+	}{
+		{
+			name:    "semicolons",
+			dialect: ConnectionPostgres,
+			script:  "select 1; select 2;\n\nselect 3",
+			want:    []string{"select 1", "select 2", "select 3"},
+		},
+		{
+			name:    "semicolon in string",
+			dialect: ConnectionPostgres,
+			script:  "select ';' from t; select 2",
+			want:    []string{"select ';' from t", "select 2"},
+		},
+		{
+			name:    "mysql backslash escape",
+			dialect: ConnectionMySQL,
+			script:  `select 'a\';b' from t; select 2`,
+			want:    []string{`select 'a\';b' from t`, "select 2"},
+		},
+		{
+			name:    "postgres standard string",
+			dialect: ConnectionPostgres,
+			script:  `select 'a\'; select 2`,
+			want:    []string{`select 'a\'`, "select 2"},
+		},
+		{
+			name:    "postgres escape string",
+			dialect: ConnectionPostgres,
+			script:  `select E'a\';b'; select 2`,
+			want:    []string{`select E'a\';b'`, "select 2"},
+		},
+		{
+			name:    "mysql double quoted string",
+			dialect: ConnectionMySQL,
+			script:  `select "a\";b"; select 2`,
+			want:    []string{`select "a\";b"`, "select 2"},
+		},
+		{
+			name:    "mysql hash comment",
+			dialect: ConnectionMySQL,
+			script:  "select 1 # c; x\n; select 2",
+			want:    []string{"select 1 # c; x", "select 2"},
+		},
+		{
+			name:    "postgres hash operator",
+			dialect: ConnectionPostgres,
+			script:  "select 1 # 2; select 3",
+			want:    []string{"select 1 # 2", "select 3"},
+		},
+		{
+			name:    "line and block comments",
+			dialect: ConnectionSQLite,
+			script:  "-- lead;\nselect 1 /* ; */; -- only a comment;\n",
+			want:    []string{"select 1 /* ; */"},
+		},
+		{
+			name:    "dollar quoted body",
+			dialect: ConnectionPostgres,
+			script:  "create function f() returns int as $$ select 1; $$ language sql; select 2",
+			want:    []string{"create function f() returns int as $$ select 1; $$ language sql", "select 2"},
+		},
+		{
+			name:    "column named begin",
+			dialect: ConnectionPostgres,
+			script:  "select begin from t; select 2",
+			want:    []string{"select begin from t", "select 2"},
+		},
+		{
+			name:    "transaction begin",
+			dialect: ConnectionSQLite,
+			script:  "begin; insert into t values (1); commit",
+			want:    []string{"begin", "insert into t values (1)", "commit"},
+		},
+		{
+			name:    "trigger body",
+			dialect: ConnectionSQLite,
+			script:  "create trigger tr after insert on t begin update u set n = n + 1; delete from v; end; select 1",
+			want:    []string{"create trigger tr after insert on t begin update u set n = n + 1; delete from v; end", "select 1"},
+		},
+		{
+			name:    "mysql procedure with nested blocks",
+			dialect: ConnectionMySQL,
+			script:  "create procedure p() begin if x then begin select 1; end; end if; select 2; end; select 3",
+			want:    []string{"create procedure p() begin if x then begin select 1; end; end if; select 2; end", "select 3"},
+		},
+		{
+			name:    "case expression",
+			dialect: ConnectionPostgres,
+			script:  "select case when a then 1 end from t; select 2",
+			want:    []string{"select case when a then 1 end from t", "select 2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, statement := range splitStatements(tt.script, tt.dialect) {
+				if tt.script[statement.Start:statement.End] != statement.Text {
+					t.Errorf("offsets %d..%d do not match %q", statement.Start, statement.End, statement.Text)
+				}
+				got = append(got, statement.Text)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("splitStatements(%q) = %q, want %q", tt.script, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStatementAt(t *testing.T) {
+	script := "select 1\n\nselect 'a\n\nb'\nfrom t;\nselect (\n\n2)\n"
+	tests := []struct {
+		name   string
+		offset int
+		want   string
+	}{
+		{name: "first", offset: 3, want: "select 1"},
+		{name: "blank line between", offset: 9, want: "select 1"},
+		{name: "blank line in string", offset: 12, want: "select 'a\n\nb'\nfrom t"},
+		{name: "blank line in parentheses", offset: len(script) - 2, want: "select (\n\n2)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := statementAt(script, tt.offset, ConnectionPostgres)
+			if !ok || got.Text != tt.want {
+				t.Errorf("statementAt(%d) = %q, %v; want %q", tt.offset, got.Text, ok, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,379 @@
+// Package sqlformat reflows SQL text for reading, in the editor or in
+// generated scripts.
+package sqlformat
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"windsurf-tui/sqllex"
+)
+
+const indentWidth = 4
+
+// clauseRule is a keyword sequence that starts a clause. Clauses begin
+// a new line; in list clauses each comma does too, aligned with the first
+// item unless the clause lists whole blocks (WITH).
+type clauseRule struct {
+	words []string
+	list  bool
+	align bool
+}
+
+var clauses = []clauseRule{
+	{words: []string{"ON", "DUPLICATE", "KEY", "UPDATE"}, list: true, align: true},
+	{words: []string{"LEFT", "OUTER", "JOIN"}},
+	{words: []string{"RIGHT", "OUTER", "JOIN"}},
+	{words: []string{"FULL", "OUTER", "JOIN"}},
+	{words: []string{"NATURAL", "LEFT", "JOIN"}},
+	{words: []string{"NATURAL", "JOIN"}},
+	{words: []string{"LEFT", "JOIN"}},
+	{words: []string{"RIGHT", "JOIN"}},
+	{words: []string{"FULL", "JOIN"}},
+	{words: []string{"INNER", "JOIN"}},
+	{words: []string{"CROSS", "JOIN"}},
+	{words: []string{"JOIN"}},
+	{words: []string{"STRAIGHT_JOIN"}},
+	{words: []string{"GROUP", "BY"}, list: true, align: true},
+	{words: []string{"ORDER", "BY"}, list: true, align: true},
+	{words: []string{"UNION", "ALL"}},
+	{words: []string{"UNION"}},
+	{words: []string{"INTERSECT"}},
+	{words: []string{"EXCEPT"}},
+	{words: []string{"INSERT", "INTO"}},
+	{words: []string{"DELETE", "FROM"}},
+	{words: []string{"ON", "CONFLICT"}},
+	{words: []string{"SELECT"}, list: true, align: true},
+	{words: []string{"FROM"}, list: true, align: true},
+	{words: []string{"WHERE"}},
+	{words: []string{"HAVING"}},
+	{words: []string{"WINDOW"}, list: true, align: true},
+	{words: []string{"LIMIT"}},
+	{words: []string{"OFFSET"}},
+	{words: []string{"FETCH"}},
+	{words: []string{"VALUES"}, list: true, align: true},
+	{words: []string{"SET"}, list: true, align: true},
+	{words: []string{"RETURNING"}, list: true, align: true},
+	{words: []string{"WITH"}, list: true},
+}
+
+// layoutBlock is a statement, a parenthesised subquery or a CASE
+// expression. indent is the column its clauses (or WHEN/ELSE) start at and
+// close the column of its closing parenthesis or END.
+type layoutBlock struct {
+	isCase     bool
+	indent     int
+	close      int
+	started    bool
+	clause     string
+	list       bool
+	listIndent int
+	parens     int
+	between    bool
+	sawUpdate  bool
+}
+
+// formatter writes the formatted text. Line breaks are deferred until
+// the next token so that a comment that followed a token on the same line
+// stays there.
+type formatter struct {
+	dialect    sqllex.Dialect
+	out        strings.Builder
+	col        int
+	lineIndent int
+	pending    int
+	blank      bool
+	blocks     []*layoutBlock
+}
+
+// Format lays out a script one clause per line with keywords in upper
+// case: select lists and other comma lists are aligned under their first
+// item, AND/OR of WHERE, HAVING and JOIN conditions start their own line,
+// and subqueries and CASE expressions are indented blocks. Comments,
+// strings and dollar-quoted bodies are kept as written.
+func Format(text string, dialect sqllex.Dialect) string {
+	f := &formatter{dialect: dialect, pending: -1}
+	f.blocks = []*layoutBlock{{}}
+	tokens := sqllex.Tokenize(text, dialect)
+
+	prev := -1
+	for idx := 0; idx < len(tokens); idx++ {
+		tok := tokens[idx]
+		if tok.Kind == sqllex.Space {
+			continue
+		}
+		if tok.Kind == sqllex.Comment {
+			f.writeComment(tok, idx > 0 && strings.Contains(tokens[idx-1].Text, "\n"))
+			continue
+		}
+		idx = f.writeToken(tokens, idx, prev, f.spaceBefore(tokens, idx, prev))
+		prev = idx
+	}
+	return strings.TrimRight(f.out.String(), " \t\n")
+}
+
+func (f *formatter) block() *layoutBlock {
+	return f.blocks[len(f.blocks)-1]
+}
+
+// breakTo starts the next token on a new line at column indent.
+func (f *formatter) breakTo(indent int) {
+	if f.out.Len() > 0 {
+		f.pending = indent
+	}
+}
+
+func (f *formatter) flush() {
+	if f.pending < 0 {
+		return
+	}
+	if f.blank {
+		f.out.WriteString("\n")
+		f.blank = false
+	}
+	f.out.WriteString("\n" + strings.Repeat(" ", f.pending))
+	f.col, f.lineIndent = f.pending, f.pending
+	f.pending = -1
+}
+
+func (f *formatter) write(text string, space bool) {
+	if f.pending >= 0 {
+		f.flush()
+	} else if space && f.out.Len() > 0 {
+		f.out.WriteString(" ")
+		f.col++
+	}
+	f.out.WriteString(text)
+	if idx := strings.LastIndexByte(text, '\n'); idx >= 0 {
+		f.col = utf8.RuneCountInString(text[idx+1:])
+		f.lineIndent = 0
+		return
+	}
+	f.col += utf8.RuneCountInString(text)
+}
+
+// writeComment keeps a comment on the line it was on: one that started its
+// own line still does, one that trailed a token follows it.
+func (f *formatter) writeComment(tok sqllex.Token, ownLine bool) {
+	text := strings.TrimRight(tok.Text, " \t\r")
+	if ownLine && f.pending < 0 {
+		f.breakTo(f.block().indent)
+	}
+	if ownLine || f.out.Len() == 0 {
+		f.write(text, false)
+	} else {
+		f.out.WriteString(" " + text)
+		f.col += 1 + utf8.RuneCountInString(text)
+	}
+	if strings.HasPrefix(text, "--") && f.pending < 0 {
+		f.breakTo(f.lineIndent)
+	}
+}
+
+// writeToken writes tokens[idx], or the keyword sequence starting there,
+// and returns the index of the last token it consumed.
+func (f *formatter) writeToken(tokens []sqllex.Token, idx, prev int, space bool) int {
+	tok := tokens[idx]
+	block := f.block()
+
+	if tok.Kind == sqllex.Punct {
+		switch tok.Text {
+		case ";":
+			if len(f.blocks) == 1 && block.parens == 0 {
+				f.write(";", false)
+				f.blocks = []*layoutBlock{{}}
+				f.breakTo(0)
+				f.blank = true
+				return idx
+			}
+		case ",":
+			f.write(",", false)
+			if block.list && block.parens == 0 {
+				f.breakTo(block.listIndent)
+			}
+			return idx
+		case "(":
+			f.write("(", space)
+			next := sqllex.NextSignificant(tokens, idx+1)
+			if next < len(tokens) && tokens[next].IsKeyword("SELECT", "WITH") {
+				f.blocks = append(f.blocks, &layoutBlock{
+					indent: f.lineIndent + indentWidth,
+					close:  f.lineIndent,
+				})
+				f.breakTo(f.lineIndent + indentWidth)
+			} else {
+				block.parens++
+			}
+			return idx
+		case ")":
+			if block.parens == 0 && !block.isCase && len(f.blocks) > 1 {
+				f.blocks = f.blocks[:len(f.blocks)-1]
+				f.breakTo(block.close)
+				f.write(")", false)
+				return idx
+			}
+			if block.parens > 0 {
+				block.parens--
+			}
+			f.write(")", false)
+			return idx
+		}
+		f.write(tok.Text, space)
+		return idx
+	}
+
+	if tok.Kind != sqllex.Word || f.isIdentifier(tokens, idx) {
+		f.write(tok.Text, space)
+		block.started = true
+		return idx
+	}
+
+	word := strings.ToUpper(tok.Text)
+	if block.parens == 0 {
+		if clause, last, ok := f.clauseAt(tokens, idx, prev); ok {
+			if block.started {
+				f.breakTo(block.indent)
+			}
+			f.write(strings.Join(clause.words, " "), space)
+			block.started = true
+			block.clause = clause.words[len(clause.words)-1]
+			block.list = clause.list
+			block.listIndent = block.indent
+			if clause.align {
+				block.listIndent = f.col + 1
+			}
+			block.between = false
+			return last
+		}
+
+		switch word {
+		case "AND", "OR":
+			if block.between && word == "AND" {
+				block.between = false
+			} else if block.clause == "WHERE" || block.clause == "HAVING" || block.clause == "JOIN" {
+				f.breakTo(block.indent + 2)
+			}
+		case "BETWEEN":
+			block.between = true
+		case "UPDATE":
+			block.sawUpdate = true
+		case "WHEN", "ELSE":
+			if block.isCase {
+				f.breakTo(block.indent)
+			}
+		case "END":
+			if block.isCase {
+				f.blocks = f.blocks[:len(f.blocks)-1]
+				f.breakTo(block.close)
+				f.write(word, false)
+				return idx
+			}
+		}
+	}
+
+	f.write(f.keywordCase(tok.Text), space)
+	block.started = true
+	if word == "CASE" {
+		caseCol := f.col - len("CASE")
+		f.blocks = append(f.blocks, &layoutBlock{
+			isCase:  true,
+			indent:  caseCol + indentWidth,
+			close:   caseCol,
+			started: true,
+		})
+	}
+	return idx
+}
+
+// clauseAt matches a clause keyword sequence at idx. The words of a
+// sequence may only be separated by whitespace, so comments between them
+// are not lost.
+func (f *formatter) clauseAt(tokens []sqllex.Token, idx, prev int) (clauseRule, int, bool) {
+	block := f.block()
+	if block.isCase {
+		return clauseRule{}, idx, false
+	}
+	for _, clause := range clauses {
+		last, ok := matchWords(tokens, idx, clause.words)
+		if !ok {
+			continue
+		}
+		switch clause.words[0] {
+		case "WITH":
+			// WITH TIME ZONE, WITH CHECK OPTION... only open a query at its start.
+			ok = !block.started
+		case "SET":
+			ok = block.sawUpdate
+		case "FROM":
+			ok = prev < 0 || !tokens[prev].IsKeyword("DISTINCT")
+		case "SELECT":
+			// EXPLAIN SELECT and GRANT SELECT, INSERT stay on one line.
+			ok = prev < 0 || !tokens[prev].IsKeyword("EXPLAIN", "ANALYZE", "VERBOSE", "GRANT", "REVOKE") && tokens[prev].Text != ","
+		}
+		if ok {
+			return clause, last, true
+		}
+	}
+	return clauseRule{}, idx, false
+}
+
+func matchWords(tokens []sqllex.Token, idx int, words []string) (int, bool) {
+	last := idx
+	for pos, word := range words {
+		if pos > 0 {
+			if idx >= len(tokens) || tokens[idx].Kind != sqllex.Space {
+				return 0, false
+			}
+			idx++
+		}
+		if idx >= len(tokens) || !tokens[idx].IsKeyword(word) {
+			return 0, false
+		}
+		last = idx
+		idx++
+	}
+	return last, true
+}
+
+// isIdentifier tells a word used as a name from a keyword: qualified names
+// (t.date), named parameters (:limit) and MySQL variables (@rows) keep
+// their case and never start a clause.
+func (f *formatter) isIdentifier(tokens []sqllex.Token, idx int) bool {
+	if idx > 0 && tokens[idx-1].Kind == sqllex.Punct {
+		switch tokens[idx-1].Text {
+		case ".", "@":
+			return true
+		case ":":
+			return idx < 2 || tokens[idx-2].Text != ":"
+		}
+	}
+	return idx+1 < len(tokens) && tokens[idx+1].Kind == sqllex.Punct && tokens[idx+1].Text == "."
+}
+
+func (f *formatter) keywordCase(word string) string {
+	if sqllex.IsKeyword(f.dialect, word) {
+		return strings.ToUpper(word)
+	}
+	return word
+}
+
+// spaceBefore keeps the spacing of the source between tokens, except that
+// a comma is always followed by a space and parentheses hug their content.
+func (f *formatter) spaceBefore(tokens []sqllex.Token, idx, prev int) bool {
+	if prev < 0 {
+		return false
+	}
+	tok, before := tokens[idx], tokens[prev]
+	if tok.Kind == sqllex.Punct && (tok.Text == ")" || tok.Text == "," || tok.Text == ";") {
+		return false
+	}
+	if before.Kind == sqllex.Punct {
+		switch before.Text {
+		case "(":
+			return false
+		case ",":
+			return true
+		}
+	}
+	return tokens[idx-1].IsTrivia()
+}
@@ -0,0 +1,168 @@
+package sqlformat
+
+import (
+	"testing"
+
+	"windsurf-tui/sqllex"
+)
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		name    string
+		dialect sqllex.Dialect
+		in      string
+		want    string
+	}{
+		{
+			name:    "clauses and aligned lists",
+			dialect: sqllex.Postgres,
+			in:      "select a, b as bee, count(*) from t join u on u.id = t.id and u.x > 1 where a = 1 and b between 1 and 2 group by a, b order by a desc limit 10",
+			want: "SELECT a,\n" +
+				"       b AS bee,\n" +
+				"       count(*)\n" +
+				"FROM t\n" +
+				"JOIN u ON u.id = t.id\n" +
+				"  AND u.x > 1\n" +
+				"WHERE a = 1\n" +
+				"  AND b BETWEEN 1 AND 2\n" +
+				"GROUP BY a,\n" +
+				"         b\n" +
+				"ORDER BY a DESC\n" +
+				"LIMIT 10",
+		},
+		{
+			name:    "case block",
+			dialect: sqllex.Postgres,
+			in:      "select id, case when x > 1 then 'big' when x = 1 then 'one' else 'small' end as size from t",
+			want: "SELECT id,\n" +
+				"       CASE\n" +
+				"           WHEN x > 1 THEN 'big'\n" +
+				"           WHEN x = 1 THEN 'one'\n" +
+				"           ELSE 'small'\n" +
+				"       END AS size\n" +
+				"FROM t",
+		},
+		{
+			name:    "nested subqueries",
+			dialect: sqllex.Postgres,
+			in:      "select a from t where id in (select id from u where y in (select y from v where z = 1)) and b = 2",
+			want: "SELECT a\n" +
+				"FROM t\n" +
+				"WHERE id IN (\n" +
+				"    SELECT id\n" +
+				"    FROM u\n" +
+				"    WHERE y IN (\n" +
+				"        SELECT y\n" +
+				"        FROM v\n" +
+				"        WHERE z = 1\n" +
+				"    )\n" +
+				")\n" +
+				"  AND b = 2",
+		},
+		{
+			name:    "with",
+			dialect: sqllex.Postgres,
+			in:      "with a as (select 1 as n), b as (select n from a) select * from a join b on a.n = b.n",
+			want: "WITH a AS (\n" +
+				"    SELECT 1 AS n\n" +
+				"),\n" +
+				"b AS (\n" +
+				"    SELECT n\n" +
+				"    FROM a\n" +
+				")\n" +
+				"SELECT *\n" +
+				"FROM a\n" +
+				"JOIN b ON a.n = b.n",
+		},
+		{
+			name:    "casts",
+			dialect: sqllex.Postgres,
+			in:      "select x::text, y::timestamp with time zone from t where z::int > 1",
+			want: "SELECT x::TEXT,\n" +
+				"       y::TIMESTAMP WITH TIME zone\n" +
+				"FROM t\n" +
+				"WHERE z::INT > 1",
+		},
+		{
+			name:    "named parameters and qualified names keep their case",
+			dialect: sqllex.Postgres,
+			in:      "select t.date from t where t.limit = :limit",
+			want: "SELECT t.date\n" +
+				"FROM t\n" +
+				"WHERE t.limit = :limit",
+		},
+		{
+			name:    "trailing comments",
+			dialect: sqllex.Postgres,
+			in:      "select a, -- first\n  b -- second\nfrom t",
+			want: "SELECT a, -- first\n" +
+				"       b -- second\n" +
+				"FROM t",
+		},
+		{
+			name:    "own-line comments",
+			dialect: sqllex.Postgres,
+			in:      "-- header\nselect a\n/* filter below */\nfrom t\n-- only ones\nwhere x = 1",
+			want: "-- header\n" +
+				"SELECT a\n" +
+				"/* filter below */\n" +
+				"FROM t\n" +
+				"-- only ones\n" +
+				"WHERE x = 1",
+		},
+		{
+			name:    "dollar-quoted body",
+			dialect: sqllex.Postgres,
+			in:      "create function f() returns int as $$\nselect   1;\n$$ language sql;\nselect 2",
+			want: "CREATE FUNCTION f() RETURNS INT AS $$\nselect   1;\n$$ LANGUAGE sql;\n" +
+				"\n" +
+				"SELECT 2",
+		},
+		{
+			name:    "insert with upsert",
+			dialect: sqllex.Postgres,
+			in:      "insert into t (a, b) values (1, 'x'), (2, 'y') on conflict (a) do update set b = excluded.b returning *",
+			want: "INSERT INTO t (a, b)\n" +
+				"VALUES (1, 'x'),\n" +
+				"       (2, 'y')\n" +
+				"ON CONFLICT (a) DO UPDATE\n" +
+				"SET b = excluded.b\n" +
+				"RETURNING *",
+		},
+		{
+			name:    "update",
+			dialect: sqllex.SQLite,
+			in:      "update t set a = 1, b = 2 where c = 3",
+			want: "UPDATE t\n" +
+				"SET a = 1,\n" +
+				"    b = 2\n" +
+				"WHERE c = 3",
+		},
+		{
+			name:    "mysql hash comment and escapes",
+			dialect: sqllex.MySQL,
+			in:      "select 'it\\'s' from t # where x\nwhere y = 1",
+			want: "SELECT 'it\\'s'\n" +
+				"FROM t # where x\n" +
+				"WHERE y = 1",
+		},
+		{
+			name:    "explain stays on one line",
+			dialect: sqllex.Postgres,
+			in:      "explain select 1",
+			want:    "EXPLAIN SELECT 1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Format(tt.in, tt.dialect)
+			if got != tt.want {
+				t.Errorf("Format(%q)\ngot:\n%s\nwant:\n%s", tt.in, got, tt.want)
+			}
+			if again := Format(got, tt.dialect); again != got {
+				t.Errorf("Format is not idempotent:\n%s\nthen:\n%s", got, again)
+			}
+		})
+	}
+}
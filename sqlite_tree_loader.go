@@ -1,29 +1,34 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"path/filepath"
-	"sort"
 	"strconv"
 	"strings"
+	"sync"
 
 	tea "github.com/charmbracelet/bubbletea"
 )
 
 type SQLiteTreeLoader struct {
-	db       *sql.DB
-	connInfo *ConnectionInfo
+	db         *sql.DB
+	connInfo   *ConnectionInfo
+	keyColumns map[string][]string
+	// mu guards keyColumns, filled from background tasks.
+	mu sync.Mutex
 }
 
 func NewSQLiteTreeLoader(db *sql.DB, connInfo *ConnectionInfo) *SQLiteTreeLoader {
 	return &SQLiteTreeLoader{
-		db:       db,
-		connInfo: connInfo,
+		db:         db,
+		connInfo:   connInfo,
+		keyColumns: make(map[string][]string),
 	}
 }
 
-func (stl *SQLiteTreeLoader) LoadTree(serverName string) (*TreeNode, error) {
+func (stl *SQLiteTreeLoader) LoadTree(ctx context.Context, serverName string) (*TreeNode, error) {
 	root := &TreeNode{
 		ID:       "root",
 		Name:     "SQLite Connections",
@@ -62,9 +67,9 @@ func (stl *SQLiteTreeLoader) LoadTree(serverName string) (*TreeNode, error) {
 	return root, nil
 }
 
-func (stl *SQLiteTreeLoader) LoadTreeAsync(serverName string) tea.Cmd {
+func (stl *SQLiteTreeLoader) LoadTreeAsync(ctx context.Context, serverName string) tea.Cmd {
 	return func() tea.Msg {
-		tree, err := stl.LoadTree(serverName)
+		tree, err := stl.LoadTree(ctx, serverName)
 		if err != nil {
 			return ErrMsg{err}
 		}
@@ -72,7 +77,7 @@ func (stl *SQLiteTreeLoader) LoadTreeAsync(serverName string) tea.Cmd {
 	}
 }
 
-func (stl *SQLiteTreeLoader) LoadChildren(node *TreeNode) error {
+func (stl *SQLiteTreeLoader) LoadChildren(ctx context.Context, node *TreeNode) error {
 	if node == nil || len(node.Children) > 0 {
 		return nil
 	}
@@ -88,7 +93,7 @@ func (stl *SQLiteTreeLoader) LoadChildren(node *TreeNode) error {
 		}
 	case NodeSchema:
 		if len(parts) >= 2 {
-			tables, err := stl.loadTables(parts[0], parts[1])
+			tables, err := stl.loadTables(ctx, parts[0], parts[1])
 			if err != nil {
 				return err
 			}
@@ -99,7 +104,7 @@ func (stl *SQLiteTreeLoader) LoadChildren(node *TreeNode) error {
 		}
 	case NodeTable:
 		if len(parts) >= 3 {
-			columns, err := stl.loadColumns(parts[0], parts[2])
+			columns, err := stl.loadColumns(ctx, parts[0], parts[2])
 			if err != nil {
 				return err
 			}
@@ -128,7 +133,7 @@ func (stl *SQLiteTreeLoader) loadSchemas(databaseName string) []*TreeNode {
 	return []*TreeNode{schema}
 }
 
-func (stl *SQLiteTreeLoader) loadTables(databaseName, schemaName string) ([]*TreeNode, error) {
+func (stl *SQLiteTreeLoader) loadTables(ctx context.Context, databaseName, schemaName string) ([]*TreeNode, error) {
 	const query = `
 		SELECT name
 		FROM sqlite_master
@@ -137,7 +142,7 @@ func (stl *SQLiteTreeLoader) loadTables(databaseName, schemaName string) ([]*Tre
 		ORDER BY name;
 	`
 
-	rows, err := stl.db.Query(query)
+	rows, err := stl.db.QueryContext(ctx, query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list tables: %w", err)
 	}
@@ -168,10 +173,10 @@ func (stl *SQLiteTreeLoader) loadTables(databaseName, schemaName string) ([]*Tre
 	return tables, nil
 }
 
-func (stl *SQLiteTreeLoader) loadColumns(databaseName, tableName string) ([]*TreeNode, error) {
+func (stl *SQLiteTreeLoader) loadColumns(ctx context.Context, databaseName, tableName string) ([]*TreeNode, error) {
 	query := fmt.Sprintf(`PRAGMA table_info(%s);`, quoteIdentifier(tableName))
 
-	rows, err := stl.db.Query(query)
+	rows, err := stl.db.QueryContext(ctx, query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list columns: %w", err)
 	}
@@ -211,162 +216,217 @@ func (stl *SQLiteTreeLoader) loadColumns(databaseName, tableName string) ([]*Tre
 	return columns, nil
 }
 
-func (stl *SQLiteTreeLoader) GetTableData(database, schema, table string, limit, offset int) ([]map[string]interface{}, error) {
+func (stl *SQLiteTreeLoader) GetTableData(ctx context.Context, database, schema, table string, opts TableQueryOptions) (*ResultSet, error) {
+	keyCols, err := stl.GetRowKeyColumns(ctx, database, schema, table)
+	if err != nil {
+		return nil, err
+	}
+
+	selectList := fmt.Sprintf(`rowid AS %s, *`, quoteIdentifier(rowIDColumn))
+	if len(keyCols) > 0 {
+		selectList = "*"
+	}
+
+	where, orderBy, args, offset := sqliteDialect.tableQueryClauses(keyCols, nil, opts)
 	query := fmt.Sprintf(`
-		SELECT rowid AS "__rowid", *
+		SELECT %s
 		FROM %s
+		%s
+		%s
 		LIMIT %d OFFSET %d
-	`, quoteIdentifier(table), limit, offset)
+	`, selectList, quoteIdentifier(table), where, orderBy, opts.Limit, offset)
 
-	rows, err := stl.db.Query(query)
+	rows, err := stl.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("query failed: %w", err)
 	}
 	defer rows.Close()
 
-	columns, err := rows.Columns()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get columns: %w", err)
-	}
-
-	var results []map[string]interface{}
-	for rows.Next() {
-		values := make([]interface{}, len(columns))
-		valuePtrs := make([]interface{}, len(columns))
-		for i := range values {
-			valuePtrs[i] = &values[i]
-		}
-
-		if err := rows.Scan(valuePtrs...); err != nil {
-			return nil, fmt.Errorf("scan failed: %w", err)
-		}
+	return scanResultSet(rows, nil)
+}
 
-		row := make(map[string]interface{})
-		for i, col := range columns {
-			row[col] = values[i]
-		}
-		results = append(results, row)
-	}
+func (stl *SQLiteTreeLoader) ApplyChanges(ctx context.Context, database, schema, table string, changes []PendingChange) error {
+	return applyChangesInTx(ctx, stl.db, changes, func(change PendingChange) (string, []interface{}, error) {
+		return stl.BuildChangeSQL(schema, table, change)
+	})
+}
 
-	return results, nil
+func (stl *SQLiteTreeLoader) ImportRows(ctx context.Context, database, schema, table string, rows []map[string]interface{}) (ImportResult, error) {
+	return importRowsInTx(ctx, stl.db, rows, func(change PendingChange) (string, []interface{}, error) {
+		return stl.BuildChangeSQL(schema, table, change)
+	})
 }
 
-func (stl *SQLiteTreeLoader) UpdateCell(database, schema, table, column, rowID string, value interface{}) error {
-	query := fmt.Sprintf(`
-		UPDATE %s
-		SET %s = ?
-		WHERE rowid = ?
-	`, quoteIdentifier(table), quoteIdentifier(column))
+func (stl *SQLiteTreeLoader) BuildChangeSQL(schema, table string, change PendingChange) (string, []interface{}, error) {
+	return sqliteDialect.changeSQL(quoteIdentifier(table), change)
+}
 
-	idValue, err := parseRowID(rowID)
-	if err != nil {
-		return err
+func (stl *SQLiteTreeLoader) TableExportSource(ctx context.Context, database, schema, table string, columns []string, view TableView) ExportSource {
+	keyColumns := func() ([]string, error) {
+		return stl.GetRowKeyColumns(ctx, database, schema, table)
 	}
+	source := tableExportSource(columns, view, sqliteDialect, keyColumns, func(opts TableQueryOptions) (*ResultSet, error) {
+		return stl.GetTableData(ctx, database, schema, table, opts)
+	})
+	source.Table = quoteIdentifier(table)
+	return source
+}
 
-	if _, err := stl.db.Exec(query, value, idValue); err != nil {
-		return fmt.Errorf("failed to update %s: %w", table, err)
-	}
-	return nil
+func (stl *SQLiteTreeLoader) QueryExportSource(results *ResultSet) ExportSource {
+	source := staticExportSource(results)
+	source.Table = quoteIdentifier(queryExportTable)
+	source.Dialect = sqliteDialect
+	return source
 }
 
-func (stl *SQLiteTreeLoader) InsertRow(database, schema, table string, values map[string]interface{}) error {
-	if len(values) == 0 {
-		return fmt.Errorf("no values provided for insert")
+func (stl *SQLiteTreeLoader) GetRowKeyColumns(ctx context.Context, database, schema, table string) ([]string, error) {
+	cacheKey := tableCacheKey(database, schema, table)
+	stl.mu.Lock()
+	cols, ok := stl.keyColumns[cacheKey]
+	stl.mu.Unlock()
+	if ok {
+		return cols, nil
 	}
 
-	columns := make([]string, 0, len(values))
-	for col := range values {
-		columns = append(columns, col)
+	rows, err := stl.db.QueryContext(ctx, fmt.Sprintf(`PRAGMA table_info(%s);`, quoteIdentifier(table)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover row key: %w", err)
 	}
-	sort.Strings(columns)
 
-	var placeholders []string
-	var args []interface{}
-	for range columns {
-		placeholders = append(placeholders, "?")
-	}
-	for _, col := range columns {
-		args = append(args, values[col])
+	notNull := make(map[string]bool)
+	pkOrder := make(map[int]string)
+	for rows.Next() {
+		var (
+			cid        int
+			name       string
+			dataType   string
+			notNullInt int
+			defaultVal sql.NullString
+			pk         int
+		)
+		if err := rows.Scan(&cid, &name, &dataType, &notNullInt, &defaultVal, &pk); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan column: %w", err)
+		}
+		notNull[name] = notNullInt != 0 || pk > 0
+		if pk > 0 {
+			pkOrder[pk] = name
+		}
 	}
+	rows.Close()
 
-	var quotedCols []string
-	for _, col := range columns {
-		quotedCols = append(quotedCols, quoteIdentifier(col))
+	var keyCols []string
+	for i := 1; i <= len(pkOrder); i++ {
+		keyCols = append(keyCols, pkOrder[i])
 	}
 
-	query := fmt.Sprintf(`
-		INSERT INTO %s (%s)
-		VALUES (%s)
-	`, quoteIdentifier(table), strings.Join(quotedCols, ", "), strings.Join(placeholders, ", "))
-
-	if _, err := stl.db.Exec(query, args...); err != nil {
-		return fmt.Errorf("failed to insert row: %w", err)
+	if len(keyCols) == 0 {
+		keyCols, err = stl.uniqueIndexColumns(ctx, table, notNull)
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	return nil
+	stl.mu.Lock()
+	stl.keyColumns[cacheKey] = keyCols
+	stl.mu.Unlock()
+	return keyCols, nil
 }
 
-func (stl *SQLiteTreeLoader) DeleteRow(database, schema, table, rowID string) error {
-	query := fmt.Sprintf(`
-		DELETE FROM %s
-		WHERE rowid = ?
-	`, quoteIdentifier(table))
-
-	idValue, err := parseRowID(rowID)
+func (stl *SQLiteTreeLoader) uniqueIndexColumns(ctx context.Context, table string, notNull map[string]bool) ([]string, error) {
+	rows, err := stl.db.QueryContext(ctx, fmt.Sprintf(`PRAGMA index_list(%s);`, quoteIdentifier(table)))
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("failed to list indexes: %w", err)
 	}
 
-	if _, err := stl.db.Exec(query, idValue); err != nil {
-		return fmt.Errorf("failed to delete row: %w", err)
+	var indexes []string
+	for rows.Next() {
+		var (
+			seq     int
+			name    string
+			unique  int
+			origin  string
+			partial int
+		)
+		if err := rows.Scan(&seq, &name, &unique, &origin, &partial); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan index: %w", err)
+		}
+		if unique == 1 && partial == 0 {
+			indexes = append(indexes, name)
+		}
 	}
-	return nil
-}
+	rows.Close()
 
-func (stl *SQLiteTreeLoader) ExecuteQuery(query string) ([]map[string]interface{}, error) {
-	query = strings.TrimSpace(query)
-	if query == "" {
-		return nil, nil
+	var best []string
+	for _, index := range indexes {
+		cols, err := stl.indexColumns(ctx, index)
+		if err != nil {
+			return nil, err
+		}
+		usable := len(cols) > 0
+		for _, col := range cols {
+			if col == "" || !notNull[col] {
+				usable = false
+				break
+			}
+		}
+		if usable && (best == nil || len(cols) < len(best)) {
+			best = cols
+		}
 	}
+	return best, nil
+}
 
-	rows, err := stl.db.Query(query)
+func (stl *SQLiteTreeLoader) indexColumns(ctx context.Context, index string) ([]string, error) {
+	rows, err := stl.db.QueryContext(ctx, fmt.Sprintf(`PRAGMA index_info(%s);`, quoteIdentifier(index)))
 	if err != nil {
-		return nil, fmt.Errorf("query failed: %w", err)
+		return nil, fmt.Errorf("failed to read index %s: %w", index, err)
 	}
 	defer rows.Close()
 
-	columns, err := rows.Columns()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get columns: %w", err)
-	}
-
-	var results []map[string]interface{}
+	var cols []string
 	for rows.Next() {
-		values := make([]interface{}, len(columns))
-		valuePtrs := make([]interface{}, len(columns))
-		for i := range values {
-			valuePtrs[i] = &values[i]
-		}
-
-		if err := rows.Scan(valuePtrs...); err != nil {
-			return nil, fmt.Errorf("scan failed: %w", err)
+		var seqno, cid int
+		var name sql.NullString
+		if err := rows.Scan(&seqno, &cid, &name); err != nil {
+			return nil, fmt.Errorf("failed to scan index column: %w", err)
 		}
+		cols = append(cols, name.String)
+	}
+	return cols, nil
+}
 
-		row := make(map[string]interface{})
-		for i, col := range columns {
-			row[col] = values[i]
+func normalizeSQLiteKeyArgs(key RowKey, args []interface{}) []interface{} {
+	if !key.IsPhysical() || len(args) == 0 {
+		return args
+	}
+	if s, ok := args[0].(string); ok {
+		if id, err := parseRowID(s); err == nil {
+			return []interface{}{id}
 		}
-		results = append(results, row)
 	}
+	return args
+}
+
+func (stl *SQLiteTreeLoader) BeginTransaction(ctx context.Context) (*QueryTransaction, error) {
+	return beginQueryTransaction(ctx, stl.db)
+}
+
+func (stl *SQLiteTreeLoader) OpenSession(ctx context.Context, tx *QueryTransaction) (*QuerySession, error) {
+	return openQuerySession(ctx, stl.db, tx)
+}
 
-	return results, nil
+func (stl *SQLiteTreeLoader) ExecuteStatement(ctx context.Context, session *QuerySession, statement string, args ...interface{}) StatementResult {
+	return executeStatement(ctx, session.queryer, ConnectionSQLite, statement, nil, args...)
 }
 
-func (stl *SQLiteTreeLoader) GetTableRowCount(database, schema, table string) (int64, error) {
-	query := fmt.Sprintf(`SELECT COUNT(*) FROM %s`, quoteIdentifier(table))
+func (stl *SQLiteTreeLoader) GetTableRowCount(ctx context.Context, database, schema, table string, view TableView) (int64, error) {
+	conditions, args := sqliteDialect.filterConditions(view, 1)
+	query := fmt.Sprintf(`SELECT COUNT(*) FROM %s %s`, quoteIdentifier(table), whereClause(conditions))
 
 	var count int64
-	if err := stl.db.QueryRow(query).Scan(&count); err != nil {
+	if err := stl.db.QueryRowContext(ctx, query, args...).Scan(&count); err != nil {
 		return 0, fmt.Errorf("failed to count rows: %w", err)
 	}
 
@@ -0,0 +1,100 @@
+package sqllex
+
+import (
+	"sort"
+	"strings"
+)
+
+// Dialect selects the lexical rules and keywords of a database. Its values
+// are the connection types of the application.
+type Dialect string
+
+const (
+	Postgres Dialect = "postgres"
+	SQLite   Dialect = "sqlite"
+	MySQL    Dialect = "mysql"
+)
+
+var commonKeywords = []string{
+	"ADD", "ALL", "ALTER", "AND", "ANY", "AS", "ASC", "BEGIN", "BETWEEN", "BY",
+	"CASCADE", "CASE", "CAST", "CHECK", "COLLATE", "COLUMN", "COMMIT", "CONSTRAINT",
+	"CREATE", "CROSS", "CURRENT_DATE", "CURRENT_TIME", "CURRENT_TIMESTAMP", "DATABASE",
+	"DEFAULT", "DELETE", "DESC", "DISTINCT", "DROP", "ELSE", "END", "ESCAPE", "EXCEPT",
+	"EXISTS", "EXPLAIN", "FALSE", "FOREIGN", "FROM", "FULL", "GROUP", "HAVING", "IF",
+	"IN", "INDEX", "INNER", "INSERT", "INTERSECT", "INTO", "IS", "JOIN", "KEY", "LEFT",
+	"LIKE", "LIMIT", "NATURAL", "NOT", "NULL", "OFFSET", "ON", "OR", "ORDER", "OUTER",
+	"PRIMARY", "REFERENCES", "RENAME", "REPLACE", "RIGHT", "ROLLBACK", "SAVEPOINT",
+	"SELECT", "SET", "TABLE", "THEN", "TO", "TRANSACTION", "TRIGGER", "TRUE", "UNION",
+	"UNIQUE", "UPDATE", "USING", "VALUES", "VIEW", "WHEN", "WHERE", "WITH", "RECURSIVE",
+	"OVER", "PARTITION", "WINDOW", "ROWS", "RANGE", "PRECEDING", "FOLLOWING", "UNBOUNDED",
+	"CURRENT", "ROW", "FILTER", "NULLS", "FIRST", "LAST", "RELEASE", "TEMPORARY", "TEMP",
+	"CONFLICT", "DO", "NOTHING", "INTEGER", "INT", "BIGINT", "SMALLINT", "TEXT", "VARCHAR",
+	"CHAR", "BOOLEAN", "REAL", "NUMERIC", "DECIMAL", "DATE", "TIME", "TIMESTAMP", "BLOB",
+}
+
+var postgresKeywords = []string{
+	"ILIKE", "SIMILAR", "RETURNING", "LATERAL", "SCHEMA", "SEQUENCE", "SERIAL",
+	"BIGSERIAL", "JSONB", "JSON", "UUID", "BYTEA", "TIMESTAMPTZ", "INTERVAL", "ARRAY",
+	"FUNCTION", "PROCEDURE", "LANGUAGE", "RETURNS", "DECLARE", "LOOP", "RAISE",
+	"NOTICE", "PERFORM", "EXECUTE", "MATERIALIZED", "REFRESH", "CONCURRENTLY", "TRUNCATE",
+	"GRANT", "REVOKE", "OWNER", "EXTENSION", "ANALYZE", "VACUUM", "LISTEN", "NOTIFY",
+	"COPY", "ONLY", "FETCH", "NEXT", "DEFERRABLE", "INITIALLY", "DEFERRED", "ATOMIC",
+	"SECURITY", "DEFINER", "VOLATILE", "STABLE", "IMMUTABLE", "TYPE", "ENUM", "DOMAIN",
+}
+
+var sqliteKeywords = []string{
+	"PRAGMA", "AUTOINCREMENT", "GLOB", "MATCH", "REGEXP", "VACUUM", "ATTACH", "DETACH",
+	"WITHOUT", "ROWID", "STRICT", "REINDEX", "ANALYZE", "ABORT", "FAIL", "IGNORE",
+	"IMMEDIATE", "EXCLUSIVE", "DEFERRED", "INSTEAD", "OF", "EACH", "FOR", "RAISE",
+	"VIRTUAL", "INDEXED", "NOTNULL", "ISNULL", "RETURNING", "UPSERT", "GENERATED",
+	"ALWAYS", "STORED",
+}
+
+var mysqlKeywords = []string{
+	"SHOW", "DESCRIBE", "USE", "AUTO_INCREMENT", "ENGINE", "CHARSET", "UNSIGNED",
+	"ZEROFILL", "DUPLICATE", "STRAIGHT_JOIN", "REGEXP", "RLIKE", "DIV", "MOD", "XOR",
+	"PROCEDURE", "FUNCTION", "RETURNS", "DECLARE", "LOOP", "WHILE", "REPEAT", "UNTIL",
+	"LEAVE", "ITERATE", "CALL", "DELIMITER", "TRUNCATE", "GRANT", "REVOKE", "DATETIME",
+	"TINYINT", "MEDIUMINT", "LONGTEXT", "MEDIUMTEXT", "ENUM", "JSON", "FULLTEXT",
+	"SCHEMA", "TABLES", "COLUMNS", "DATABASES", "STATUS", "VARIABLES", "LOCK", "UNLOCK",
+}
+
+var dialectKeywords = map[Dialect]map[string]bool{
+	Postgres: keywordSet(commonKeywords, postgresKeywords),
+	SQLite:   keywordSet(commonKeywords, sqliteKeywords),
+	MySQL:    keywordSet(commonKeywords, mysqlKeywords),
+}
+
+func keywordSet(lists ...[]string) map[string]bool {
+	set := make(map[string]bool)
+	for _, list := range lists {
+		for _, word := range list {
+			set[word] = true
+		}
+	}
+	return set
+}
+
+// IsKeyword reports whether word is a keyword of the dialect; unknown
+// dialects fall back to PostgreSQL.
+func IsKeyword(dialect Dialect, word string) bool {
+	keywords, ok := dialectKeywords[dialect]
+	if !ok {
+		keywords = dialectKeywords[Postgres]
+	}
+	return keywords[strings.ToUpper(word)]
+}
+
+// Keywords lists the keywords of the dialect in alphabetical order.
+func Keywords(dialect Dialect) []string {
+	keywords, ok := dialectKeywords[dialect]
+	if !ok {
+		keywords = dialectKeywords[Postgres]
+	}
+	list := make([]string, 0, len(keywords))
+	for word := range keywords {
+		list = append(list, word)
+	}
+	sort.Strings(list)
+	return list
+}
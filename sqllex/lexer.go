@@ -0,0 +1,236 @@
+// Package sqllex splits SQL text into tokens for the editor, the script
+// splitter and the formatter. It does not validate SQL; it only needs to
+// know where strings, comments and words start and end in each dialect.
+package sqllex
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type Kind int
+
+const (
+	Space Kind = iota
+	Comment
+	Word
+	QuotedIdent
+	String
+	Number
+	Param
+	Punct
+)
+
+// Token is a slice of the source text; Start and End are byte offsets.
+type Token struct {
+	Kind  Kind
+	Text  string
+	Start int
+	End   int
+}
+
+// Tokenize splits text into tokens without validating it. Unterminated
+// strings and comments run to the end of the text so that a half-typed
+// statement still produces a usable token stream. The dialect decides the
+// lexical details: MySQL has # comments and backslash escapes in quotes,
+// PostgreSQL (also the default) has E'...' strings and dollar quoting.
+func Tokenize(text string, dialect Dialect) []Token {
+	var tokens []Token
+	pos := 0
+	for pos < len(text) {
+		kind, end := scanToken(text, pos, dialect)
+		tokens = append(tokens, Token{Kind: kind, Text: text[pos:end], Start: pos, End: end})
+		pos = end
+	}
+	return tokens
+}
+
+func scanToken(text string, pos int, dialect Dialect) (Kind, int) {
+	r, size := utf8.DecodeRuneInString(text[pos:])
+	rest := text[pos:]
+	mysql := dialect == MySQL
+	postgres := !mysql && dialect != SQLite
+
+	switch {
+	case unicode.IsSpace(r):
+		end := pos + size
+		for end < len(text) {
+			next, n := utf8.DecodeRuneInString(text[end:])
+			if !unicode.IsSpace(next) {
+				break
+			}
+			end += n
+		}
+		return Space, end
+	case strings.HasPrefix(rest, "--"), mysql && r == '#':
+		if idx := strings.IndexByte(rest, '\n'); idx >= 0 {
+			return Comment, pos + idx
+		}
+		return Comment, len(text)
+	case strings.HasPrefix(rest, "/*"):
+		return Comment, scanBlockComment(text, pos)
+	case r == '\'':
+		return String, scanQuoted(text, pos, '\'', mysql)
+	case postgres && (r == 'E' || r == 'e') && strings.HasPrefix(rest[1:], "'"):
+		return String, scanQuoted(text, pos+1, '\'', true)
+	case r == '"' && mysql:
+		// Without ANSI_QUOTES, MySQL double quotes delimit strings.
+		return String, scanQuoted(text, pos, '"', true)
+	case r == '"':
+		return QuotedIdent, scanQuoted(text, pos, '"', false)
+	case r == '`':
+		return QuotedIdent, scanQuoted(text, pos, '`', false)
+	case r == '$':
+		if tag, ok := dollarQuoteTag(rest); ok && postgres {
+			if idx := strings.Index(rest[len(tag):], tag); idx >= 0 {
+				return String, pos + len(tag) + idx + len(tag)
+			}
+			return String, len(text)
+		}
+		end := pos + 1
+		for end < len(text) && text[end] >= '0' && text[end] <= '9' {
+			end++
+		}
+		if end > pos+1 {
+			return Param, end
+		}
+		return Punct, end
+	case r >= '0' && r <= '9', r == '.' && len(rest) > 1 && rest[1] >= '0' && rest[1] <= '9':
+		return Number, scanNumber(text, pos)
+	case isIdentStart(r):
+		return Word, scanWord(text, pos)
+	}
+	return Punct, pos + size
+}
+
+func isIdentStart(r rune) bool {
+	return r == '_' || unicode.IsLetter(r)
+}
+
+func isIdentPart(r rune) bool {
+	return r == '_' || r == '$' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func scanWord(text string, pos int) int {
+	for pos < len(text) {
+		r, size := utf8.DecodeRuneInString(text[pos:])
+		if !isIdentPart(r) {
+			break
+		}
+		pos += size
+	}
+	return pos
+}
+
+func scanNumber(text string, pos int) int {
+	for pos < len(text) {
+		c := text[pos]
+		if (c >= '0' && c <= '9') || c == '.' {
+			pos++
+			continue
+		}
+		if (c == 'e' || c == 'E') && pos+1 < len(text) {
+			next := text[pos+1]
+			if next >= '0' && next <= '9' {
+				pos += 2
+				continue
+			}
+			if (next == '+' || next == '-') && pos+2 < len(text) && text[pos+2] >= '0' && text[pos+2] <= '9' {
+				pos += 3
+				continue
+			}
+		}
+		break
+	}
+	return pos
+}
+
+// scanQuoted returns the end of a quoted token starting at pos. A doubled
+// quote is an escaped quote; backslash escapes only apply to MySQL strings
+// and PostgreSQL E-prefixed strings.
+func scanQuoted(text string, pos int, quote byte, backslash bool) int {
+	idx := pos + 1
+	for idx < len(text) {
+		switch text[idx] {
+		case '\\':
+			if backslash {
+				idx += 2
+				continue
+			}
+		case quote:
+			if idx+1 < len(text) && text[idx+1] == quote {
+				idx += 2
+				continue
+			}
+			return idx + 1
+		}
+		idx++
+	}
+	return len(text)
+}
+
+// scanBlockComment handles nested comments the way PostgreSQL does.
+func scanBlockComment(text string, pos int) int {
+	depth := 0
+	idx := pos
+	for idx < len(text)-1 {
+		switch text[idx : idx+2] {
+		case "/*":
+			depth++
+			idx += 2
+			continue
+		case "*/":
+			depth--
+			idx += 2
+			if depth == 0 {
+				return idx
+			}
+			continue
+		}
+		idx++
+	}
+	return len(text)
+}
+
+// dollarQuoteTag recognises the opening $tag$ of a PostgreSQL dollar-quoted
+// string. Positional parameters such as $1 are not tags.
+func dollarQuoteTag(text string) (string, bool) {
+	end := 1
+	for end < len(text) && text[end] != '$' {
+		r, _ := utf8.DecodeRuneInString(text[end:])
+		if end == 1 && !isIdentStart(r) || !isIdentPart(r) {
+			return "", false
+		}
+		end += utf8.RuneLen(r)
+	}
+	if end >= len(text) {
+		return "", false
+	}
+	return text[:end+1], true
+}
+
+func (tok Token) IsTrivia() bool {
+	return tok.Kind == Space || tok.Kind == Comment
+}
+
+func (tok Token) IsKeyword(words ...string) bool {
+	if tok.Kind != Word {
+		return false
+	}
+	for _, word := range words {
+		if strings.EqualFold(tok.Text, word) {
+			return true
+		}
+	}
+	return false
+}
+
+// NextSignificant returns the index of the first token at or after idx that
+// is not whitespace or a comment, or len(tokens).
+func NextSignificant(tokens []Token, idx int) int {
+	for idx < len(tokens) && tokens[idx].IsTrivia() {
+		idx++
+	}
+	return idx
+}
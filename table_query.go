@@ -0,0 +1,199 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+type SortColumn struct {
+	Column     string
+	Descending bool
+}
+
+// ColumnFilter keeps rows whose column equals Value; a nil Value matches NULL.
+type ColumnFilter struct {
+	Column string
+	Value  interface{}
+}
+
+// TableView is the sort and filter applied to a table in the Data pane.
+// Where is an expression typed by the user and is sent as-is; every other
+// part is built from quoted identifiers and bound parameters.
+type TableView struct {
+	OrderBy []SortColumn
+	Filters []ColumnFilter
+	Where   string
+}
+
+// TableQueryOptions selects a page of table rows. When After holds the key
+// of the previous page's last row, loaders seek past it (keyset pagination)
+// instead of skipping Offset rows; that only applies to the default order.
+type TableQueryOptions struct {
+	TableView
+	Limit  int
+	Offset int
+	After  []interface{}
+}
+
+func (tv TableView) IsEmpty() bool {
+	return len(tv.OrderBy) == 0 && len(tv.Filters) == 0 && strings.TrimSpace(tv.Where) == ""
+}
+
+func (tv TableView) SortOf(column string) (SortColumn, bool) {
+	for _, sort := range tv.OrderBy {
+		if sort.Column == column {
+			return sort, true
+		}
+	}
+	return SortColumn{}, false
+}
+
+// CycleSort moves column through ascending, descending and unsorted, keeping
+// the position of the other sort columns.
+func (tv TableView) CycleSort(column string) TableView {
+	next := tv
+	next.OrderBy = nil
+	found := false
+	for _, sort := range tv.OrderBy {
+		if sort.Column != column {
+			next.OrderBy = append(next.OrderBy, sort)
+			continue
+		}
+		found = true
+		if !sort.Descending {
+			next.OrderBy = append(next.OrderBy, SortColumn{Column: column, Descending: true})
+		}
+	}
+	if !found {
+		next.OrderBy = append(next.OrderBy, SortColumn{Column: column})
+	}
+	return next
+}
+
+// WithFilter replaces any equality filter on the same column.
+func (tv TableView) WithFilter(column string, value interface{}) TableView {
+	next := tv
+	next.Filters = nil
+	for _, filter := range tv.Filters {
+		if filter.Column != column {
+			next.Filters = append(next.Filters, filter)
+		}
+	}
+	next.Filters = append(next.Filters, ColumnFilter{Column: column, Value: value})
+	return next
+}
+
+func (tv TableView) String() string {
+	var parts []string
+	if len(tv.OrderBy) > 0 {
+		sorts := make([]string, 0, len(tv.OrderBy))
+		for _, sort := range tv.OrderBy {
+			sorts = append(sorts, sortArrow(sort)+sort.Column)
+		}
+		parts = append(parts, "ordem: "+strings.Join(sorts, ", "))
+	}
+	var filters []string
+	for _, filter := range tv.Filters {
+		if filter.Value == nil {
+			filters = append(filters, filter.Column+" IS NULL")
+			continue
+		}
+		filters = append(filters, fmt.Sprintf("%s = %v", filter.Column, filter.Value))
+	}
+	if where := strings.TrimSpace(tv.Where); where != "" {
+		filters = append(filters, where)
+	}
+	if len(filters) > 0 {
+		parts = append(parts, "filtro: "+strings.Join(filters, " AND "))
+	}
+	return strings.Join(parts, " | ")
+}
+
+func sortArrow(sort SortColumn) string {
+	if sort.Descending {
+		return "▼"
+	}
+	return "▲"
+}
+
+// filterConditions renders the view's filters, numbering placeholders from
+// startArg.
+func (d sqlDialect) filterConditions(view TableView, startArg int) ([]string, []interface{}) {
+	var conditions []string
+	if where := strings.TrimSpace(view.Where); where != "" {
+		conditions = append(conditions, "("+where+")")
+	}
+
+	var columns []string
+	var args []interface{}
+	for _, filter := range view.Filters {
+		target := d.quoteIdent(filter.Column)
+		if filter.Value == nil {
+			conditions = append(conditions, target+" IS NULL")
+			continue
+		}
+		conditions = append(conditions, fmt.Sprintf("%s = %s", target, d.placeholder(startArg+len(args))))
+		columns = append(columns, filter.Column)
+		args = append(args, filter.Value)
+	}
+	if d.normalizeKeyArgs != nil && len(args) > 0 {
+		args = d.normalizeKeyArgs(RowKey{Columns: columns, Values: args}, args)
+	}
+	return conditions, args
+}
+
+func whereClause(conditions []string) string {
+	if len(conditions) == 0 {
+		return ""
+	}
+	return "WHERE " + strings.Join(conditions, " AND ")
+}
+
+// tableQueryClauses returns the WHERE and ORDER BY clauses, their arguments
+// and the OFFSET for a page of a table with the given key columns. The key,
+// or else the engine's row locator, breaks ties so pages never overlap. A
+// table with neither is ordered by all of its columns, which keeps OFFSET
+// pages stable unless two rows are identical.
+func (d sqlDialect) tableQueryClauses(keyCols, columns []string, opts TableQueryOptions) (string, string, []interface{}, int) {
+	conditions, args := d.filterConditions(opts.TableView, 1)
+
+	offset := opts.Offset
+	if len(opts.OrderBy) == 0 && len(keyCols) > 0 && len(opts.After) == len(keyCols) {
+		seek, seekArgs := d.keysetWhere(keyCols, opts.After, len(args)+1)
+		conditions = append(conditions, seek)
+		args = append(args, seekArgs...)
+		offset = 0
+	}
+
+	var order []string
+	sorted := make(map[string]bool)
+	for _, sort := range opts.OrderBy {
+		direction := "ASC"
+		if sort.Descending {
+			direction = "DESC"
+		}
+		order = append(order, d.quoteIdent(sort.Column)+" "+direction)
+		sorted[sort.Column] = true
+	}
+	for _, col := range keyCols {
+		if !sorted[col] {
+			order = append(order, d.quoteIdent(col))
+		}
+	}
+	if len(keyCols) == 0 && d.rowLocator != "" {
+		order = append(order, d.rowLocator)
+	}
+	if len(keyCols) == 0 && d.rowLocator == "" {
+		for _, col := range columns {
+			if !sorted[col] {
+				order = append(order, d.quoteIdent(col))
+			}
+		}
+	}
+
+	orderBy := ""
+	if len(order) > 0 {
+		orderBy = "ORDER BY " + strings.Join(order, ", ")
+	}
+	return whereClause(conditions), orderBy, args, offset
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestKeysetWhere(t *testing.T) {
+	tests := []struct {
+		name     string
+		dialect  sqlDialect
+		keyCols  []string
+		after    []interface{}
+		startArg int
+		want     string
+		wantArgs []interface{}
+	}{
+		{
+			name:     "postgres numbers placeholders from startArg",
+			dialect:  postgresDialect,
+			keyCols:  []string{"a", "b"},
+			after:    []interface{}{int64(1), []byte("x")},
+			startArg: 3,
+			want:     `("a", "b") > ($3, $4)`,
+			wantArgs: []interface{}{int64(1), "x"},
+		},
+		{
+			name:     "sqlite single column",
+			dialect:  sqliteDialect,
+			keyCols:  []string{"id"},
+			after:    []interface{}{int64(10)},
+			startArg: 1,
+			want:     `("id") > (?)`,
+			wantArgs: []interface{}{int64(10)},
+		},
+		{
+			name:     "mysql quotes with backticks",
+			dialect:  mysqlDialect,
+			keyCols:  []string{"id"},
+			after:    []interface{}{int64(10)},
+			startArg: 1,
+			want:     "(`id`) > (?)",
+			wantArgs: []interface{}{int64(10)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, args := tt.dialect.keysetWhere(tt.keyCols, tt.after, tt.startArg)
+			if got != tt.want {
+				t.Errorf("keysetWhere() = %q, want %q", got, tt.want)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("keysetWhere() args = %#v, want %#v", args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestTableQueryClauses(t *testing.T) {
+	tests := []struct {
+		name        string
+		dialect     sqlDialect
+		keyCols     []string
+		columns     []string
+		opts        TableQueryOptions
+		wantWhere   string
+		wantOrderBy string
+		wantArgs    []interface{}
+		wantOffset  int
+	}{
+		{
+			name:        "key orders the default view",
+			dialect:     postgresDialect,
+			keyCols:     []string{"id"},
+			opts:        TableQueryOptions{Limit: 100, Offset: 200},
+			wantOrderBy: `ORDER BY "id"`,
+			wantOffset:  200,
+		},
+		{
+			name:        "keyset seek replaces the offset",
+			dialect:     postgresDialect,
+			keyCols:     []string{"id"},
+			opts:        TableQueryOptions{TableView: TableView{Filters: []ColumnFilter{{Column: "kind", Value: "a"}}}, Limit: 100, Offset: 200, After: []interface{}{int64(42)}},
+			wantWhere:   `WHERE "kind" = $1 AND ("id") > ($2)`,
+			wantOrderBy: `ORDER BY "id"`,
+			wantArgs:    []interface{}{"a", int64(42)},
+			wantOffset:  0,
+		},
+		{
+			name:        "user sort keeps the offset and breaks ties by key",
+			dialect:     sqliteDialect,
+			keyCols:     []string{"a", "b"},
+			opts:        TableQueryOptions{TableView: TableView{OrderBy: []SortColumn{{Column: "b", Descending: true}, {Column: "name"}}}, Offset: 100, After: []interface{}{int64(1), int64(2)}},
+			wantOrderBy: `ORDER BY "b" DESC, "name" ASC, "a"`,
+			wantOffset:  100,
+		},
+		{
+			name:        "partial key values do not seek",
+			dialect:     sqliteDialect,
+			keyCols:     []string{"a", "b"},
+			opts:        TableQueryOptions{Offset: 100, After: []interface{}{int64(1)}},
+			wantOrderBy: `ORDER BY "a", "b"`,
+			wantOffset:  100,
+		},
+		{
+			name:        "no key falls back to the row locator",
+			dialect:     postgresDialect,
+			columns:     []string{"x", "y"},
+			opts:        TableQueryOptions{TableView: TableView{Where: "x > 1", Filters: []ColumnFilter{{Column: "y"}}}, Offset: 50},
+			wantWhere:   `WHERE (x > 1) AND "y" IS NULL`,
+			wantOrderBy: `ORDER BY ctid`,
+			wantOffset:  50,
+		},
+		{
+			name:        "mysql without key orders by every column",
+			dialect:     mysqlDialect,
+			columns:     []string{"x", "y", "z"},
+			opts:        TableQueryOptions{TableView: TableView{OrderBy: []SortColumn{{Column: "y"}}}, Offset: 50},
+			wantOrderBy: "ORDER BY `y` ASC, `x`, `z`",
+			wantOffset:  50,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			where, orderBy, args, offset := tt.dialect.tableQueryClauses(tt.keyCols, tt.columns, tt.opts)
+			if where != tt.wantWhere {
+				t.Errorf("where = %q, want %q", where, tt.wantWhere)
+			}
+			if orderBy != tt.wantOrderBy {
+				t.Errorf("orderBy = %q, want %q", orderBy, tt.wantOrderBy)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("args = %#v, want %#v", args, tt.wantArgs)
+			}
+			if offset != tt.wantOffset {
+				t.Errorf("offset = %d, want %d", offset, tt.wantOffset)
+			}
+		})
+	}
+}
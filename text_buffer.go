@@ -0,0 +1,579 @@
+package main
+
+import (
+	"strings"
+	"unicode"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const textBufferUndoLimit = 200
+
+var (
+	textCursorStyle    = lipgloss.NewStyle().Reverse(true)
+	textSelectionColor = lipgloss.Color("#264F78")
+)
+
+// textEditKind groups consecutive edits so that undo removes a typed word
+// at once instead of one character at a time.
+type textEditKind int
+
+const (
+	textEditNone textEditKind = iota
+	textEditInsert
+	textEditDelete
+	textEditOther
+)
+
+type textBufferState struct {
+	runes  []rune
+	cursor int
+}
+
+// TextBuffer is the editable text behind QueryEditor, TextInput and the
+// connection form. Positions count runes, not bytes, so accented
+// identifiers keep the cursor in place. The selection runs from anchor to
+// cursor; anchor is -1 when nothing is selected.
+type TextBuffer struct {
+	runes     []rune
+	cursor    int
+	anchor    int
+	goal      int
+	multiline bool
+	undo      []textBufferState
+	redo      []textBufferState
+	lastEdit  textEditKind
+}
+
+func NewTextBuffer(multiline bool) *TextBuffer {
+	return &TextBuffer{anchor: -1, goal: -1, multiline: multiline}
+}
+
+func (tb *TextBuffer) Text() string {
+	return string(tb.runes)
+}
+
+func (tb *TextBuffer) Len() int {
+	return len(tb.runes)
+}
+
+func (tb *TextBuffer) Cursor() int {
+	return tb.cursor
+}
+
+// ByteOffset converts a rune position to a byte offset into Text(), the
+// unit used by the SQL lexer.
+func (tb *TextBuffer) ByteOffset(pos int) int {
+	return len(string(tb.runes[:tb.clamp(pos)]))
+}
+
+// RuneOffset converts a byte offset into Text() back to a rune position.
+func (tb *TextBuffer) RuneOffset(offset int) int {
+	pos := 0
+	for idx := range tb.Text() {
+		if idx >= offset {
+			return pos
+		}
+		pos++
+	}
+	return pos
+}
+
+func (tb *TextBuffer) CursorByte() int {
+	return tb.ByteOffset(tb.cursor)
+}
+
+// Load replaces the text without recording history, e.g. when a form field
+// gains focus, and puts the cursor at the end.
+func (tb *TextBuffer) Load(text string) {
+	tb.runes = tb.sanitize(text)
+	tb.cursor = len(tb.runes)
+	tb.anchor = -1
+	tb.goal = -1
+	tb.undo = nil
+	tb.redo = nil
+	tb.lastEdit = textEditNone
+}
+
+// SetText replaces the whole text as one undoable edit.
+func (tb *TextBuffer) SetText(text string) {
+	runes := tb.sanitize(text)
+	if string(runes) == tb.Text() {
+		return
+	}
+	tb.record(textEditOther)
+	tb.runes = runes
+	tb.cursor = tb.clamp(tb.cursor)
+	tb.anchor = -1
+	tb.goal = -1
+}
+
+func (tb *TextBuffer) SetCursor(pos int) {
+	tb.moveTo(pos, false)
+}
+
+// Selection returns the selected range in runes, start <= end.
+func (tb *TextBuffer) Selection() (int, int, bool) {
+	if tb.anchor < 0 || tb.anchor == tb.cursor {
+		return tb.cursor, tb.cursor, false
+	}
+	if tb.anchor < tb.cursor {
+		return tb.anchor, tb.cursor, true
+	}
+	return tb.cursor, tb.anchor, true
+}
+
+func (tb *TextBuffer) SelectedText() string {
+	start, end, ok := tb.Selection()
+	if !ok {
+		return ""
+	}
+	return string(tb.runes[start:end])
+}
+
+func (tb *TextBuffer) SelectAll() {
+	tb.anchor = 0
+	tb.cursor = len(tb.runes)
+	tb.goal = -1
+	tb.lastEdit = textEditNone
+	if tb.cursor == 0 {
+		tb.anchor = -1
+	}
+}
+
+// sanitize normalises line endings; single-line buffers turn them into
+// spaces so pasted text stays on one line.
+func (tb *TextBuffer) sanitize(text string) []rune {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	text = strings.ReplaceAll(text, "\r", "\n")
+	if !tb.multiline {
+		text = strings.ReplaceAll(text, "\n", " ")
+	}
+	return []rune(text)
+}
+
+func (tb *TextBuffer) clamp(pos int) int {
+	if pos < 0 {
+		return 0
+	}
+	if pos > len(tb.runes) {
+		return len(tb.runes)
+	}
+	return pos
+}
+
+// record saves the current state for undo. Edits of the same kind in a row
+// (typing, backspacing) share one entry.
+func (tb *TextBuffer) record(kind textEditKind) {
+	if kind != textEditOther && kind == tb.lastEdit {
+		return
+	}
+	tb.undo = append(tb.undo, textBufferState{runes: append([]rune(nil), tb.runes...), cursor: tb.cursor})
+	if len(tb.undo) > textBufferUndoLimit {
+		tb.undo = tb.undo[len(tb.undo)-textBufferUndoLimit:]
+	}
+	tb.redo = nil
+	tb.lastEdit = kind
+}
+
+func (tb *TextBuffer) replace(start, end int, text []rune, kind textEditKind) {
+	tb.record(kind)
+	runes := make([]rune, 0, len(tb.runes)-(end-start)+len(text))
+	runes = append(runes, tb.runes[:start]...)
+	runes = append(runes, text...)
+	runes = append(runes, tb.runes[end:]...)
+	tb.runes = runes
+	tb.cursor = start + len(text)
+	tb.anchor = -1
+	tb.goal = -1
+}
+
+// ReplaceRange swaps the runes in [start, end) for text, as completion does
+// with the word being typed.
+func (tb *TextBuffer) ReplaceRange(start, end int, text string) {
+	start, end = tb.clamp(start), tb.clamp(end)
+	if start > end {
+		start, end = end, start
+	}
+	tb.replace(start, end, tb.sanitize(text), textEditOther)
+}
+
+// Insert types text at the cursor, replacing the selection if there is one.
+func (tb *TextBuffer) Insert(text string) {
+	runes := tb.sanitize(text)
+	if start, end, ok := tb.Selection(); ok {
+		tb.replace(start, end, runes, textEditOther)
+		return
+	}
+	if len(runes) == 0 {
+		return
+	}
+	tb.replace(tb.cursor, tb.cursor, runes, textEditInsert)
+	if strings.TrimSpace(text) == "" {
+		// The next word starts a new undo step.
+		tb.lastEdit = textEditNone
+	}
+}
+
+// InsertNewline breaks the line at the cursor; single-line buffers ignore it.
+func (tb *TextBuffer) InsertNewline() bool {
+	if !tb.multiline {
+		return false
+	}
+	start, end, _ := tb.Selection()
+	tb.replace(start, end, []rune{'\n'}, textEditOther)
+	return true
+}
+
+func (tb *TextBuffer) deleteSelection() bool {
+	start, end, ok := tb.Selection()
+	if !ok {
+		return false
+	}
+	tb.replace(start, end, nil, textEditOther)
+	return true
+}
+
+func (tb *TextBuffer) DeleteBackward() {
+	if tb.deleteSelection() || tb.cursor == 0 {
+		return
+	}
+	tb.replace(tb.cursor-1, tb.cursor, nil, textEditDelete)
+}
+
+func (tb *TextBuffer) DeleteForward() {
+	if tb.deleteSelection() || tb.cursor >= len(tb.runes) {
+		return
+	}
+	tb.replace(tb.cursor, tb.cursor+1, nil, textEditDelete)
+}
+
+func (tb *TextBuffer) DeleteWordBackward() {
+	if tb.deleteSelection() || tb.cursor == 0 {
+		return
+	}
+	tb.replace(tb.wordLeft(tb.cursor), tb.cursor, nil, textEditOther)
+}
+
+// DeleteLine removes the cursor's line together with its line break.
+func (tb *TextBuffer) DeleteLine() {
+	start, end := tb.lineStart(tb.cursor), tb.lineEnd(tb.cursor)
+	switch {
+	case end < len(tb.runes):
+		end++
+	case start > 0:
+		start--
+	}
+	if start == end {
+		return
+	}
+	col := tb.cursor - tb.lineStart(tb.cursor)
+	tb.replace(start, end, nil, textEditOther)
+	line := tb.lineStart(tb.cursor)
+	tb.cursor = tb.clamp(line + min(col, tb.lineEnd(line)-line))
+}
+
+// DuplicateLine copies the cursor's line below itself and moves onto the copy.
+func (tb *TextBuffer) DuplicateLine() bool {
+	if !tb.multiline {
+		return false
+	}
+	start, end := tb.lineStart(tb.cursor), tb.lineEnd(tb.cursor)
+	col := tb.cursor - start
+	line := append([]rune{'\n'}, tb.runes[start:end]...)
+	tb.replace(end, end, line, textEditOther)
+	tb.cursor = end + 1 + col
+	return true
+}
+
+func (tb *TextBuffer) Undo() bool {
+	if len(tb.undo) == 0 {
+		return false
+	}
+	tb.redo = append(tb.redo, textBufferState{runes: tb.runes, cursor: tb.cursor})
+	state := tb.undo[len(tb.undo)-1]
+	tb.undo = tb.undo[:len(tb.undo)-1]
+	tb.restore(state)
+	return true
+}
+
+func (tb *TextBuffer) Redo() bool {
+	if len(tb.redo) == 0 {
+		return false
+	}
+	tb.undo = append(tb.undo, textBufferState{runes: tb.runes, cursor: tb.cursor})
+	state := tb.redo[len(tb.redo)-1]
+	tb.redo = tb.redo[:len(tb.redo)-1]
+	tb.restore(state)
+	return true
+}
+
+func (tb *TextBuffer) restore(state textBufferState) {
+	tb.runes = state.runes
+	tb.cursor = tb.clamp(state.cursor)
+	tb.anchor = -1
+	tb.goal = -1
+	tb.lastEdit = textEditNone
+}
+
+// moveTo places the cursor at pos; extend grows the selection from where
+// the cursor was, otherwise the selection is dropped.
+func (tb *TextBuffer) moveTo(pos int, extend bool) {
+	if extend {
+		if tb.anchor < 0 {
+			tb.anchor = tb.cursor
+		}
+	} else {
+		tb.anchor = -1
+	}
+	tb.cursor = tb.clamp(pos)
+	tb.goal = -1
+	tb.lastEdit = textEditNone
+}
+
+func (tb *TextBuffer) MoveLeft(extend bool) {
+	if start, _, ok := tb.Selection(); ok && !extend {
+		tb.moveTo(start, false)
+		return
+	}
+	tb.moveTo(tb.cursor-1, extend)
+}
+
+func (tb *TextBuffer) MoveRight(extend bool) {
+	if _, end, ok := tb.Selection(); ok && !extend {
+		tb.moveTo(end, false)
+		return
+	}
+	tb.moveTo(tb.cursor+1, extend)
+}
+
+func (tb *TextBuffer) MoveWordLeft(extend bool) {
+	tb.moveTo(tb.wordLeft(tb.cursor), extend)
+}
+
+func (tb *TextBuffer) MoveWordRight(extend bool) {
+	tb.moveTo(tb.wordRight(tb.cursor), extend)
+}
+
+func (tb *TextBuffer) MoveLineStart(extend bool) {
+	tb.moveTo(tb.lineStart(tb.cursor), extend)
+}
+
+func (tb *TextBuffer) MoveLineEnd(extend bool) {
+	tb.moveTo(tb.lineEnd(tb.cursor), extend)
+}
+
+// MoveUp and MoveDown keep the column the cursor had before the first
+// vertical move, so passing through a short line does not lose it.
+func (tb *TextBuffer) MoveUp(extend bool) {
+	start := tb.lineStart(tb.cursor)
+	if start == 0 {
+		return
+	}
+	tb.moveVertical(tb.lineStart(start-1), extend)
+}
+
+func (tb *TextBuffer) MoveDown(extend bool) {
+	end := tb.lineEnd(tb.cursor)
+	if end >= len(tb.runes) {
+		return
+	}
+	tb.moveVertical(end+1, extend)
+}
+
+func (tb *TextBuffer) moveVertical(lineStart int, extend bool) {
+	goal := tb.goal
+	if goal < 0 {
+		goal = tb.cursor - tb.lineStart(tb.cursor)
+	}
+	tb.moveTo(lineStart+min(goal, tb.lineEnd(lineStart)-lineStart), extend)
+	tb.goal = goal
+}
+
+func (tb *TextBuffer) lineStart(pos int) int {
+	for pos > 0 && tb.runes[pos-1] != '\n' {
+		pos--
+	}
+	return pos
+}
+
+func (tb *TextBuffer) lineEnd(pos int) int {
+	for pos < len(tb.runes) && tb.runes[pos] != '\n' {
+		pos++
+	}
+	return pos
+}
+
+func isWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func (tb *TextBuffer) wordLeft(pos int) int {
+	for pos > 0 && !isWordRune(tb.runes[pos-1]) {
+		pos--
+	}
+	for pos > 0 && isWordRune(tb.runes[pos-1]) {
+		pos--
+	}
+	return pos
+}
+
+func (tb *TextBuffer) wordRight(pos int) int {
+	for pos < len(tb.runes) && !isWordRune(tb.runes[pos]) {
+		pos++
+	}
+	for pos < len(tb.runes) && isWordRune(tb.runes[pos]) {
+		pos++
+	}
+	return pos
+}
+
+// CursorLine is the zero-based line of the cursor.
+func (tb *TextBuffer) CursorLine() int {
+	line := 0
+	for _, r := range tb.runes[:tb.cursor] {
+		if r == '\n' {
+			line++
+		}
+	}
+	return line
+}
+
+func (tb *TextBuffer) OnFirstLine() bool {
+	return tb.lineStart(tb.cursor) == 0
+}
+
+func (tb *TextBuffer) OnLastLine() bool {
+	return tb.lineEnd(tb.cursor) == len(tb.runes)
+}
+
+// HandleKey applies the editing keys shared by every text field. It reports
+// whether the key was used; Enter, Tab and Esc are left to the caller.
+func (tb *TextBuffer) HandleKey(msg tea.KeyMsg) bool {
+	switch msg.Type {
+	case tea.KeyLeft:
+		if msg.Alt {
+			tb.MoveWordLeft(false)
+		} else {
+			tb.MoveLeft(false)
+		}
+	case tea.KeyRight:
+		if msg.Alt {
+			tb.MoveWordRight(false)
+		} else {
+			tb.MoveRight(false)
+		}
+	case tea.KeyShiftLeft:
+		tb.MoveLeft(true)
+	case tea.KeyShiftRight:
+		tb.MoveRight(true)
+	case tea.KeyCtrlLeft:
+		tb.MoveWordLeft(false)
+	case tea.KeyCtrlRight:
+		tb.MoveWordRight(false)
+	case tea.KeyCtrlShiftLeft:
+		tb.MoveWordLeft(true)
+	case tea.KeyCtrlShiftRight:
+		tb.MoveWordRight(true)
+	case tea.KeyHome:
+		tb.MoveLineStart(false)
+	case tea.KeyEnd:
+		tb.MoveLineEnd(false)
+	case tea.KeyShiftHome:
+		tb.MoveLineStart(true)
+	case tea.KeyShiftEnd:
+		tb.MoveLineEnd(true)
+	case tea.KeyCtrlHome:
+		tb.moveTo(0, false)
+	case tea.KeyCtrlEnd:
+		tb.moveTo(len(tb.runes), false)
+	case tea.KeyCtrlShiftHome:
+		tb.moveTo(0, true)
+	case tea.KeyCtrlShiftEnd:
+		tb.moveTo(len(tb.runes), true)
+	case tea.KeyUp, tea.KeyDown, tea.KeyShiftUp, tea.KeyShiftDown:
+		if !tb.multiline {
+			return false
+		}
+		extend := msg.Type == tea.KeyShiftUp || msg.Type == tea.KeyShiftDown
+		if msg.Type == tea.KeyUp || msg.Type == tea.KeyShiftUp {
+			tb.MoveUp(extend)
+		} else {
+			tb.MoveDown(extend)
+		}
+	case tea.KeyCtrlA:
+		tb.SelectAll()
+	case tea.KeyBackspace:
+		if msg.Alt {
+			tb.DeleteWordBackward()
+		} else {
+			tb.DeleteBackward()
+		}
+	case tea.KeyCtrlW:
+		tb.DeleteWordBackward()
+	case tea.KeyDelete:
+		tb.DeleteForward()
+	case tea.KeyCtrlK:
+		tb.DeleteLine()
+	case tea.KeyCtrlD:
+		return tb.DuplicateLine()
+	case tea.KeyCtrlZ:
+		tb.Undo()
+	case tea.KeyCtrlY:
+		tb.Redo()
+	case tea.KeySpace:
+		tb.Insert(" ")
+	case tea.KeyRunes:
+		if msg.Alt {
+			return false
+		}
+		tb.Insert(string(msg.Runes))
+	default:
+		return false
+	}
+	return true
+}
+
+// View renders a single-line buffer scrolled to keep the cursor within
+// width cells. mask, when not zero, replaces every character (passwords).
+func (tb *TextBuffer) View(mask rune, width int) string {
+	runes := tb.runes
+	if mask != 0 {
+		runes = []rune(strings.Repeat(string(mask), len(runes)))
+	}
+	start, end := 0, len(runes)
+	if width > 0 && tb.cursor >= width {
+		start = tb.cursor - width + 1
+	}
+	if width > 0 && end > start+width {
+		end = start + width
+	}
+
+	selStart, selEnd, _ := tb.Selection()
+	selection := lipgloss.NewStyle().Background(textSelectionColor)
+	var out strings.Builder
+	for pos := start; pos < end; {
+		if pos == tb.cursor {
+			out.WriteString(textCursorStyle.Render(string(runes[pos])))
+			pos++
+			continue
+		}
+		stop := end
+		for _, bound := range []int{tb.cursor, selStart, selEnd} {
+			if bound > pos && bound < stop {
+				stop = bound
+			}
+		}
+		if pos >= selStart && pos < selEnd {
+			out.WriteString(selection.Render(string(runes[pos:stop])))
+		} else {
+			out.WriteString(string(runes[pos:stop]))
+		}
+		pos = stop
+	}
+	if tb.cursor >= len(runes) {
+		out.WriteString(textCursorStyle.Render(" "))
+	}
+	return out.String()
+}
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+// typeText inserts text one rune at a time, the way key presses arrive.
+func typeText(tb *TextBuffer, text string) {
+	for _, r := range text {
+		tb.Insert(string(r))
+	}
+}
+
+func TestTextBufferRunes(t *testing.T) {
+	tests := []struct {
+		name       string
+		multiline  bool
+		load       string
+		edit       func(tb *TextBuffer)
+		want       string
+		wantCursor int
+	}{
+		{
+			name:       "backspace removes a whole accented rune",
+			load:       "ação",
+			edit:       func(tb *TextBuffer) { tb.DeleteBackward() },
+			want:       "açã",
+			wantCursor: 3,
+		},
+		{
+			name: "insert between multibyte runes",
+			load: "açã",
+			edit: func(tb *TextBuffer) {
+				tb.MoveLeft(false)
+				tb.Insert("ü")
+			},
+			want:       "açüã",
+			wantCursor: 3,
+		},
+		{
+			name: "delete forward at a multibyte rune",
+			load: "日本語",
+			edit: func(tb *TextBuffer) {
+				tb.SetCursor(1)
+				tb.DeleteForward()
+			},
+			want:       "日語",
+			wantCursor: 1,
+		},
+		{
+			name:       "word motions treat letters with accents as word runes",
+			load:       "select função_x from",
+			edit:       func(tb *TextBuffer) { tb.MoveWordLeft(false); tb.DeleteWordBackward() },
+			want:       "select from",
+			wantCursor: 7,
+		},
+		{
+			name: "selection replaced by typing",
+			load: "olá mundo",
+			edit: func(tb *TextBuffer) {
+				tb.SetCursor(0)
+				for i := 0; i < 3; i++ {
+					tb.MoveRight(true)
+				}
+				tb.Insert("oi")
+			},
+			want:       "oi mundo",
+			wantCursor: 2,
+		},
+		{
+			name:       "single-line buffers flatten pasted newlines",
+			load:       "",
+			edit:       func(tb *TextBuffer) { tb.Insert("a\r\nb\nc") },
+			want:       "a b c",
+			wantCursor: 5,
+		},
+		{
+			name:      "vertical moves keep the goal column",
+			multiline: true,
+			load:      "ççççç\nç\nççççç",
+			edit: func(tb *TextBuffer) {
+				tb.SetCursor(4)
+				tb.MoveDown(false)
+				tb.MoveDown(false)
+				tb.Insert("|")
+			},
+			want:       "ççççç\nç\nçççç|ç",
+			wantCursor: 13,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tb := NewTextBuffer(tt.multiline)
+			tb.Load(tt.load)
+			tt.edit(tb)
+			if got := tb.Text(); got != tt.want {
+				t.Errorf("Text() = %q, want %q", got, tt.want)
+			}
+			if got := tb.Cursor(); got != tt.wantCursor {
+				t.Errorf("Cursor() = %d, want %d", got, tt.wantCursor)
+			}
+		})
+	}
+}
+
+func TestTextBufferOffsets(t *testing.T) {
+	tb := NewTextBuffer(true)
+	tb.Load("só ação")
+
+	tests := []struct {
+		pos    int
+		offset int
+	}{
+		{pos: 0, offset: 0},
+		{pos: 2, offset: 3},
+		{pos: 5, offset: 7},
+		{pos: 7, offset: 10},
+	}
+	for _, tt := range tests {
+		if got := tb.ByteOffset(tt.pos); got != tt.offset {
+			t.Errorf("ByteOffset(%d) = %d, want %d", tt.pos, got, tt.offset)
+		}
+		if got := tb.RuneOffset(tt.offset); got != tt.pos {
+			t.Errorf("RuneOffset(%d) = %d, want %d", tt.offset, got, tt.pos)
+		}
+	}
+}
+
+func TestTextBufferUndoGrouping(t *testing.T) {
+	tests := []struct {
+		name string
+		edit func(tb *TextBuffer)
+		// want lists the text after each successive Undo.
+		want []string
+	}{
+		{
+			name: "typed words undo one at a time",
+			edit: func(tb *TextBuffer) { typeText(tb, "select from") },
+			want: []string{"select ", ""},
+		},
+		{
+			name: "backspaces group apart from typing",
+			edit: func(tb *TextBuffer) {
+				typeText(tb, "ação")
+				tb.DeleteBackward()
+				tb.DeleteBackward()
+			},
+			want: []string{"ação", ""},
+		},
+		{
+			name: "cursor moves split a run of typing",
+			edit: func(tb *TextBuffer) {
+				typeText(tb, "ab")
+				tb.MoveLeft(false)
+				typeText(tb, "xy")
+			},
+			want: []string{"ab", ""},
+		},
+		{
+			name: "newline is its own step",
+			edit: func(tb *TextBuffer) {
+				typeText(tb, "a")
+				tb.InsertNewline()
+				typeText(tb, "b")
+			},
+			want: []string{"a\n", "a", ""},
+		},
+		{
+			name: "replacing the whole text is one step",
+			edit: func(tb *TextBuffer) {
+				typeText(tb, "old")
+				tb.SetText("new")
+			},
+			want: []string{"old", ""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tb := NewTextBuffer(true)
+			tt.edit(tb)
+			final := tb.Text()
+
+			var got []string
+			for tb.Undo() {
+				got = append(got, tb.Text())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("undo steps = %q, want %q", got, tt.want)
+			}
+
+			for tb.Redo() {
+			}
+			if tb.Text() != final {
+				t.Errorf("after redo Text() = %q, want %q", tb.Text(), final)
+			}
+		})
+	}
+}
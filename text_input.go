@@ -8,16 +8,14 @@ import (
 )
 
 type TextInput struct {
-	value       string
-	cursor      int
+	buffer      *TextBuffer
 	width       int
 	placeholder string
 }
 
 func NewTextInput() *TextInput {
 	return &TextInput{
-		value:       "",
-		cursor:      0,
+		buffer:      NewTextBuffer(false),
 		width:       60,
 		placeholder: "",
 	}
@@ -34,72 +32,28 @@ func (ti *TextInput) SetWidth(width int) {
 	ti.width = width
 }
 
+// SetValue loads a new value with the cursor at its end and a fresh undo
+// history.
 func (ti *TextInput) SetValue(value string) {
-	ti.value = value
-	if ti.cursor > len(value) {
-		ti.cursor = len(value)
-	}
+	ti.buffer.Load(value)
 }
 
 func (ti *TextInput) Value() string {
-	return ti.value
+	return ti.buffer.Text()
 }
 
 func (ti *TextInput) Reset() {
-	ti.value = ""
-	ti.cursor = 0
+	ti.buffer.Load("")
 }
 
 func (ti *TextInput) HandleKey(msg tea.KeyMsg) bool {
-	switch msg.Type {
-	case tea.KeyLeft:
-		if ti.cursor > 0 {
-			ti.cursor--
-		}
-		return true
-	case tea.KeyRight:
-		if ti.cursor < len(ti.value) {
-			ti.cursor++
-		}
-		return true
-	case tea.KeyHome:
-		ti.cursor = 0
-		return true
-	case tea.KeyEnd:
-		ti.cursor = len(ti.value)
-		return true
-	case tea.KeyBackspace:
-		if ti.cursor > 0 {
-			ti.value = ti.value[:ti.cursor-1] + ti.value[ti.cursor:]
-			ti.cursor--
-		}
-		return true
-	case tea.KeyDelete:
-		if ti.cursor < len(ti.value) {
-			ti.value = ti.value[:ti.cursor] + ti.value[ti.cursor+1:]
-		}
-		return true
-	}
-
-	if len(msg.Runes) > 0 {
-		ti.value = ti.value[:ti.cursor] + string(msg.Runes) + ti.value[ti.cursor:]
-		ti.cursor += len(msg.Runes)
-		return true
-	}
-
-	return false
+	return ti.buffer.HandleKey(msg)
 }
 
 func (ti *TextInput) View(prompt string) string {
-	value := ti.value
-	if value == "" && ti.placeholder != "" {
-		value = ti.placeholder
-	}
-
-	display := value
-	if len(display) > ti.width {
-		start := len(display) - ti.width
-		display = display[start:]
+	display := ti.buffer.View(0, ti.width)
+	if ti.buffer.Len() == 0 && ti.placeholder != "" {
+		display = ti.placeholder
 	}
 
 	style := lipgloss.NewStyle().
@@ -168,6 +168,24 @@ func (tn *TreeNode) Collapse() {
 	tn.Expanded = false
 }
 
+// detached is a childless copy of the node for a background task to load
+// children into, so the live tree is only ever written by the update loop.
+func (tn *TreeNode) detached() *TreeNode {
+	return &TreeNode{ID: tn.ID, Name: tn.Name, Type: tn.Type, Path: tn.Path, Level: tn.Level, Metadata: tn.Metadata}
+}
+
+// attachChildren adopts children loaded into a detached copy, unless the
+// node got its children from another load meanwhile.
+func (tn *TreeNode) attachChildren(children []*TreeNode) {
+	if len(tn.Children) > 0 {
+		return
+	}
+	for _, child := range children {
+		child.Parent = tn
+	}
+	tn.Children = children
+}
+
 func (tm *TreeModel) GetAllVisibleNodes() []*TreeNode {
 	return tm.getVisibleNodes(tm.root, 0)
 }
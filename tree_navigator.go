@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
@@ -117,10 +118,12 @@ func (tn *TreeNavigator) navigateRight() (tea.Model, tea.Cmd) {
 	if selected.HasChildren() && !selected.Expanded {
 		selected.Expand()
 	} else if !selected.HasChildren() && tn.dbLoader != nil {
-		// Try to load children on demand
-		if err := tn.dbLoader.LoadChildren(selected); err == nil && selected.HasChildren() {
-			selected.Expand()
-		}
+		// Load children on demand in the background
+		loader, probe := tn.dbLoader, selected.detached()
+		return tn.model, runTask("Carregando "+selected.Name, func(ctx context.Context) tea.Msg {
+			err := loader.LoadChildren(ctx, probe)
+			return TreeChildrenLoadedMsg{node: selected, children: probe.Children, err: err}
+		})
 	}
 
 	return tn.model, nil